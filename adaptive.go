@@ -0,0 +1,76 @@
+package gobreaker
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Mode is a type that represents how CircuitBreaker decides to reject requests.
+type Mode int
+
+// These constants are modes of CircuitBreaker.
+//
+// ModeStandard is the state machine of closed, open and half-open states.
+//
+// ModeAdaptive is the client-side adaptive throttling described in the Google SRE book.
+// The CircuitBreaker stays closed and rejects each request with the probability
+// max(0, (requests - K * accepts) / (requests + 1)),
+// where requests and accepts are the numbers of the attempted and the successful requests
+// during the last AdaptiveWindow. It avoids the thundering recovery of hard open/close transitions.
+//
+// ModeHighThroughput is ModeStandard for extreme contention. In the closed state, the failures are also
+// counted without the lock, like the successes with CounterShards, and the CircuitBreaker evaluates
+// ReadyToTrip with the batched outcomes at the next request after a failure,
+// trading bounded staleness in tripping for much less lock contention.
+const (
+	ModeStandard Mode = iota
+	ModeAdaptive
+	ModeHighThroughput
+)
+
+// String implements stringer interface.
+func (m Mode) String() string {
+	switch m {
+	case ModeStandard:
+		return "standard"
+	case ModeAdaptive:
+		return "adaptive"
+	case ModeHighThroughput:
+		return "high-throughput"
+	default:
+		return fmt.Sprintf("unknown mode: %d", m)
+	}
+}
+
+const defaultAdaptiveK = 2.0
+const defaultAdaptiveWindow = time.Duration(2) * time.Minute
+
+// throttle records an attempt in the adaptive window and reports whether the request should be rejected.
+func (cb *CircuitBreaker) throttle(now time.Time, opts callOptions) bool {
+	requests, accepts, _ := cb.adaptive.totals(now)
+	cb.adaptive.onRequest(now)
+
+	p := (float64(requests) - cb.adaptiveK*float64(accepts)) / float64(requests+1)
+	if p <= 0 {
+		return false
+	}
+	return rand.Float64() < cb.shedProbability(p, opts)
+}
+
+// shedProbability returns the probability to reject a request with opts when the overall probability is p.
+// The requests of the class shed first are rejected with twice the probability,
+// and the other class is rejected only when that isn't enough.
+func (cb *CircuitBreaker) shedProbability(p float64, opts callOptions) float64 {
+	shedFirst := opts.idempotent != cb.shedNonIdempotentFirst
+	if shedFirst {
+		if p >= 0.5 {
+			return 1
+		}
+		return 2 * p
+	}
+	if p <= 0.5 {
+		return 0
+	}
+	return 2*p - 1
+}
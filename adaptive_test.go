@@ -0,0 +1,56 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeString(t *testing.T) {
+	assert.Equal(t, "standard", ModeStandard.String())
+	assert.Equal(t, "adaptive", ModeAdaptive.String())
+	assert.Equal(t, "unknown mode: 100", Mode(100).String())
+}
+
+func TestAdaptiveThrottling(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Mode: ModeAdaptive})
+	assert.Equal(t, defaultAdaptiveK, cb.adaptiveK)
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+
+	throttled := 0
+	for i := 0; i < 1000; i++ {
+		err := fail(cb)
+		if errors.Is(err, ErrThrottled) {
+			throttled++
+			assert.True(t, IsRejection(err))
+			assert.Equal(t, ErrThrottled, RejectionReason(err))
+		}
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.True(t, throttled > 500)
+	assert.True(t, throttled < 1000)
+}
+
+func TestAdaptiveShedsIdempotentFirst(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Mode: ModeAdaptive})
+	assert.Equal(t, float64(1), cb.shedProbability(0.5, callOptions{idempotent: true}))
+	assert.Equal(t, float64(0), cb.shedProbability(0.5, callOptions{}))
+	assert.Equal(t, 0.5, cb.shedProbability(0.75, callOptions{}))
+
+	cb.shedNonIdempotentFirst = true
+	assert.Equal(t, 0.5, cb.shedProbability(0.25, callOptions{}))
+	assert.Equal(t, float64(0), cb.shedProbability(0.25, callOptions{idempotent: true}))
+}
+
+func TestAdaptiveModeDisablesShards(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Mode: ModeAdaptive, CounterShards: 4})
+	assert.Nil(t, cb.shards)
+
+	cb.UpdateSettings(Settings{CounterShards: 4})
+	assert.NotNil(t, cb.shards)
+	assert.Nil(t, cb.adaptive)
+}
@@ -0,0 +1,52 @@
+package gobreaker
+
+import "time"
+
+// AdmissionRequest describes a request for AdmissionPolicy to allow or reject.
+// State and Counts are those of the CircuitBreaker when the request arrives,
+// and Priority, Idempotent and Metadata are given to the request by the CallOptions.
+type AdmissionRequest struct {
+	Name       string
+	State      State
+	Counts     Counts
+	Time       time.Time
+	Priority   Priority
+	Idempotent bool
+	Metadata   Metadata
+}
+
+// AdmissionPolicy decides whether CircuitBreaker allows the requests, set by Settings.AdmissionPolicy,
+// e.g. to throttle them probabilistically, by a token bucket or by priority without forking the state machine.
+//
+// Admit is called only for the requests the CircuitBreaker would allow without AdmissionPolicy,
+// i.e. the state machine and the built-in throttles such as AdaptiveK and RecoveryRamp always apply first.
+// Admit returns nil to allow the request, or the error to reject it with, e.g. ErrThrottled,
+// which is returned in a RejectionError like the built-in rejections.
+// Admit is called with the lock of the CircuitBreaker held, so it must not call the methods of the CircuitBreaker.
+type AdmissionPolicy interface {
+	Admit(req AdmissionRequest) error
+}
+
+// AdmissionFunc is an AdmissionPolicy of a function.
+type AdmissionFunc func(req AdmissionRequest) error
+
+// Admit calls f(req).
+func (f AdmissionFunc) Admit(req AdmissionRequest) error {
+	return f(req)
+}
+
+// admitByPolicy returns the error of Settings.AdmissionPolicy for the request with opts, or nil if it is allowed.
+func (cb *CircuitBreaker) admitByPolicy(state State, opts callOptions, now time.Time) error {
+	if cb.admissionPolicy == nil {
+		return nil
+	}
+	return cb.admissionPolicy.Admit(AdmissionRequest{
+		Name:       cb.name,
+		State:      state,
+		Counts:     cb.counts.snapshot(),
+		Time:       now,
+		Priority:   opts.priority,
+		Idempotent: opts.idempotent,
+		Metadata:   opts.metadata,
+	})
+}
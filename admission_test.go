@@ -0,0 +1,56 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmissionPolicy(t *testing.T) {
+	var seen []AdmissionRequest
+	allow := true
+	cb := NewCircuitBreaker(Settings{
+		Name:          "db",
+		CounterShards: 4,
+		AdmissionPolicy: AdmissionFunc(func(req AdmissionRequest) error {
+			seen = append(seen, req)
+			if !allow {
+				return ErrThrottled
+			}
+			return nil
+		}),
+	})
+
+	assert.Nil(t, succeed(cb))
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, nil }, WithIdempotent())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(seen))
+	assert.Equal(t, "db", seen[0].Name)
+	assert.Equal(t, StateClosed, seen[0].State)
+	assert.False(t, seen[0].Idempotent)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, seen[1].Counts)
+	assert.True(t, seen[1].Idempotent)
+
+	allow = false
+	err = succeed(cb)
+	assert.True(t, IsRejection(err))
+	assert.Equal(t, ErrThrottled, RejectionReason(err))
+	assert.Equal(t, Counts{2, 2, 0, 2, 0}, cb.Counts())
+
+	// the requests rejected by the state never reach the policy
+	cb.Trip()
+	assert.Equal(t, ErrOpenState, RejectionReason(succeed(cb)))
+	assert.Equal(t, 3, len(seen))
+}
+
+func TestAdmissionPolicyCustomError(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	cb := NewCircuitBreaker(Settings{
+		AdmissionPolicy: AdmissionFunc(func(req AdmissionRequest) error { return errQuota }),
+	})
+
+	err := succeed(cb)
+	assert.Equal(t, errQuota, RejectionReason(err))
+	assert.Equal(t, Counts{}, cb.Counts())
+}
@@ -0,0 +1,123 @@
+package gobreaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Result is the result of a request run by ExecuteAsync, ExecuteAll, Pool or ExecuteDetailed.
+//
+// State, Generation, Duration and Rejected are set only by ExecuteDetailed:
+// State and Generation are the state and the generation that allowed or rejected the request,
+// Duration is the time the request took, and Rejected is true if the CircuitBreaker rejected the request.
+type Result struct {
+	Value      interface{}
+	Err        error
+	State      State
+	Generation uint64
+	Duration   time.Duration
+	Rejected   bool
+}
+
+// ExecuteAsync runs the given request in a new goroutine if the CircuitBreaker accepts it,
+// and returns a channel that receives the Result of the request and is closed.
+// The request is allowed at the time of the call, so its outcome is counted in the generation
+// that allowed it even if it completes after the state has changed, like Execute.
+// If the CircuitBreaker rejects the request, the channel receives the rejection at once.
+// A panic in the request is counted like Execute and then crashes the program,
+// because it can't be re-raised in the goroutine of the caller.
+func (cb *CircuitBreaker) ExecuteAsync(req func() (interface{}, error)) <-chan Result {
+	ch := make(chan Result, 1)
+
+	adm, err := cb.beforeRequest(callOptions{})
+	if err != nil {
+		ch <- Result{Err: err}
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer close(ch)
+		value, err := cb.run(adm, req)
+		ch <- Result{Value: value, Err: err}
+	}()
+	return ch
+}
+
+// ExecuteAll runs the given requests with the CircuitBreaker, at most concurrency at a time,
+// and returns their Results in the order of reqs.
+// If concurrency is less than or equal to 0, all the requests run at once.
+// As soon as the CircuitBreaker rejects a request, e.g. because it has tripped in the middle of the batch,
+// ExecuteAll stops issuing the requests, and the requests not yet issued get the same rejection.
+// A panic in a request is counted as it is by Execute, even with Settings.DisablePanicRecovery,
+// and then recovered and returned as a *PanicError in the Result of the request.
+func (cb *CircuitBreaker) ExecuteAll(reqs []func() (interface{}, error), concurrency int) []Result {
+	if concurrency <= 0 || concurrency > len(reqs) {
+		concurrency = len(reqs)
+	}
+
+	results := make([]Result, len(reqs))
+	var (
+		mutex    sync.Mutex
+		next     int
+		rejected error
+	)
+	// issue returns the index of the next request to run, or -1 if there is none.
+	issue := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for next < len(reqs) && rejected != nil {
+			results[next] = Result{Err: rejected}
+			next++
+		}
+		if next == len(reqs) {
+			return -1
+		}
+		next++
+		return next - 1
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := issue(); i >= 0; i = issue() {
+				value, err := cb.executeRecovered(reqs[i])
+				if IsRejection(err) {
+					mutex.Lock()
+					if rejected == nil {
+						rejected = err
+					}
+					mutex.Unlock()
+				}
+				results[i] = Result{Value: value, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// PanicError is the error of the Result of a request that panicked in ExecuteAll.
+// Value is the value passed to panic.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("request panicked: %v", e.Value)
+}
+
+// executeRecovered is Execute returning a panic of req as a *PanicError,
+// so that a panic in a worker of ExecuteAll doesn't crash the process.
+func (cb *CircuitBreaker) executeRecovered(req func() (interface{}, error)) (value interface{}, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			value, err = nil, &PanicError{Value: e}
+		}
+	}()
+	return cb.Execute(req)
+}
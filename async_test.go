@@ -0,0 +1,87 @@
+package gobreaker
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteAsync(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+
+	r := <-cb.ExecuteAsync(func() (interface{}, error) { return 1, nil })
+	assert.Equal(t, Result{Value: 1}, r)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	release := make(chan struct{})
+	ch := cb.ExecuteAsync(func() (interface{}, error) {
+		<-release
+		return nil, errors.New("fail")
+	})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	close(release)
+	r = <-ch
+	assert.EqualError(t, r.Err, "fail")
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Equal(t, Counts{}, cb.Counts())
+
+	cb.Trip()
+	r, ok := <-cb.ExecuteAsync(func() (interface{}, error) { return nil, nil })
+	assert.True(t, ok)
+	assert.True(t, errors.Is(r.Err, ErrOpenState))
+}
+
+func TestExecuteAll(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	var calls int32
+	reqs := make([]func() (interface{}, error), 10)
+	for i := range reqs {
+		i := i
+		reqs[i] = func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			if i < 3 {
+				return i, nil
+			}
+			return nil, errors.New("fail")
+		}
+	}
+
+	results := cb.ExecuteAll(reqs, 1)
+	assert.Equal(t, Result{Value: 0}, results[0])
+	assert.Equal(t, Result{Value: 2}, results[2])
+	for _, r := range results[3:9] {
+		assert.EqualError(t, r.Err, "fail")
+	}
+	assert.Equal(t, ErrOpenState, RejectionReason(results[9].Err))
+	assert.Equal(t, int32(9), atomic.LoadInt32(&calls))
+
+	results = cb.ExecuteAll(reqs, 4)
+	assert.Equal(t, 10, len(results))
+	for _, r := range results {
+		assert.Equal(t, ErrOpenState, RejectionReason(r.Err))
+	}
+	assert.Equal(t, int32(9), atomic.LoadInt32(&calls))
+	assert.Empty(t, cb.ExecuteAll(nil, 0))
+}
+
+func TestExecuteAllPanic(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		cb := NewCircuitBreaker(Settings{DisablePanicRecovery: disabled})
+		results := cb.ExecuteAll([]func() (interface{}, error){
+			func() (interface{}, error) { return 1, nil },
+			func() (interface{}, error) { panic("oops") },
+		}, 2)
+
+		assert.Equal(t, Result{Value: 1}, results[0])
+		var pe *PanicError
+		assert.True(t, errors.As(results[1].Err, &pe))
+		assert.Equal(t, "oops", pe.Value)
+		assert.EqualError(t, results[1].Err, "request panicked: oops")
+		assert.Equal(t, uint32(1), cb.Counts().TotalFailures)
+	}
+}
@@ -0,0 +1,68 @@
+package gobreaker
+
+import "time"
+
+// DoneFunc reports the outcome of a request allowed by TwoStepCircuitBreaker.
+type DoneFunc func(success bool)
+
+// AllowN is like Allow but reserves n sub-requests of a batch at once, with a single round-trip to the lock,
+// and returns a DoneFunc for each of the allowed sub-requests, every one of which must be called.
+// If the CircuitBreaker rejects a sub-request, e.g. in the half-open state after MaxRequests probes,
+// AllowN returns the DoneFuncs of the sub-requests allowed before it together with the rejection,
+// so that the caller issues only the allowed part of the batch.
+func (tscb *TwoStepCircuitBreaker) AllowN(n uint32) (tokens []DoneFunc, err error) {
+	adms, err := tscb.cb.admitN(n)
+	tokens = make([]DoneFunc, len(adms))
+	for i := range adms {
+		tokens[i] = Ticket{cb: tscb.cb, adm: adms[i]}.Done
+	}
+	return tokens, err
+}
+
+// admitN allows up to n requests, stopping at the first rejection.
+// The admissions are not preallocated by n, which may be far more than the CircuitBreaker allows.
+func (cb *CircuitBreaker) admitN(n uint32) ([]admission, error) {
+	var adms []admission
+	cb.lazyInit()
+	if cb.parent != nil {
+		// The parents are asked one by one.
+		for uint32(len(adms)) < n {
+			adm, err := cb.beforeRequest(callOptions{})
+			if err != nil {
+				return adms, err
+			}
+			adms = append(adms, adm)
+		}
+		return adms, nil
+	}
+
+	cb.electProbe(callOptions{})
+	adms, decisions, mc, err := cb.admitNLocked(n)
+
+	// The decisions are observed without the lock, like the ones of beforeRequest.
+	if mc != nil {
+		for i, d := range decisions {
+			mc.ObserveDecision(cb.name, d, i < len(adms))
+		}
+	}
+	return adms, err
+}
+
+// admitNLocked is admitN with the lock,
+// and also returns the time every decision took and the MetricsCollector to observe them.
+func (cb *CircuitBreaker) admitNLocked(n uint32) (adms []admission, decisions []time.Duration, mc MetricsCollector, err error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	mc = cb.metricsCollector
+	for uint32(len(adms)) < n {
+		start := cb.clock.Now()
+		adm, _, err := cb.tryAdmitLocked(callOptions{}, false)
+		decisions = append(decisions, cb.clock.Now().Sub(start))
+		if err != nil {
+			return adms, decisions, mc, err
+		}
+		adms = append(adms, adm)
+	}
+	return adms, decisions, mc, nil
+}
@@ -0,0 +1,51 @@
+package gobreaker
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowN(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{MaxRequests: 2})
+
+	tokens, err := tscb.AllowN(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tokens))
+	assert.Equal(t, uint32(3), tscb.Counts().Requests)
+	tokens[0](true)
+	tokens[1](true)
+	tokens[2](false)
+	assert.Equal(t, Counts{3, 2, 1, 0, 1}, tscb.Counts())
+
+	tscb.cb.Trip()
+	tokens, err = tscb.AllowN(3)
+	assert.True(t, errors.Is(err, ErrOpenState))
+	assert.Empty(t, tokens)
+
+	pseudoSleep(tscb.cb, time.Duration(61)*time.Second)
+	tokens, err = tscb.AllowN(3)
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, StateHalfOpen, tscb.State())
+	for _, done := range tokens {
+		done(true)
+	}
+	assert.Equal(t, StateClosed, tscb.State())
+}
+
+func TestAllowNMetrics(t *testing.T) {
+	hc := NewHistogramCollector()
+	tscb := NewTwoStepCircuitBreaker(Settings{Name: "batch", MaxRequests: 2, MetricsCollector: hc})
+	tscb.cb.Trip()
+	pseudoSleep(tscb.cb, time.Duration(61)*time.Second)
+
+	// n is not preallocated, so a huge batch is only as expensive as the allowed part of it
+	tokens, err := tscb.AllowN(math.MaxUint32)
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, uint64(3), hc.Decisions()["batch"].Count)
+}
@@ -0,0 +1,22 @@
+package gobreaker
+
+// Breaker is the interface of the circuit breakers, so that a library can accept any of them
+// and a user can swap the implementations without changing the call sites.
+// CircuitBreaker and TwoStepCircuitBreaker implement Breaker.
+type Breaker interface {
+	Name() string
+	State() State
+	Counts() Counts
+	Execute(req func() (interface{}, error)) (interface{}, error)
+}
+
+var (
+	_ Breaker = (*CircuitBreaker)(nil)
+	_ Breaker = (*TwoStepCircuitBreaker)(nil)
+)
+
+// Execute runs the given request with the TwoStepCircuitBreaker, so that it implements Breaker.
+// See CircuitBreaker.Execute.
+func (tscb *TwoStepCircuitBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	return tscb.cb.Execute(req)
+}
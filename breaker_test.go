@@ -0,0 +1,20 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	for _, b := range []Breaker{
+		NewCircuitBreaker(Settings{Name: "one-step"}),
+		NewTwoStepCircuitBreaker(Settings{Name: "two-step"}),
+	} {
+		value, err := b.Execute(func() (interface{}, error) { return b.Name(), nil })
+		assert.Equal(t, b.Name(), value)
+		assert.Nil(t, err)
+		assert.Equal(t, StateClosed, b.State())
+		assert.Equal(t, Counts{1, 1, 0, 1, 0}, b.Counts())
+	}
+}
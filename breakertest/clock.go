@@ -0,0 +1,111 @@
+// Package breakertest provides utilities for testing the code using gobreaker.
+package breakertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// FakeClock is a gobreaker.Clock whose time moves only by Advance.
+type FakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	f     func()
+}
+
+// NewFakeClock returns a new FakeClock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current virtual time.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// AfterFunc calls f when Advance moves the virtual time to d after now or later.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) gobreaker.Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the virtual time forward by d.
+// The timers due by then are fired in the order of their times on the goroutine calling Advance,
+// each with the virtual time set to its own time.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	target := c.now.Add(d)
+	c.mutex.Unlock()
+
+	for {
+		c.mutex.Lock()
+		t := c.next(target)
+		if t == nil {
+			c.now = target
+			c.mutex.Unlock()
+			return
+		}
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.mutex.Unlock()
+
+		t.f()
+	}
+}
+
+// AdvanceTo moves the virtual time forward to t like Advance.
+// AdvanceTo does nothing if t is not after the current virtual time.
+func (c *FakeClock) AdvanceTo(t time.Time) {
+	if d := t.Sub(c.Now()); d > 0 {
+		c.Advance(d)
+	}
+}
+
+// next removes and returns the earliest timer due by target, or nil if there is none.
+func (c *FakeClock) next(target time.Time) *fakeTimer {
+	i := -1
+	for j, t := range c.timers {
+		if !t.at.After(target) && (i < 0 || t.at.Before(c.timers[i].at)) {
+			i = j
+		}
+	}
+	if i < 0 {
+		return nil
+	}
+
+	t := c.timers[i]
+	c.timers = append(c.timers[:i], c.timers[i+1:]...)
+	return t
+}
+
+// Stop prevents the timer from firing.
+// Stop returns false if the timer has already fired or been stopped.
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, u := range c.timers {
+		if u == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
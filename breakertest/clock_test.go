@@ -0,0 +1,38 @@
+package breakertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	assert.Equal(t, start, c.Now())
+
+	var fired []time.Time
+	c.AfterFunc(time.Duration(2)*time.Second, func() { fired = append(fired, c.Now()) })
+	c.AfterFunc(time.Duration(1)*time.Second, func() { fired = append(fired, c.Now()) })
+	stopped := c.AfterFunc(time.Duration(1)*time.Second, func() { t.Fatal("stopped timer fired") })
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	c.Advance(time.Duration(1500) * time.Millisecond)
+	assert.Equal(t, []time.Time{start.Add(time.Duration(1) * time.Second)}, fired)
+	assert.Equal(t, start.Add(time.Duration(1500)*time.Millisecond), c.Now())
+
+	c.Advance(time.Duration(1) * time.Second)
+	assert.Equal(t, 2, len(fired))
+	assert.Equal(t, start.Add(time.Duration(2)*time.Second), fired[1])
+}
+
+func TestFakeClockAdvanceTo(t *testing.T) {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	c.AdvanceTo(start.Add(-time.Second))
+	assert.Equal(t, start, c.Now())
+	c.AdvanceTo(start.Add(time.Minute))
+	assert.Equal(t, start.Add(time.Minute), c.Now())
+}
@@ -0,0 +1,86 @@
+package breakertest
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// T is the subset of testing.TB used by the assertions of breakertest.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertState reports an error to t unless cb is in the state want, and returns whether it is.
+func AssertState(t T, cb *gobreaker.CircuitBreaker, want gobreaker.State) bool {
+	t.Helper()
+	if got := cb.State(); got != want {
+		t.Errorf("circuit breaker %q is %s, want %s", cb.Name(), got, want)
+		return false
+	}
+	return true
+}
+
+// AssertCounts reports an error to t unless the Counts of cb are want, and returns whether they are.
+func AssertCounts(t T, cb *gobreaker.CircuitBreaker, want gobreaker.Counts) bool {
+	t.Helper()
+	if got := cb.Counts(); got != want {
+		t.Errorf("circuit breaker %q has %+v, want %+v", cb.Name(), got, want)
+		return false
+	}
+	return true
+}
+
+// ErrInjected is the error returned from the requests failed by Fail and DriveOpen.
+var ErrInjected = errors.New("breakertest: injected failure")
+
+// Succeed runs a successful request with cb, and returns the error of cb if cb rejects it.
+func Succeed(cb *gobreaker.CircuitBreaker) error {
+	_, err := cb.Execute(func() (interface{}, error) { return nil, nil })
+	return err
+}
+
+// Fail runs a request failing with ErrInjected with cb, and returns the error of cb if cb rejects it.
+func Fail(cb *gobreaker.CircuitBreaker) error {
+	_, err := cb.Execute(func() (interface{}, error) { return nil, ErrInjected })
+	if errors.Is(err, ErrInjected) {
+		return nil
+	}
+	return err
+}
+
+// DriveOpen fails the requests with cb until cb trips, up to max requests,
+// and returns the number of the failed requests and whether cb has tripped.
+func DriveOpen(cb *gobreaker.CircuitBreaker, max int) (int, bool) {
+	return drive(cb, Fail, gobreaker.StateOpen, max)
+}
+
+// DriveClosed succeeds the requests with cb until cb closes, up to max requests,
+// and returns the number of the successful requests and whether cb has closed.
+func DriveClosed(cb *gobreaker.CircuitBreaker, max int) (int, bool) {
+	return drive(cb, Succeed, gobreaker.StateClosed, max)
+}
+
+func drive(cb *gobreaker.CircuitBreaker, request func(cb *gobreaker.CircuitBreaker) error, state gobreaker.State, max int) (int, bool) {
+	for n := 0; n < max; n++ {
+		if cb.State() == state {
+			return n, true
+		}
+		if err := request(cb); err != nil {
+			return n, false
+		}
+	}
+	return max, cb.State() == state
+}
+
+// DriveHalfOpen advances clock past the expiry of the open state of cb, which must use clock,
+// and returns whether cb has become half-open.
+func DriveHalfOpen(clock *FakeClock, cb *gobreaker.CircuitBreaker) bool {
+	if cb.State() != gobreaker.StateOpen {
+		return false
+	}
+	clock.AdvanceTo(cb.Expiry().Add(time.Nanosecond))
+	return cb.State() == gobreaker.StateHalfOpen
+}
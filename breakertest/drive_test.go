@@ -0,0 +1,53 @@
+package breakertest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestDrive(t *testing.T) {
+	clock := NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{Name: "drive", Clock: clock, MaxRequests: 2})
+
+	n, ok := DriveOpen(cb, 10)
+	assert.Equal(t, 6, n)
+	assert.True(t, ok)
+	assert.True(t, AssertState(t, cb, gobreaker.StateOpen))
+	assert.True(t, gobreaker.IsRejection(Succeed(cb)))
+
+	assert.True(t, DriveHalfOpen(clock, cb))
+	assert.False(t, DriveHalfOpen(clock, cb))
+	assert.Nil(t, Fail(cb))
+	assert.True(t, DriveHalfOpen(clock, cb))
+
+	n, ok = DriveClosed(cb, 10)
+	assert.Equal(t, 2, n)
+	assert.True(t, ok)
+	assert.True(t, AssertCounts(t, cb, gobreaker.Counts{}))
+
+	n, ok = DriveOpen(cb, 3)
+	assert.Equal(t, 3, n)
+	assert.False(t, ok)
+
+	rt := &recordingT{}
+	assert.False(t, AssertState(rt, cb, gobreaker.StateOpen))
+	assert.False(t, AssertCounts(rt, cb, gobreaker.Counts{}))
+	assert.Equal(t, []string{
+		`circuit breaker "drive" is closed, want open`,
+		`circuit breaker "drive" has {Requests:3 TotalSuccesses:0 TotalFailures:3 ConsecutiveSuccesses:0 ConsecutiveFailures:3}, want {Requests:0 TotalSuccesses:0 TotalFailures:0 ConsecutiveSuccesses:0 ConsecutiveFailures:0}`,
+	}, rt.errors)
+}
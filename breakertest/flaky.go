@@ -0,0 +1,211 @@
+package breakertest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// ErrUnavailable is the error returned from FlakyServer.Call for the responses failed by FlakyServer.
+var ErrUnavailable = errors.New("breakertest: service unavailable")
+
+// Phase is a phase of the script of FlakyServer.
+//
+// Name names the phase, e.g. "healthy", "erroring" or "recovering".
+// Requests is the number of the requests the phase serves before FlakyServer moves on to the next phase.
+// If Requests is 0, the phase serves the requests until Next is called, or forever if it is the last phase.
+// FailureRate is the fraction of the requests of the phase failed with 503 Service Unavailable.
+// The failures are spread evenly over the requests, so that a script gives the same outcomes every run.
+// Latency delays every response of the phase.
+type Phase struct {
+	Name        string
+	Requests    int
+	FailureRate float64
+	Latency     time.Duration
+}
+
+// Healthy returns the Phase named "healthy" serving n requests without a failure.
+func Healthy(n int) Phase {
+	return Phase{Name: "healthy", Requests: n}
+}
+
+// Erroring returns the Phase named "erroring" failing every one of n requests.
+func Erroring(n int) Phase {
+	return Phase{Name: "erroring", Requests: n, FailureRate: 1}
+}
+
+// Recovering returns the Phase named "recovering" failing the fraction failureRate of n requests.
+func Recovering(n int, failureRate float64) Phase {
+	return Phase{Name: "recovering", Requests: n, FailureRate: failureRate}
+}
+
+// FlakyServer is an httptest.Server failing the requests by a script of phases,
+// e.g. healthy, then erroring, and then recovering, to drive a CircuitBreaker
+// through realistic cycles of the open, the half-open and the closed states end to end.
+// After the last phase, FlakyServer keeps serving the requests as in the last phase.
+type FlakyServer struct {
+	*httptest.Server
+
+	mutex  sync.Mutex
+	phases []Phase
+	phase  int
+	served int
+	total  int
+}
+
+// NewFlakyServer starts and returns a new FlakyServer following the given phases.
+// If no phase is given, FlakyServer serves the requests without a failure.
+// The caller should call Close when finished, to shut it down.
+func NewFlakyServer(phases ...Phase) *FlakyServer {
+	if len(phases) == 0 {
+		phases = []Phase{{Name: "healthy"}}
+	}
+	s := &FlakyServer{phases: phases}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *FlakyServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	p, fail := s.next()
+	if p.Latency > 0 {
+		time.Sleep(p.Latency)
+	}
+
+	if fail {
+		http.Error(w, fmt.Sprintf("breakertest: %s", p.Name), http.StatusServiceUnavailable)
+		return
+	}
+	io.WriteString(w, p.Name)
+}
+
+// next counts a request and returns its Phase and whether it fails.
+func (s *FlakyServer) next() (Phase, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.phases[s.phase]
+	i := s.served
+	fail := int(float64(i+1)*p.FailureRate) > int(float64(i)*p.FailureRate)
+
+	s.served++
+	s.total++
+	if p.Requests > 0 && s.served >= p.Requests {
+		s.advance()
+	}
+	return p, fail
+}
+
+func (s *FlakyServer) advance() {
+	if s.phase < len(s.phases)-1 {
+		s.phase++
+		s.served = 0
+	}
+}
+
+// Next moves FlakyServer on to the next phase, unless it is in the last phase.
+func (s *FlakyServer) Next() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.advance()
+}
+
+// Phase returns the name of the current phase.
+func (s *FlakyServer) Phase() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.phases[s.phase].Name
+}
+
+// Requests returns the number of the requests FlakyServer has served.
+func (s *FlakyServer) Requests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.total
+}
+
+// Call sends a GET request to FlakyServer with cb, and returns ErrUnavailable if FlakyServer fails it,
+// or the error of cb if cb rejects it.
+func (s *FlakyServer) Call(cb *gobreaker.CircuitBreaker) error {
+	_, err := cb.Execute(func() (interface{}, error) {
+		resp, err := s.Client().Get(s.URL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, ErrUnavailable
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// Transitions records the state changes of CircuitBreakers for AssertTransitions.
+type Transitions struct {
+	mutex  sync.Mutex
+	events []gobreaker.StateChangeEvent
+}
+
+// Record returns a copy of st recording the state changes of the CircuitBreaker in tr.
+// Settings.OnStateChangeDetailed of st is still called.
+func (tr *Transitions) Record(st gobreaker.Settings) gobreaker.Settings {
+	onStateChange := st.OnStateChangeDetailed
+	st.OnStateChangeDetailed = func(ev gobreaker.StateChangeEvent) {
+		tr.mutex.Lock()
+		tr.events = append(tr.events, ev)
+		tr.mutex.Unlock()
+
+		if onStateChange != nil {
+			onStateChange(ev)
+		}
+	}
+	return st
+}
+
+// Events returns the recorded state changes in the order they happened.
+func (tr *Transitions) Events() []gobreaker.StateChangeEvent {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+
+	return append([]gobreaker.StateChangeEvent(nil), tr.events...)
+}
+
+// States returns the states the recorded state changes led to, in the order they happened.
+func (tr *Transitions) States() []gobreaker.State {
+	events := tr.Events()
+	states := make([]gobreaker.State, len(events))
+	for i, ev := range events {
+		states[i] = ev.To
+	}
+	return states
+}
+
+// AssertTransitions reports an error to t unless the states the state changes recorded in tr led to are want,
+// and returns whether they are.
+func AssertTransitions(t T, tr *Transitions, want ...gobreaker.State) bool {
+	t.Helper()
+	got := tr.States()
+	if len(got) != len(want) {
+		t.Errorf("circuit breaker changed states to %v, want %v", got, want)
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("circuit breaker changed states to %v, want %v", got, want)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package breakertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlakyServer(t *testing.T) {
+	s := NewFlakyServer(Healthy(10), Erroring(10), Recovering(0, 0))
+	defer s.Close()
+
+	clock := NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	var tr Transitions
+	cb := gobreaker.NewCircuitBreaker(tr.Record(gobreaker.Settings{Name: "flaky", Clock: clock}))
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, s.Call(cb))
+	}
+	assert.Equal(t, "erroring", s.Phase())
+
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, ErrUnavailable, s.Call(cb))
+	}
+	assert.True(t, AssertState(t, cb, gobreaker.StateOpen))
+	assert.True(t, gobreaker.IsRejection(s.Call(cb)))
+	assert.Equal(t, 16, s.Requests())
+
+	for s.Phase() == "erroring" {
+		assert.True(t, DriveHalfOpen(clock, cb))
+		assert.Equal(t, ErrUnavailable, s.Call(cb))
+	}
+	assert.Equal(t, "recovering", s.Phase())
+
+	assert.True(t, DriveHalfOpen(clock, cb))
+	assert.Nil(t, s.Call(cb))
+	assert.True(t, AssertState(t, cb, gobreaker.StateClosed))
+	assert.Equal(t, 21, s.Requests())
+
+	open, halfOpen, closed := gobreaker.StateOpen, gobreaker.StateHalfOpen, gobreaker.StateClosed
+	assert.True(t, AssertTransitions(t, &tr,
+		open, halfOpen, open, halfOpen, open, halfOpen, open, halfOpen, open, halfOpen, closed))
+
+	rt := &recordingT{}
+	assert.False(t, AssertTransitions(rt, &tr, open))
+	assert.Equal(t, 1, len(rt.errors))
+}
+
+func TestFlakyServerFailureRate(t *testing.T) {
+	s := NewFlakyServer(Recovering(4, 0.5), Phase{Name: "down", FailureRate: 1})
+	defer s.Close()
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{})
+
+	var failures int
+	for i := 0; i < 4; i++ {
+		if s.Call(cb) == ErrUnavailable {
+			failures++
+		}
+	}
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, "down", s.Phase())
+	assert.Equal(t, ErrUnavailable, s.Call(cb))
+
+	s.Next()
+	assert.Equal(t, "down", s.Phase())
+}
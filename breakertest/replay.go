@@ -0,0 +1,107 @@
+package breakertest
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// RecordedRequest is a request recorded from the real traffic, e.g. from the access logs, to be replayed by Replay.
+// Time is the time the request started, and the request completes after Latency with Success.
+type RecordedRequest struct {
+	Time    time.Time
+	Success bool
+	Latency time.Duration
+}
+
+// ReplayReport summarizes a run of Replay.
+// Rejections is the number of the requests the CircuitBreaker would have rejected,
+// and Transitions are the state changes of the CircuitBreaker in the order they happened.
+// Lifetime is the Lifetime of the CircuitBreaker at the end of the replay.
+type ReplayReport struct {
+	Requests    uint64
+	Allowed     uint64
+	Rejections  uint64
+	Transitions []gobreaker.StateChangeEvent
+	Lifetime    gobreaker.Lifetime
+}
+
+// completion is the completion of an allowed request pending in the replay.
+type completion struct {
+	at      time.Time
+	success bool
+	done    func(success bool)
+}
+
+type completions []completion
+
+func (c completions) Len() int            { return len(c) }
+func (c completions) Less(i, j int) bool  { return c[i].at.Before(c[j].at) }
+func (c completions) Swap(i, j int)       { c[i], c[j] = c[j], c[i] }
+func (c *completions) Push(x interface{}) { *c = append(*c, x.(completion)) }
+
+func (c *completions) Pop() interface{} {
+	old := *c
+	x := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return x
+}
+
+// Replay drives a CircuitBreaker with st through the recorded requests in virtual time,
+// so that the thresholds such as ReadyToTrip can be tuned offline against the traces of the real traffic.
+// Each request is allowed by the CircuitBreaker at its Time, and its outcome is reported after its Latency,
+// so the requests overlap as they did. The requests don't have to be sorted.
+// Settings.Clock is replaced with a FakeClock starting at the time of the first request,
+// and Settings.OnStateChangeDetailed is still called.
+func Replay(st gobreaker.Settings, requests []RecordedRequest) ReplayReport {
+	var report ReplayReport
+	if len(requests) == 0 {
+		return report
+	}
+
+	sorted := make([]RecordedRequest, len(requests))
+	copy(sorted, requests)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	clock := NewFakeClock(sorted[0].Time)
+	st.Clock = clock
+	onStateChange := st.OnStateChangeDetailed
+	st.OnStateChangeDetailed = func(ev gobreaker.StateChangeEvent) {
+		report.Transitions = append(report.Transitions, ev)
+		if onStateChange != nil {
+			onStateChange(ev)
+		}
+	}
+	tscb := gobreaker.NewTwoStepCircuitBreaker(st)
+
+	var pending completions
+	completeUntil := func(t time.Time) {
+		for len(pending) > 0 && !pending[0].at.After(t) {
+			c := heap.Pop(&pending).(completion)
+			clock.AdvanceTo(c.at)
+			c.done(c.success)
+		}
+		clock.AdvanceTo(t)
+	}
+
+	for _, req := range sorted {
+		completeUntil(req.Time)
+
+		report.Requests++
+		done, err := tscb.Allow()
+		if err != nil {
+			report.Rejections++
+			continue
+		}
+		report.Allowed++
+		heap.Push(&pending, completion{at: req.Time.Add(req.Latency), success: req.Success, done: done})
+	}
+	for len(pending) > 0 {
+		completeUntil(pending[0].at)
+	}
+
+	report.Lifetime = tscb.Lifetime()
+	return report
+}
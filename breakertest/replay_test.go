@@ -0,0 +1,41 @@
+package breakertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	requests := []RecordedRequest{{Time: at(70000), Success: true, Latency: 100 * time.Millisecond}}
+	for i := 0; i < 6; i++ {
+		requests = append(requests, RecordedRequest{Time: at(i * 1000), Latency: 1500 * time.Millisecond})
+	}
+	for i := 7; i < 11; i++ {
+		requests = append(requests, RecordedRequest{Time: at(i * 1000), Success: true})
+	}
+
+	var changes int
+	report := Replay(gobreaker.Settings{
+		OnStateChangeDetailed: func(gobreaker.StateChangeEvent) { changes++ },
+	}, requests)
+
+	assert.Equal(t, uint64(11), report.Requests)
+	assert.Equal(t, uint64(7), report.Allowed)
+	assert.Equal(t, uint64(4), report.Rejections)
+	assert.Equal(t, 3, changes)
+	assert.Len(t, report.Transitions, 3)
+	assert.Equal(t, gobreaker.StateOpen, report.Transitions[0].To)
+	assert.Equal(t, at(6500), report.Transitions[0].Time)
+	assert.Equal(t, gobreaker.StateClosed, report.Transitions[2].To)
+	assert.Equal(t, at(70100), report.Transitions[2].Time)
+	assert.Equal(t, uint64(1), report.Lifetime.Trips)
+	assert.Equal(t, start, report.Lifetime.Since)
+
+	assert.Equal(t, ReplayReport{}, Replay(gobreaker.Settings{}, nil))
+}
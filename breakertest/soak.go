@@ -0,0 +1,231 @@
+package breakertest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// SoakConfig configures Soak.
+//
+// Settings are the Settings of the CircuitBreaker under the test.
+// Settings.Clock is replaced with a FakeClock.
+//
+// Duration is the virtual time to run the CircuitBreaker.
+// If Duration is 0, Duration is set to 6 hours.
+//
+// Step is the virtual time between two requests.
+// If Step is 0, Step is set to 100 milliseconds.
+//
+// FailureRate returns the probability that a request fails at the elapsed virtual time.
+// If FailureRate is nil, the dependency fails 5% of the requests,
+// except in the outage of the last 5 minutes of every 30 minutes, when it fails 90% of them.
+//
+// Seed is the seed of the random failures, so that a failed run can be reproduced.
+//
+// MaxHeapGrowth is the allowed growth of the heap in bytes at the end of the run.
+// If MaxHeapGrowth is 0, MaxHeapGrowth is set to 32 MiB.
+type SoakConfig struct {
+	Settings      gobreaker.Settings
+	Duration      time.Duration
+	Step          time.Duration
+	FailureRate   func(elapsed time.Duration) float64
+	Seed          int64
+	MaxHeapGrowth uint64
+}
+
+// SoakReport summarizes a run of Soak.
+type SoakReport struct {
+	Requests     uint64
+	Successes    uint64
+	Failures     uint64
+	Rejections   uint64
+	StateChanges uint64
+	Events       uint64
+	HeapGrowth   int64
+}
+
+// errSoak is the error of the simulated failures.
+var errSoak = errors.New("breakertest: simulated failure")
+
+func defaultFailureRate(elapsed time.Duration) float64 {
+	if elapsed%(time.Duration(30)*time.Minute) >= time.Duration(25)*time.Minute {
+		return 0.9
+	}
+	return 0.05
+}
+
+// Soak runs a CircuitBreaker with simulated failures for hours of virtual time
+// and checks the invariants of the CircuitBreaker on the way:
+// the Counts are consistent with the outcomes of the requests,
+// no goroutine is left behind after the run, and the heap stays bounded.
+// Soak returns the first violation as an error.
+//
+// Soak is meant for long-running jobs such as CI, e.g.
+//
+//	report, err := breakertest.Soak(breakertest.SoakConfig{Settings: st})
+func Soak(cfg SoakConfig) (SoakReport, error) {
+	if cfg.Duration <= 0 {
+		cfg.Duration = time.Duration(6) * time.Hour
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = time.Duration(100) * time.Millisecond
+	}
+	if cfg.FailureRate == nil {
+		cfg.FailureRate = defaultFailureRate
+	}
+	if cfg.MaxHeapGrowth == 0 {
+		cfg.MaxHeapGrowth = 32 << 20
+	}
+	isSuccessful := cfg.Settings.IsSuccessful
+	if isSuccessful == nil {
+		isSuccessful = func(err error) bool { return err == nil }
+	}
+
+	var report SoakReport
+	heap := heapAlloc()
+
+	clock := NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	st := cfg.Settings
+	st.Clock = clock
+	cb := gobreaker.NewCircuitBreaker(st)
+	goroutines := runtime.NumGoroutine()
+
+	events := cb.Subscribe()
+	drained := make(chan uint64)
+	go func() {
+		var n uint64
+		for range events {
+			n++
+		}
+		drained <- n
+	}()
+
+	err := soak(cb, clock, cfg, isSuccessful, &report)
+
+	cb.Unsubscribe(events)
+	report.Events = <-drained
+
+	if err == nil {
+		err = checkGoroutines(clock, cfg.Step, goroutines)
+	}
+
+	report.HeapGrowth = int64(heapAlloc()) - int64(heap)
+	if err == nil && report.HeapGrowth > int64(cfg.MaxHeapGrowth) {
+		err = fmt.Errorf("breakertest: heap grew by %d bytes", report.HeapGrowth)
+	}
+
+	runtime.KeepAlive(cb)
+	return report, err
+}
+
+func soak(cb *gobreaker.CircuitBreaker, clock *FakeClock, cfg SoakConfig, isSuccessful func(err error) bool, report *SoakReport) error {
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+	checkDrift := cfg.Settings.HealthCheck == nil
+
+	state := cb.State()
+	before := cb.Counts()
+	for elapsed := time.Duration(0); elapsed < cfg.Duration; elapsed += cfg.Step {
+		fail := rnd.Float64() < cfg.FailureRate(elapsed)
+
+		_, err := cb.Execute(func() (interface{}, error) {
+			if fail {
+				return nil, errSoak
+			}
+			return nil, nil
+		})
+
+		report.Requests++
+		rejected := gobreaker.IsRejection(err)
+		success := !rejected && isSuccessful(err)
+		switch {
+		case rejected:
+			report.Rejections++
+		case success:
+			report.Successes++
+		default:
+			report.Failures++
+		}
+
+		after := cb.Counts()
+		if err := checkCounts(after); err != nil {
+			return fmt.Errorf("breakertest: at %v: %v", elapsed, err)
+		}
+		if checkDrift && !rejected && !follows(before, after, success) {
+			return fmt.Errorf("breakertest: at %v: counts drifted from %+v to %+v", elapsed, before, after)
+		}
+		before = after
+
+		if s := cb.State(); s != state {
+			report.StateChanges++
+			state = s
+		}
+
+		clock.Advance(cfg.Step)
+	}
+
+	if report.Successes+report.Failures+report.Rejections != report.Requests {
+		return fmt.Errorf("breakertest: %d requests are lost", report.Requests-report.Successes-report.Failures-report.Rejections)
+	}
+	return nil
+}
+
+// checkCounts checks the invariants of Counts when no request is in flight.
+func checkCounts(c gobreaker.Counts) error {
+	switch {
+	case c.Requests != c.TotalSuccesses+c.TotalFailures:
+		return fmt.Errorf("requests don't match the outcomes: %+v", c)
+	case c.ConsecutiveSuccesses > c.TotalSuccesses || c.ConsecutiveFailures > c.TotalFailures:
+		return fmt.Errorf("consecutive counts exceed the totals: %+v", c)
+	case c.ConsecutiveSuccesses > 0 && c.ConsecutiveFailures > 0:
+		return fmt.Errorf("both consecutive counts are positive: %+v", c)
+	}
+	return nil
+}
+
+// follows reports whether after follows from before by one request with the given outcome,
+// allowing for the Counts cleared by a new generation.
+func follows(before, after gobreaker.Counts, success bool) bool {
+	if after.Requests <= 1 {
+		return true
+	}
+
+	expected := before
+	expected.Requests++
+	if success {
+		expected.TotalSuccesses++
+		expected.ConsecutiveSuccesses++
+		expected.ConsecutiveFailures = 0
+	} else {
+		expected.TotalFailures++
+		expected.ConsecutiveFailures++
+		expected.ConsecutiveSuccesses = 0
+	}
+	return after == expected
+}
+
+// checkGoroutines waits for the goroutines started during the run to exit,
+// advancing the clock so that the background loops notice the end of their work.
+func checkGoroutines(clock *FakeClock, step time.Duration, limit int) error {
+	var n int
+	for i := 0; i < 100; i++ {
+		n = runtime.NumGoroutine()
+		if n <= limit {
+			return nil
+		}
+		clock.Advance(step)
+		time.Sleep(time.Duration(10) * time.Millisecond)
+	}
+	return fmt.Errorf("breakertest: %d goroutines leaked", n-limit)
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
@@ -0,0 +1,57 @@
+package breakertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoak(t *testing.T) {
+	report, err := Soak(SoakConfig{
+		Settings: gobreaker.Settings{
+			Interval:       time.Duration(1) * time.Minute,
+			DowntimeBudget: time.Duration(1) * time.Hour,
+			AuditLogSize:   8,
+		},
+		Duration: time.Duration(2) * time.Hour,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(72000), report.Requests)
+	assert.True(t, report.StateChanges > 0)
+	assert.True(t, report.Rejections > 0)
+	assert.True(t, report.Events > 0)
+}
+
+func TestSoakDetectsDrift(t *testing.T) {
+	assert.True(t, follows(counts(2, 1, 1, 0, 1), counts(3, 2, 1, 1, 0), true))
+	assert.True(t, follows(counts(2, 1, 1, 0, 1), counts(1, 0, 1, 0, 1), false))
+	assert.False(t, follows(counts(2, 1, 1, 0, 1), counts(3, 1, 2, 0, 1), false))
+
+	assert.NotNil(t, checkCounts(counts(2, 1, 0, 1, 0)))
+	assert.NotNil(t, checkCounts(counts(2, 1, 1, 1, 1)))
+	assert.Nil(t, checkCounts(counts(2, 1, 1, 1, 0)))
+}
+
+func counts(requests, successes, failures, consecutiveSuccesses, consecutiveFailures uint32) gobreaker.Counts {
+	return gobreaker.Counts{
+		Requests:             requests,
+		TotalSuccesses:       successes,
+		TotalFailures:        failures,
+		ConsecutiveSuccesses: consecutiveSuccesses,
+		ConsecutiveFailures:  consecutiveFailures,
+	}
+}
+
+func TestSoakWithHealthCheck(t *testing.T) {
+	_, err := Soak(SoakConfig{
+		Settings: gobreaker.Settings{
+			HealthCheck:          func(ctx context.Context) error { return nil },
+			HealthCheckSuccesses: 3,
+		},
+		Duration: time.Duration(1) * time.Hour,
+	})
+	assert.Nil(t, err)
+}
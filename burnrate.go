@@ -0,0 +1,122 @@
+package gobreaker
+
+import "time"
+
+// BurnRate is a trip policy based on the burn rate of the error budget, like the multiwindow alerts of an SLO.
+// Set it to Settings.BurnRate.
+//
+// Target is the target success rate of the requests, e.g. 0.999, and must be between 0 and 1 exclusive.
+// The burn rate over a window is the failure rate of the requests completed in the window
+// divided by the error budget, 1 - Target, so a burn rate of 1 consumes exactly the budget.
+//
+// The CircuitBreaker trips on a failure when the burn rate over FastWindow is FastThreshold or more
+// and the burn rate over SlowWindow is SlowThreshold or more,
+// so that it trips quickly on a severe outage but not on a short burst of failures.
+// If FastWindow or SlowWindow is less than or equal to 0, it is set to 5 minutes or 1 hour respectively.
+// If FastThreshold or SlowThreshold is less than or equal to 0, it is set to 14.4,
+// which consumes 2% of a 30-day budget in an hour.
+//
+// MinRequests is the minimum number of the requests completed in FastWindow for the burn rates to be evaluated.
+// If MinRequests is 0, MinRequests is set to 20.
+type BurnRate struct {
+	Target        float64
+	FastWindow    time.Duration
+	FastThreshold float64
+	SlowWindow    time.Duration
+	SlowThreshold float64
+	MinRequests   uint32
+}
+
+const (
+	defaultBurnRateFastWindow  = time.Duration(5) * time.Minute
+	defaultBurnRateSlowWindow  = time.Duration(60) * time.Minute
+	defaultBurnRateThreshold   = 14.4
+	defaultBurnRateMinRequests = 20
+)
+
+func (p BurnRate) withDefaults() BurnRate {
+	if p.FastWindow <= 0 {
+		p.FastWindow = defaultBurnRateFastWindow
+	}
+	if p.FastThreshold <= 0 {
+		p.FastThreshold = defaultBurnRateThreshold
+	}
+	if p.SlowWindow <= 0 {
+		p.SlowWindow = defaultBurnRateSlowWindow
+	}
+	if p.SlowThreshold <= 0 {
+		p.SlowThreshold = defaultBurnRateThreshold
+	}
+	if p.MinRequests == 0 {
+		p.MinRequests = defaultBurnRateMinRequests
+	}
+	return p
+}
+
+// burnRateWindows counts the outcomes of the requests in the closed state over the windows of a BurnRate.
+// Unlike Counts, the windows are not cleared by the generations but only when the CircuitBreaker closes.
+type burnRateWindows struct {
+	policy BurnRate
+	fast   *rollingWindow
+	slow   *rollingWindow
+}
+
+func newBurnRateWindows(policy BurnRate) *burnRateWindows {
+	return &burnRateWindows{
+		policy: policy,
+		fast:   newRollingWindow(policy.FastWindow),
+		slow:   newRollingWindow(policy.SlowWindow),
+	}
+}
+
+func (b *burnRateWindows) onSuccess(now time.Time) {
+	b.fast.onSuccess(now)
+	b.slow.onSuccess(now)
+}
+
+func (b *burnRateWindows) onFailure(now time.Time) {
+	b.fast.onFailure(now)
+	b.slow.onFailure(now)
+}
+
+func (b *burnRateWindows) add(now time.Time, successes, failures uint32) {
+	b.fast.add(now, 0, successes, failures)
+	b.slow.add(now, 0, successes, failures)
+}
+
+func (b *burnRateWindows) clear() {
+	b.fast = newRollingWindow(b.policy.FastWindow)
+	b.slow = newRollingWindow(b.policy.SlowWindow)
+}
+
+// exceeded reports whether the burn rates over both of the windows reach their thresholds.
+func (b *burnRateWindows) exceeded(now time.Time) bool {
+	_, fastSuccesses, fastFailures := b.fast.totals(now)
+	if fastSuccesses+fastFailures < b.policy.MinRequests {
+		return false
+	}
+
+	_, slowSuccesses, slowFailures := b.slow.totals(now)
+	return b.rate(fastSuccesses, fastFailures) >= b.policy.FastThreshold &&
+		b.rate(slowSuccesses, slowFailures) >= b.policy.SlowThreshold
+}
+
+func (b *burnRateWindows) rate(successes, failures uint32) float64 {
+	if successes+failures == 0 {
+		return 0
+	}
+	return float64(failures) / float64(successes+failures) / (1 - b.policy.Target)
+}
+
+// setBurnRate applies Settings.BurnRate, keeping the counted outcomes if the policy is unchanged.
+func (cb *CircuitBreaker) setBurnRate(p *BurnRate) {
+	if p == nil {
+		cb.burnRate = nil
+		return
+	}
+
+	policy := p.withDefaults()
+	if cb.burnRate == nil || cb.burnRate.policy != policy {
+		cb.burnRate = newBurnRateWindows(policy)
+	}
+}
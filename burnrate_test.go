@@ -0,0 +1,73 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurnRate(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(Settings{
+		Clock: clock,
+		BurnRate: &BurnRate{
+			Target:        0.9,
+			FastWindow:    time.Minute,
+			FastThreshold: 5,
+			SlowWindow:    time.Duration(10) * time.Minute,
+			SlowThreshold: 2,
+		},
+	})
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	clock.advance(time.Duration(9) * time.Minute)
+
+	// the fast window burns at 10, but the slow window burns at 2 only after 25 failures
+	for i := 0; i < 24; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	// the windows are cleared by closing, so a failure alone doesn't trip
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestBurnRateDefaults(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{BurnRate: &BurnRate{Target: 0.99}})
+	assert.Equal(t, BurnRate{
+		Target:        0.99,
+		FastWindow:    time.Duration(5) * time.Minute,
+		FastThreshold: 14.4,
+		SlowWindow:    time.Duration(60) * time.Minute,
+		SlowThreshold: 14.4,
+		MinRequests:   20,
+	}, cb.burnRate.policy)
+
+	windows := cb.burnRate
+	cb.UpdateSettings(Settings{BurnRate: &BurnRate{Target: 0.99}})
+	assert.True(t, windows == cb.burnRate)
+	cb.UpdateSettings(Settings{})
+	assert.Nil(t, cb.burnRate)
+}
+
+func TestBurnRateValidate(t *testing.T) {
+	err := Settings{BurnRate: &BurnRate{Target: 1}}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+
+	err = Settings{BurnRate: &BurnRate{Target: 0.99}, ReadyToTrip: func(counts Counts) bool { return true }}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+
+	assert.Nil(t, Settings{BurnRate: &BurnRate{Target: 0.99}}.Validate())
+}
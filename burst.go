@@ -0,0 +1,47 @@
+package gobreaker
+
+import "time"
+
+// failureBurst counts the failures in the closed state over the last Settings.BurstWindow.
+type failureBurst struct {
+	window    *rollingWindow
+	threshold float64
+}
+
+// setBurst applies Settings.BurstWindow and Settings.BurstThreshold,
+// keeping the counted failures if the window is unchanged.
+func (cb *CircuitBreaker) setBurst(window time.Duration, threshold float64) {
+	if window <= 0 {
+		cb.burst = nil
+		return
+	}
+
+	if cb.burst == nil || cb.burst.window.size != window {
+		cb.burst = &failureBurst{window: newRollingWindow(window)}
+	}
+	cb.burst.threshold = threshold
+}
+
+// velocity returns the failures per second over the window until now.
+func (b *failureBurst) velocity(now time.Time) float64 {
+	_, _, failures := b.window.totals(now)
+	return float64(failures) / b.window.size.Seconds()
+}
+
+// exceeded reports whether the failure velocity reaches the threshold.
+func (b *failureBurst) exceeded(now time.Time) bool {
+	return b.threshold > 0 && b.velocity(now) >= b.threshold
+}
+
+func (b *failureBurst) clear() {
+	b.window = newRollingWindow(b.window.size)
+}
+
+// snapshotStats returns the Stats of the current generation with the failure velocity of BurstWindow.
+func (cb *CircuitBreaker) snapshotStats() Stats {
+	st := cb.stats.snapshot()
+	if cb.burst != nil {
+		st.FailureVelocity = cb.burst.velocity(cb.clock.Now())
+	}
+	return st
+}
@@ -0,0 +1,54 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurst(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	cb := NewCircuitBreaker(Settings{
+		Clock:          clock,
+		ReadyToTrip:    RateHysteresis{TripFailureRatio: 0.5, MinRequests: 100}.ReadyToTrip,
+		BurstWindow:    time.Duration(10) * time.Second,
+		BurstThreshold: 0.5,
+	})
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, fail(cb))
+		clock.advance(time.Second)
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, 0.4, cb.Stats().FailureVelocity)
+
+	// the failures spread over more than BurstWindow don't trip
+	clock.advance(time.Duration(10) * time.Second)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, CauseReadyToTrip, cb.LastTransition().Cause)
+}
+
+func TestBurstWithStats(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	var velocities []float64
+	cb := NewCircuitBreaker(Settings{
+		Clock: clock,
+		ReadyToTripWithStats: func(counts Counts, stats Stats) bool {
+			velocities = append(velocities, stats.FailureVelocity)
+			return false
+		},
+		BurstWindow:    time.Duration(2) * time.Second,
+		BurstThreshold: 100,
+	})
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, []float64{0.5, 1}, velocities)
+}
@@ -0,0 +1,69 @@
+package gobreaker
+
+// Disable bypasses the CircuitBreaker, e.g. for a canary rollout or an emergency during an incident.
+// Disable places the CircuitBreaker into the closed state, and while it is disabled,
+// the CircuitBreaker allows all requests and never changes its state by itself.
+// If Settings.CountWhileDisabled is true, the requests and their outcomes are still counted.
+func (cb *CircuitBreaker) Disable() {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.disabled {
+		return
+	}
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+	cb.audit("disable", now)
+	cb.disabled = true
+	if cb.state == StateClosed {
+		cb.toNewGeneration(now)
+	} else {
+		cb.setState(StateClosed, now, CauseManual)
+	}
+}
+
+// Enable stops bypassing the CircuitBreaker disabled by Disable.
+// The CircuitBreaker starts a new generation in the closed state,
+// so the outcomes counted while it was disabled don't trip it.
+func (cb *CircuitBreaker) Enable() {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if !cb.disabled {
+		return
+	}
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+	cb.audit("enable", now)
+	cb.disabled = false
+	if cb.state == StateClosed {
+		cb.toNewGeneration(now)
+	} else {
+		cb.setState(StateClosed, now, CauseManual)
+	}
+}
+
+// Disabled reports whether the CircuitBreaker is disabled by Disable.
+func (cb *CircuitBreaker) Disabled() bool {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.disabled
+}
+
+// countDisabled counts the outcome of a request allowed while the CircuitBreaker is disabled.
+func (cb *CircuitBreaker) countDisabled(success bool) {
+	if !cb.countWhileDisabled {
+		return
+	}
+	if success {
+		cb.counts.onSuccess()
+	} else {
+		cb.counts.onFailure()
+	}
+}
@@ -0,0 +1,53 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisableAndEnable(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{AuditLogSize: 4})
+	cb.Trip()
+	assert.Equal(t, StateOpen, cb.State())
+
+	cb.Disable()
+	assert.True(t, cb.Disabled())
+	assert.Equal(t, StateClosed, cb.State())
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	cb.ReportExternalFailure("pool exhausted")
+	cb.Trip()
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{}, cb.Counts())
+
+	cb.Enable()
+	assert.False(t, cb.Disabled())
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	log := cb.AuditLog()
+	assert.Equal(t, 3, len(log))
+	assert.Equal(t, "disable", log[1].Action)
+	assert.Equal(t, "enable", log[2].Action)
+}
+
+func TestCountWhileDisabled(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{CountWhileDisabled: true, CounterShards: 4})
+	cb.Disable()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Nil(t, succeed(cb))
+	cb.ReportExternalFailure("pool exhausted")
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{12, 1, 11, 0, 1}, cb.Counts())
+
+	cb.Enable()
+	assert.Equal(t, Counts{}, cb.Counts())
+}
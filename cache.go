@@ -0,0 +1,103 @@
+package gobreaker
+
+import (
+	"container/list"
+	"time"
+)
+
+const defaultCacheSize = 128
+
+// resultCache holds the results of the last successful requests of ExecuteCached by key,
+// the most recently stored first.
+type resultCache struct {
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type cacheEntry struct {
+	key      string
+	value    interface{}
+	storedAt time.Time
+}
+
+// setCache applies Settings.CacheTTL and Settings.CacheSize, dropping the entries that no longer fit.
+func (cb *CircuitBreaker) setCache(ttl time.Duration, size int) {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	cb.cacheTTL = ttl
+	cb.cacheSize = size
+
+	if ttl <= 0 {
+		cb.cache = resultCache{}
+		return
+	}
+	cb.cache.trim(size)
+}
+
+func (c *resultCache) store(key string, value interface{}, now time.Time, size int) {
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+		c.order = list.New()
+	}
+
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		entry.value, entry.storedAt = value, now
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, storedAt: now})
+	c.trim(size)
+}
+
+// load returns the value stored for key within ttl before now.
+func (c *resultCache) load(key string, now time.Time, ttl time.Duration) (interface{}, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*cacheEntry)
+	if now.Sub(entry.storedAt) >= ttl {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *resultCache) trim(size int) {
+	for c.order != nil && c.order.Len() > size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).key)
+	}
+}
+
+// ExecuteCached is like Execute, but keeps the result of the last request for key that returned no error
+// and was counted as a success, for Settings.CacheTTL,
+// and returns it with a nil error instead of the rejection while the CircuitBreaker rejects the requests,
+// so that a caller degrades to a stale value rather than failing.
+// The errors returned from req are returned as is, and so are the rejections if there is no fresh result for key.
+// If Settings.CacheTTL is 0, ExecuteCached is the same as Execute.
+func (cb *CircuitBreaker) ExecuteCached(key string, req func() (interface{}, error)) (interface{}, error) {
+	value, v, err := cb.executeVerdict(req, callOptions{})
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.cacheTTL <= 0 {
+		return value, err
+	}
+
+	now := cb.clock.Now()
+	if err == nil && v == Success {
+		cb.cache.store(key, value, now, cb.cacheSize)
+	} else if IsRejection(err) {
+		if cached, ok := cb.cache.load(key, now, cb.cacheTTL); ok {
+			return cached, nil
+		}
+	}
+	return value, err
+}
@@ -0,0 +1,85 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteCached(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	cb := NewCircuitBreaker(Settings{
+		Timeout:  time.Duration(61) * time.Second,
+		Clock:    clock,
+		CacheTTL: time.Duration(10) * time.Second,
+	})
+	errFail := errors.New("fail")
+
+	value, err := cb.ExecuteCached("a", func() (interface{}, error) { return "a1", nil })
+	assert.Equal(t, "a1", value)
+	assert.Nil(t, err)
+
+	// the errors of the requests are not hidden by the cache
+	_, err = cb.ExecuteCached("a", func() (interface{}, error) { return nil, errFail })
+	assert.Equal(t, errFail, err)
+
+	cb.Trip()
+	value, err = cb.ExecuteCached("a", func() (interface{}, error) { return "a2", nil })
+	assert.Equal(t, "a1", value)
+	assert.Nil(t, err)
+
+	_, err = cb.ExecuteCached("b", func() (interface{}, error) { return "b1", nil })
+	assert.True(t, errors.Is(err, ErrOpenState))
+
+	clock.advance(time.Duration(10) * time.Second)
+	_, err = cb.ExecuteCached("a", func() (interface{}, error) { return "a2", nil })
+	assert.True(t, errors.Is(err, ErrOpenState))
+}
+
+func TestExecuteCachedIsSuccessfulResult(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		CacheTTL: time.Duration(10) * time.Second,
+		IsSuccessfulResult: func(result interface{}, err error) bool {
+			return err == nil && result != "bad"
+		},
+	})
+
+	_, err := cb.ExecuteCached("a", func() (interface{}, error) { return "good", nil })
+	assert.Nil(t, err)
+	// the result counted as a failure doesn't replace the cached one
+	value, err := cb.ExecuteCached("a", func() (interface{}, error) { return "bad", nil })
+	assert.Equal(t, "bad", value)
+	assert.Nil(t, err)
+
+	cb.Trip()
+	value, err = cb.ExecuteCached("a", func() (interface{}, error) { return "good", nil })
+	assert.Equal(t, "good", value)
+	assert.Nil(t, err)
+}
+
+func TestExecuteCachedSize(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		Timeout:   time.Duration(61) * time.Second,
+		CacheTTL:  time.Duration(61) * time.Second,
+		CacheSize: 2,
+	})
+
+	for _, key := range []string{"a", "b", "c"} {
+		k := key
+		_, err := cb.ExecuteCached(k, func() (interface{}, error) { return k, nil })
+		assert.Nil(t, err)
+	}
+
+	cb.Trip()
+	_, err := cb.ExecuteCached("a", func() (interface{}, error) { return nil, nil })
+	assert.True(t, errors.Is(err, ErrOpenState))
+	value, err := cb.ExecuteCached("c", func() (interface{}, error) { return nil, nil })
+	assert.Equal(t, "c", value)
+	assert.Nil(t, err)
+
+	cb.UpdateSettings(Settings{Timeout: time.Duration(61) * time.Second})
+	_, err = cb.ExecuteCached("c", func() (interface{}, error) { return nil, nil })
+	assert.True(t, errors.Is(err, ErrOpenState))
+}
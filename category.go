@@ -0,0 +1,40 @@
+package gobreaker
+
+import "time"
+
+// countCategory counts the failure of a request with err by the category returned from Settings.Categorize.
+func (cb *CircuitBreaker) countCategory(err error, now time.Time) {
+	if cb.categorize == nil || err == nil || cb.ignoresFailure(now) {
+		return
+	}
+
+	category := cb.categorize(err)
+	if category == "" {
+		return
+	}
+	if cb.categories == nil {
+		cb.categories = make(map[string]uint32)
+	}
+	cb.categories[category]++
+}
+
+// CountsByCategory returns the numbers of the failures in the current generation by the category
+// returned from Settings.Categorize. It is empty if Settings.Categorize is nil.
+func (cb *CircuitBreaker) CountsByCategory() map[string]uint32 {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+	failures := make(map[string]uint32, len(cb.categories))
+	for category, n := range cb.categories {
+		failures[category] = n
+	}
+	return failures
+}
+
+// CountsByCategory returns the numbers of the failures in the current generation by category.
+// See CircuitBreaker.CountsByCategory.
+func (tscb *TwoStepCircuitBreaker) CountsByCategory() map[string]uint32 {
+	return tscb.cb.CountsByCategory()
+}
@@ -0,0 +1,46 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountsByCategory(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		Categorize: func(err error) string {
+			var se statusError
+			if !errors.As(err, &se) {
+				return ""
+			}
+			if se >= 500 {
+				return "5xx"
+			}
+			return "4xx"
+		},
+		ReadyToTripByCategory: func(counts Counts, failures map[string]uint32) bool {
+			return failures["5xx"] >= 3
+		},
+	})
+	execute := func(err error) {
+		_, e := cb.Execute(func() (interface{}, error) { return nil, err })
+		assert.Equal(t, err, e)
+	}
+
+	for i := 0; i < 10; i++ {
+		execute(statusError(429))
+	}
+	execute(errors.New("other"))
+	execute(statusError(503))
+	execute(statusError(502))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, map[string]uint32{"4xx": 10, "5xx": 2}, cb.CountsByCategory())
+
+	execute(statusError(503))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, map[string]uint32{}, cb.CountsByCategory())
+
+	err := Settings{ReadyToTripByCategory: func(Counts, map[string]uint32) bool { return false }}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
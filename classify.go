@@ -0,0 +1,251 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Verdict is a type that represents how a Classifier classifies the outcome of a request.
+type Verdict int
+
+// These constants are verdicts of Classifier.
+//
+// Undecided passes the error to the next Classifier of Chain.
+// Success and Failure count the request as a success and a failure.
+// Ignored doesn't count the request at all, e.g. for a request canceled by the caller.
+const (
+	Undecided Verdict = iota
+	Success
+	Failure
+	Ignored
+)
+
+// String implements stringer interface.
+func (v Verdict) String() string {
+	switch v {
+	case Undecided:
+		return "undecided"
+	case Success:
+		return "success"
+	case Failure:
+		return "failure"
+	case Ignored:
+		return "ignored"
+	default:
+		return fmt.Sprintf("unknown verdict: %d", v)
+	}
+}
+
+// Classifier classifies the error returned from a request.
+type Classifier func(err error) Verdict
+
+// Chain returns a Classifier that asks the given Classifiers in order
+// and returns the first verdict other than Undecided.
+// If all of them are undecided, the request succeeds if err is nil and fails otherwise.
+func Chain(classifiers ...Classifier) Classifier {
+	return func(err error) Verdict {
+		for _, c := range classifiers {
+			if v := c(err); v != Undecided {
+				return v
+			}
+		}
+		if err == nil {
+			return Success
+		}
+		return Failure
+	}
+}
+
+// errorsClassifier returns a Classifier that gives v to the errors matching any of errs with errors.Is.
+func errorsClassifier(v Verdict, errs []error) Classifier {
+	return func(err error) Verdict {
+		for _, target := range errs {
+			if errors.Is(err, target) {
+				return v
+			}
+		}
+		return Undecided
+	}
+}
+
+// IgnoreErrors returns a Classifier that ignores the errors matching any of errs with errors.Is,
+// e.g. context.Canceled.
+func IgnoreErrors(errs ...error) Classifier {
+	return errorsClassifier(Ignored, errs)
+}
+
+// SucceedOn returns a Classifier that counts the errors matching any of errs with errors.Is as successes,
+// e.g. the errors of the application such as a not-found error.
+func SucceedOn(errs ...error) Classifier {
+	return errorsClassifier(Success, errs)
+}
+
+// FailOn returns a Classifier that counts the errors matching any of errs with errors.Is as failures.
+func FailOn(errs ...error) Classifier {
+	return errorsClassifier(Failure, errs)
+}
+
+// Matcher reports whether the error returned from a request matches a condition.
+// SuccessIf, FailureIf and IgnoreIf build a Classifier from Matchers.
+type Matcher func(err error) bool
+
+// ErrorIs returns a Matcher that matches the errors matching target with errors.Is.
+func ErrorIs(target error) Matcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ErrorAs returns a Matcher that matches the errors with an error assignable to target in their chain,
+// with the same semantics as errors.As, e.g. new(*net.OpError) or new(net.Error).
+// target is only used for its type and is never written.
+// ErrorAs panics if target is not a non-nil pointer to an interface or to a type implementing error.
+func ErrorAs(target interface{}) Matcher {
+	typ := reflect.TypeOf(target)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		panic("gobreaker: ErrorAs target must be a non-nil pointer")
+	}
+	elem := typ.Elem()
+	if elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		panic("gobreaker: ErrorAs target must be a pointer to an interface or to a type implementing error")
+	}
+
+	return func(err error) bool {
+		return errors.As(err, reflect.New(elem).Interface())
+	}
+}
+
+// ErrorTimeout returns a Matcher that matches the errors with a timeout in their chain,
+// i.e. an error whose Timeout method returns true, such as a net.Error or context.DeadlineExceeded.
+func ErrorTimeout() Matcher {
+	return func(err error) bool {
+		var t interface{ Timeout() bool }
+		return errors.As(err, &t) && t.Timeout()
+	}
+}
+
+// matchClassifier returns a Classifier that gives v to the errors matching any of matchers.
+func matchClassifier(v Verdict, matchers []Matcher) Classifier {
+	return func(err error) Verdict {
+		if err == nil {
+			return Undecided
+		}
+		for _, m := range matchers {
+			if m(err) {
+				return v
+			}
+		}
+		return Undecided
+	}
+}
+
+// SuccessIf returns a Classifier that counts the errors matching any of matchers as successes.
+// The Classifiers built by SuccessIf, FailureIf and IgnoreIf are composed with Chain, e.g.
+//
+//	gobreaker.Chain(
+//		gobreaker.IgnoreIf(gobreaker.ErrorIs(context.Canceled)),
+//		gobreaker.FailureIf(gobreaker.ErrorTimeout()),
+//		gobreaker.SuccessIf(gobreaker.ErrorAs(new(*ValidationError))),
+//	)
+func SuccessIf(matchers ...Matcher) Classifier {
+	return matchClassifier(Success, matchers)
+}
+
+// FailureIf returns a Classifier that counts the errors matching any of matchers as failures.
+func FailureIf(matchers ...Matcher) Classifier {
+	return matchClassifier(Failure, matchers)
+}
+
+// IgnoreIf returns a Classifier that ignores the errors matching any of matchers.
+func IgnoreIf(matchers ...Matcher) Classifier {
+	return matchClassifier(Ignored, matchers)
+}
+
+// ClassifyFunc returns a Classifier from a function like Settings.IsSuccessful.
+func ClassifyFunc(isSuccessful func(err error) bool) Classifier {
+	return func(err error) Verdict {
+		if isSuccessful(err) {
+			return Success
+		}
+		return Failure
+	}
+}
+
+// StatusCoder is implemented by the errors carrying a status code, such as an HTTP status.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// ClassifyStatus returns a Classifier that classifies the errors carrying a status code with classify.
+// The errors without a StatusCoder in their chain are undecided.
+//
+// For example, to fail only on the server errors of HTTP:
+//
+//	gobreaker.ClassifyStatus(func(code int) gobreaker.Verdict {
+//		if code >= 500 {
+//			return gobreaker.Failure
+//		}
+//		return gobreaker.Success
+//	})
+func ClassifyStatus(classify func(code int) Verdict) Classifier {
+	return func(err error) Verdict {
+		var sc StatusCoder
+		if errors.As(err, &sc) {
+			return classify(sc.StatusCode())
+		}
+		return Undecided
+	}
+}
+
+// ClassifyJoined returns a Classifier that classifies each of the errors joined in err, e.g. by errors.Join
+// or any error with an Unwrap() []error method in its chain, with c, so that a failure among the joined errors
+// isn't hidden by another error matched first, e.g. by errors.As.
+// The verdict is Failure if any of the joined errors fails, and otherwise Success, Ignored or Undecided in this order.
+// The errors without joined errors are classified by c as they are.
+func ClassifyJoined(c Classifier) Classifier {
+	var classify Classifier
+	classify = func(err error) Verdict {
+		errs := joinedErrors(err)
+		if errs == nil {
+			return c(err)
+		}
+
+		verdict := Undecided
+		for _, e := range errs {
+			if v := classify(e); joinedRank(v) > joinedRank(verdict) {
+				verdict = v
+			}
+		}
+		return verdict
+	}
+	return classify
+}
+
+// joinedRank ranks the verdicts of the joined errors for ClassifyJoined.
+func joinedRank(v Verdict) int {
+	switch v {
+	case Failure:
+		return 3
+	case Success:
+		return 2
+	case Ignored:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// joinedErrors returns the errors joined in the first error with an Unwrap() []error method in the chain of err,
+// or nil if there is none.
+func joinedErrors(err error) []error {
+	for err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return joined.Unwrap()
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusError int
+
+func (e statusError) Error() string   { return "status error" }
+func (e statusError) StatusCode() int { return int(e) }
+
+func TestChain(t *testing.T) {
+	errFatal := errors.New("fatal")
+	c := Chain(
+		IgnoreErrors(context.Canceled),
+		ClassifyStatus(func(code int) Verdict {
+			if code >= 500 {
+				return Failure
+			}
+			return Success
+		}),
+		SucceedOn(errNotFound),
+		FailOn(errFatal),
+		ClassifyFunc(func(err error) bool { return err == nil }),
+	)
+
+	assert.Equal(t, Success, c(nil))
+	assert.Equal(t, Ignored, c(context.Canceled))
+	assert.Equal(t, Success, c(statusError(404)))
+	assert.Equal(t, Failure, c(statusError(503)))
+	assert.Equal(t, Success, c(errNotFound))
+	assert.Equal(t, Failure, c(errFatal))
+	assert.Equal(t, Failure, c(errors.New("other")))
+
+	assert.Equal(t, Success, Chain()(nil))
+	assert.Equal(t, Failure, Chain()(errFatal))
+	assert.Equal(t, "ignored", Ignored.String())
+	assert.Equal(t, "unknown verdict: 7", Verdict(7).String())
+}
+
+type timeoutError struct{ timeout bool }
+
+func (e *timeoutError) Error() string { return "timeout error" }
+func (e *timeoutError) Timeout() bool { return e.timeout }
+
+func TestMatchers(t *testing.T) {
+	c := Chain(
+		IgnoreIf(ErrorIs(context.Canceled)),
+		FailureIf(ErrorTimeout()),
+		SuccessIf(ErrorAs(new(statusError))),
+	)
+
+	assert.Equal(t, Success, c(nil))
+	assert.Equal(t, Ignored, c(fmt.Errorf("wrapped: %w", context.Canceled)))
+	assert.Equal(t, Failure, c(context.DeadlineExceeded))
+	assert.Equal(t, Failure, c(fmt.Errorf("wrapped: %w", &timeoutError{timeout: true})))
+	assert.Equal(t, Failure, c(&timeoutError{timeout: false}))
+	assert.Equal(t, Success, c(fmt.Errorf("wrapped: %w", statusError(404))))
+	assert.Equal(t, Failure, c(errors.New("other")))
+
+	assert.True(t, ErrorAs(new(StatusCoder))(statusError(503)))
+	assert.Equal(t, Undecided, IgnoreIf(func(error) bool { return true })(nil))
+	assert.Panics(t, func() { ErrorAs(nil) })
+	assert.Panics(t, func() { ErrorAs(statusError(0)) })
+	assert.Panics(t, func() { ErrorAs(new(int)) })
+}
+
+// joinError is a joined error like the one of errors.Join.
+type joinError []error
+
+func (e joinError) Error() string   { return "joined" }
+func (e joinError) Unwrap() []error { return e }
+
+func TestClassifyJoined(t *testing.T) {
+	status := ClassifyStatus(func(code int) Verdict {
+		if code >= 500 {
+			return Failure
+		}
+		return Success
+	})
+	c := ClassifyJoined(Chain(IgnoreIf(ErrorIs(context.Canceled)), status))
+
+	joined := joinError{statusError(404), statusError(503)}
+	assert.Equal(t, Success, Chain(status)(joined))
+	assert.Equal(t, Failure, c(joined))
+	assert.Equal(t, Failure, c(fmt.Errorf("batch: %w", joinError{statusError(404), joinError{context.Canceled, statusError(500)}})))
+	assert.Equal(t, Success, c(joinError{context.Canceled, statusError(404)}))
+	assert.Equal(t, Ignored, c(joinError{context.Canceled, context.Canceled}))
+	assert.Equal(t, Undecided, ClassifyJoined(status)(joinError{errors.New("other")}))
+	assert.Equal(t, Failure, c(statusError(502)))
+	assert.Equal(t, Success, c(nil))
+}
+
+func TestClassifier(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		Classifier: Chain(IgnoreErrors(context.Canceled), SucceedOn(errNotFound)),
+	})
+	execute := func(err error) {
+		_, e := cb.Execute(func() (interface{}, error) { return nil, err })
+		assert.Equal(t, err, e)
+	}
+
+	execute(context.Canceled)
+	assert.Equal(t, Counts{}, cb.Counts())
+	execute(errNotFound)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+	execute(errors.New("fail"))
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, cb.Counts())
+}
+
+func TestHalfOpenIsSuccessful(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	slow := func(err error) error {
+		clock.advance(time.Duration(2) * time.Second)
+		return err
+	}
+	cb := NewCircuitBreaker(Settings{
+		Clock:       clock,
+		MaxRequests: 2,
+		HalfOpenIsSuccessful: func(d time.Duration, err error) bool {
+			return err == nil && d < time.Second
+		},
+	})
+
+	_, err := cb.Execute(func() (interface{}, error) { return nil, slow(nil) })
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	cb.Trip()
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	_, err = cb.Execute(func() (interface{}, error) { return nil, slow(nil) })
+	assert.Nil(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+}
@@ -0,0 +1,63 @@
+package gobreaker
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time of CircuitBreaker.
+// A fake Clock lets tests and simulations drive the CircuitBreaker through virtual time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f after d, like time.AfterFunc.
+	// f must not be called by the goroutine calling AfterFunc.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the timer returned by Clock.AfterFunc.
+type Timer interface {
+	// Stop prevents the Timer from firing, like time.Timer.Stop.
+	Stop() bool
+}
+
+// systemClock is the Clock of the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// sleep waits for d on the Clock of the CircuitBreaker.
+func (cb *CircuitBreaker) sleep(d time.Duration) {
+	done := make(chan struct{})
+	cb.clock.AfterFunc(d, func() { close(done) })
+	<-done
+}
+
+// sleepContext is like sleep but returns the error of ctx if ctx is done first.
+func (cb *CircuitBreaker) sleepContext(ctx context.Context, d time.Duration) error {
+	return sleepContext(ctx, cb.clock, d)
+}
+
+// sleepContext waits for d on clock, or returns the error of ctx if ctx is done first.
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	wake := make(chan struct{})
+	timer := clock.AfterFunc(d, func() { close(wake) })
+	defer timer.Stop()
+
+	select {
+	case <-wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package gobreaker
+
+// Close stops the background goroutines of the CircuitBreaker, such as the ones of HealthCheck,
+// HealthReporter and the attached Resources, and the timer of DowntimeBudget,
+// waits for them to return, and closes the channels returned by Subscribe,
+// e.g. to tear down the CircuitBreaker at the end of a test without leaking goroutines.
+// The notifications already queued for HealthReporter and the Resources are delivered before Close returns.
+// The CircuitBreaker still works after Close without HealthCheck, HealthReporter, the Resources and DowntimeBudget,
+// i.e. the open state lasts for Timeout as if HealthCheck were nil.
+// Close always returns nil and can be called more than once.
+func (cb *CircuitBreaker) Close() error {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	cb.closed = true
+	cb.cancel()
+	for _, sub := range cb.subscribers {
+		close(sub)
+	}
+	cb.subscribers = nil
+	if cb.healthReporter != nil {
+		close(cb.healthReporter.queue)
+		cb.healthReporter = nil
+	}
+	for _, hr := range cb.resources {
+		close(hr.queue)
+	}
+	cb.resources = nil
+	if cb.downtime.timer != nil {
+		cb.downtime.timer.Stop()
+		cb.downtime.timer = nil
+	}
+	cb.mutex.Unlock()
+
+	cb.background.Wait()
+	return nil
+}
+
+// Close stops the background goroutines of the TwoStepCircuitBreaker.
+// See CircuitBreaker.Close.
+func (tscb *TwoStepCircuitBreaker) Close() error {
+	return tscb.cb.Close()
+}
@@ -0,0 +1,96 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unhealthyBreaker(ctx context.Context, checks *int32) *CircuitBreaker {
+	cb := NewCircuitBreakerContext(ctx, Settings{
+		HealthCheck: func(ctx context.Context) error {
+			atomic.AddInt32(checks, 1)
+			return errors.New("unhealthy")
+		},
+		HealthCheckInterval: time.Duration(10) * time.Millisecond,
+	})
+	cb.Trip()
+	return cb
+}
+
+func TestClose(t *testing.T) {
+	var checks int32
+	cb := unhealthyBreaker(context.Background(), &checks)
+	ch := cb.Subscribe()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.True(t, atomic.LoadInt32(&checks) > 0)
+
+	assert.Nil(t, cb.Close())
+	n := atomic.LoadInt32(&checks)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&checks))
+
+	_, ok := <-ch
+	assert.False(t, ok)
+	_, ok = <-cb.Subscribe()
+	assert.False(t, ok)
+
+	// the open state lasts for Timeout without HealthCheck
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, cb.Close())
+}
+
+func TestCloseGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	var notified int32
+	cb := NewCircuitBreaker(Settings{
+		HealthReporter: reporterFunc(func(up bool, ev StateChangeEvent) {
+			atomic.AddInt32(&notified, 1)
+		}),
+		DowntimeBudget: time.Hour,
+	})
+	cb.Attach(ResourceFuncs{Open: func(ev StateChangeEvent) { atomic.AddInt32(&notified, 1) }})
+	cb.Trip()
+	assert.NotNil(t, cb.downtime.timer)
+
+	assert.Nil(t, cb.Close())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&notified))
+	assert.Nil(t, cb.downtime.timer)
+
+	// the goroutines may linger for a moment after they are done
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, before, runtime.NumGoroutine())
+
+	// nothing is restarted after Close
+	cb.UpdateSettings(Settings{HealthReporter: reporterFunc(func(up bool, ev StateChangeEvent) {})})
+	cb.Attach(ResourceFuncs{})()
+	assert.Equal(t, before, runtime.NumGoroutine())
+	assert.Nil(t, cb.Close())
+}
+
+func TestNewCircuitBreakerContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var checks int32
+	cb := unhealthyBreaker(ctx, &checks)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+
+	cancel()
+	time.Sleep(time.Duration(20) * time.Millisecond)
+	n := atomic.LoadInt32(&checks)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&checks))
+	assert.Nil(t, cb.Close())
+
+	tscb := NewTwoStepCircuitBreakerContext(context.Background(), Settings{})
+	assert.Nil(t, tscb.Close())
+}
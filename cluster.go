@@ -0,0 +1,144 @@
+package gobreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ClusterEvent is the state of a CircuitBreaker on an instance of a service, exchanged by a Coordinator.
+// Preemptive is true if the CircuitBreaker was tripped by its Cluster rather than by its own requests,
+// so that the pre-emptively open instances don't keep each other open.
+// A ClusterEvent with the empty Name means the instance has left the cluster.
+type ClusterEvent struct {
+	Instance   string
+	Name       string
+	State      State
+	Preemptive bool
+	Time       time.Time
+}
+
+// Coordinator exchanges ClusterEvents among the instances of a service, e.g. by gossip.
+// The package gobreakergossip provides a Coordinator based on memberlist.
+//
+// Broadcast sends ev to the other instances.
+// Watch calls receive with every ClusterEvent from the other instances until ctx is done or the watch fails.
+type Coordinator interface {
+	Broadcast(ev ClusterEvent) error
+	Watch(ctx context.Context, receive func(ev ClusterEvent)) error
+}
+
+// Cluster shares the state of a CircuitBreaker with the CircuitBreakers of the same name on the other instances
+// of a service through a Coordinator, and trips the CircuitBreaker pre-emptively
+// when a quorum of the other instances have tripped for the dependency.
+// The CircuitBreaker stays open while the quorum of the other instances are open,
+// and then recovers by its own probes.
+type Cluster struct {
+	cb       *CircuitBreaker
+	instance string
+	quorum   int
+	coord    Coordinator
+
+	mutex      sync.Mutex
+	peers      map[string]ClusterEvent
+	preemptive bool
+}
+
+// NewCluster returns a new Cluster for cb on the instance with the given unique ID.
+// If quorum is less than or equal to 0, quorum is set to 1.
+func NewCluster(cb *CircuitBreaker, instance string, quorum int, coord Coordinator) *Cluster {
+	if quorum <= 0 {
+		quorum = 1
+	}
+
+	return &Cluster{
+		cb:       cb,
+		instance: instance,
+		quorum:   quorum,
+		coord:    coord,
+		peers:    make(map[string]ClusterEvent),
+	}
+}
+
+// Run broadcasts the state changes of the CircuitBreaker and follows the ClusterEvents from the other instances.
+// Run blocks until ctx is done or Watch of the Coordinator fails, and returns the error of Watch.
+func (c *Cluster) Run(ctx context.Context) error {
+	ch := c.cb.Subscribe()
+	defer c.cb.Unsubscribe(ch)
+
+	c.broadcast(c.cb.State())
+	go func() {
+		for ev := range ch {
+			if ev.Type == EventStateChange {
+				c.mutex.Lock()
+				if ev.To != StateOpen {
+					c.preemptive = false
+				}
+				c.mutex.Unlock()
+
+				c.broadcast(ev.To)
+				c.evaluate()
+			}
+		}
+	}()
+
+	return c.coord.Watch(ctx, c.receive)
+}
+
+func (c *Cluster) broadcast(state State) {
+	c.mutex.Lock()
+	preemptive := c.preemptive
+	c.mutex.Unlock()
+
+	// A lost broadcast is corrected by the next one, so the error is not fatal.
+	_ = c.coord.Broadcast(ClusterEvent{
+		Instance:   c.instance,
+		Name:       c.cb.Name(),
+		State:      state,
+		Preemptive: preemptive && state == StateOpen,
+		Time:       c.cb.clock.Now(),
+	})
+}
+
+func (c *Cluster) receive(ev ClusterEvent) {
+	if ev.Instance == c.instance {
+		return
+	}
+
+	c.mutex.Lock()
+	switch ev.Name {
+	case "":
+		delete(c.peers, ev.Instance)
+	case c.cb.Name():
+		c.peers[ev.Instance] = ev
+	}
+	c.mutex.Unlock()
+
+	c.evaluate()
+}
+
+// evaluate trips the CircuitBreaker if the quorum of the other instances are open.
+func (c *Cluster) evaluate() {
+	if c.Open() < c.quorum || c.cb.State() == StateOpen {
+		return
+	}
+
+	c.mutex.Lock()
+	c.preemptive = true
+	c.mutex.Unlock()
+	c.cb.Trip()
+}
+
+// Open returns the number of the other instances whose CircuitBreakers are open by their own requests.
+func (c *Cluster) Open() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var n int
+	for _, ev := range c.peers {
+		if ev.State == StateOpen && !ev.Preemptive {
+			n++
+		}
+	}
+	return n
+}
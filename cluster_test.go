@@ -0,0 +1,83 @@
+package gobreaker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hub is an in-memory Coordinator connecting the instances of a test.
+type hub struct {
+	mutex     sync.Mutex
+	receivers map[string]func(ev ClusterEvent)
+}
+
+type hubMember struct {
+	hub      *hub
+	instance string
+}
+
+func (m hubMember) Broadcast(ev ClusterEvent) error {
+	m.hub.mutex.Lock()
+	defer m.hub.mutex.Unlock()
+
+	for instance, receive := range m.hub.receivers {
+		if instance != m.instance {
+			receive(ev)
+		}
+	}
+	return nil
+}
+
+func (m hubMember) Watch(ctx context.Context, receive func(ev ClusterEvent)) error {
+	m.hub.mutex.Lock()
+	m.hub.receivers[m.instance] = func(ev ClusterEvent) { go receive(ev) }
+	m.hub.mutex.Unlock()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCluster(t *testing.T) {
+	h := &hub{receivers: make(map[string]func(ev ClusterEvent))}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cbs := make([]*CircuitBreaker, 4)
+	clusters := make([]*Cluster, 4)
+	for i, instance := range []string{"a", "b", "c", "d"} {
+		cbs[i] = NewCircuitBreaker(Settings{Name: "db"})
+		clusters[i] = NewCluster(cbs[i], instance, 2, hubMember{hub: h, instance: instance})
+		go clusters[i].Run(ctx)
+	}
+	time.Sleep(time.Duration(50) * time.Millisecond)
+
+	cbs[0].Trip()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, 1, clusters[2].Open())
+	assert.Equal(t, StateClosed, cbs[2].State())
+
+	// c and d open pre-emptively when a and b have tripped
+	cbs[1].Trip()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, StateOpen, cbs[2].State())
+	assert.Equal(t, StateOpen, cbs[3].State())
+	assert.Equal(t, 1, clusters[0].Open()) // c and d are not counted
+
+	// c and d are free to recover when a and b recover
+	cbs[0].Reset()
+	cbs[1].Reset()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, 0, clusters[2].Open())
+	assert.Equal(t, 0, clusters[0].Open())
+
+	// an instance leaving the cluster is forgotten
+	cbs[0].Trip()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, 1, clusters[3].Open())
+	clusters[3].receive(ClusterEvent{Instance: "a"})
+	assert.Equal(t, 0, clusters[3].Open())
+}
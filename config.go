@@ -0,0 +1,209 @@
+package gobreaker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration that is marshaled as a string such as "1m30s",
+// so that durations in configuration files are readable.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// TripPolicy is a named ReadyToTrip policy in Config.
+//
+// Type is the name of the policy:
+// "consecutiveFailures" trips when the number of consecutive failures reaches Failures, and
+// "failureRate" trips when the failure ratio reaches Rate after MinRequests requests.
+type TripPolicy struct {
+	Type        string  `json:"type" yaml:"type"`
+	Failures    uint32  `json:"failures,omitempty" yaml:"failures,omitempty"`
+	Rate        float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+	MinRequests uint32  `json:"minRequests,omitempty" yaml:"minRequests,omitempty"`
+}
+
+// ReadyToTrip returns the ReadyToTrip function of the policy.
+func (p TripPolicy) ReadyToTrip() (func(counts Counts) bool, error) {
+	switch p.Type {
+	case "consecutiveFailures":
+		if p.Failures == 0 {
+			return nil, invalidSettings("consecutiveFailures policy requires failures")
+		}
+		failures := p.Failures
+		return func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= failures
+		}, nil
+	case "failureRate":
+		if p.Rate <= 0 || p.Rate > 1 {
+			return nil, invalidSettings("failureRate policy requires rate in (0, 1]")
+		}
+		return RateHysteresis{TripFailureRatio: p.Rate, MinRequests: p.MinRequests}.ReadyToTrip, nil
+	default:
+		return nil, invalidSettings("unknown trip policy %q", p.Type)
+	}
+}
+
+// Config is the serializable subset of Settings, e.g. for configuration files.
+// Config has the struct tags for JSON and YAML, so it can be decoded by a YAML library as well.
+// The hooks of Settings, which are Go functions, are expressed with named policies such as TripPolicy.
+//
+// Mode is "standard", "adaptive" or "high-throughput", and IntervalPolicy is "never" or "default".
+// The empty strings mean the zero values.
+type Config struct {
+	Name                    string      `json:"name,omitempty" yaml:"name,omitempty"`
+	MaxRequests             uint32      `json:"maxRequests,omitempty" yaml:"maxRequests,omitempty"`
+	Interval                Duration    `json:"interval,omitempty" yaml:"interval,omitempty"`
+	IntervalPolicy          string      `json:"intervalPolicy,omitempty" yaml:"intervalPolicy,omitempty"`
+	Timeout                 Duration    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ReadyToTrip             *TripPolicy `json:"readyToTrip,omitempty" yaml:"readyToTrip,omitempty"`
+	DisablePanicRecovery    bool        `json:"disablePanicRecovery,omitempty" yaml:"disablePanicRecovery,omitempty"`
+	DowntimeBudget          Duration    `json:"downtimeBudget,omitempty" yaml:"downtimeBudget,omitempty"`
+	PublishExpvar           bool        `json:"publishExpvar,omitempty" yaml:"publishExpvar,omitempty"`
+	IdempotentProbes        uint32      `json:"idempotentProbes,omitempty" yaml:"idempotentProbes,omitempty"`
+	CounterShards           int         `json:"counterShards,omitempty" yaml:"counterShards,omitempty"`
+	AuditLogSize            int         `json:"auditLogSize,omitempty" yaml:"auditLogSize,omitempty"`
+	HistorySize             int         `json:"historySize,omitempty" yaml:"historySize,omitempty"`
+	Mode                    string      `json:"mode,omitempty" yaml:"mode,omitempty"`
+	AdaptiveK               float64     `json:"adaptiveK,omitempty" yaml:"adaptiveK,omitempty"`
+	AdaptiveWindow          Duration    `json:"adaptiveWindow,omitempty" yaml:"adaptiveWindow,omitempty"`
+	HalfOpenProbeInterval   Duration    `json:"halfOpenProbeInterval,omitempty" yaml:"halfOpenProbeInterval,omitempty"`
+	CallTimeout             Duration    `json:"callTimeout,omitempty" yaml:"callTimeout,omitempty"`
+	CountWhileDisabled      bool        `json:"countWhileDisabled,omitempty" yaml:"countWhileDisabled,omitempty"`
+	TripDumpSize            int         `json:"tripDumpSize,omitempty" yaml:"tripDumpSize,omitempty"`
+	CountResetThreshold     uint32      `json:"countResetThreshold,omitempty" yaml:"countResetThreshold,omitempty"`
+	MinimumRequestThreshold uint32      `json:"minimumRequestThreshold,omitempty" yaml:"minimumRequestThreshold,omitempty"`
+	MinRemainingBudget      Duration    `json:"minRemainingBudget,omitempty" yaml:"minRemainingBudget,omitempty"`
+	FailOpenRatio           float64     `json:"failOpenRatio,omitempty" yaml:"failOpenRatio,omitempty"`
+	HalfOpenMaxConcurrent   uint32      `json:"halfOpenMaxConcurrent,omitempty" yaml:"halfOpenMaxConcurrent,omitempty"`
+	HalfOpenMaxTotal        uint32      `json:"halfOpenMaxTotal,omitempty" yaml:"halfOpenMaxTotal,omitempty"`
+	ShadowMode              bool        `json:"shadowMode,omitempty" yaml:"shadowMode,omitempty"`
+	HalfOpenSuccessRatio    float64     `json:"halfOpenSuccessRatio,omitempty" yaml:"halfOpenSuccessRatio,omitempty"`
+	CountRejections         bool        `json:"countRejections,omitempty" yaml:"countRejections,omitempty"`
+	TimeoutJitter           Duration    `json:"timeoutJitter,omitempty" yaml:"timeoutJitter,omitempty"`
+	WrapErrors              bool        `json:"wrapErrors,omitempty" yaml:"wrapErrors,omitempty"`
+	DecayHalfLife           Duration    `json:"decayHalfLife,omitempty" yaml:"decayHalfLife,omitempty"`
+	HalfOpenDuration        Duration    `json:"halfOpenDuration,omitempty" yaml:"halfOpenDuration,omitempty"`
+	CacheTTL                Duration    `json:"cacheTTL,omitempty" yaml:"cacheTTL,omitempty"`
+	CacheSize               int         `json:"cacheSize,omitempty" yaml:"cacheSize,omitempty"`
+	BurstWindow             Duration    `json:"burstWindow,omitempty" yaml:"burstWindow,omitempty"`
+	BurstThreshold          float64     `json:"burstThreshold,omitempty" yaml:"burstThreshold,omitempty"`
+	InjectFailureRate       float64     `json:"injectFailureRate,omitempty" yaml:"injectFailureRate,omitempty"`
+	InjectLatency           Duration    `json:"injectLatency,omitempty" yaml:"injectLatency,omitempty"`
+	EnforceCallTimeout      bool        `json:"enforceCallTimeout,omitempty" yaml:"enforceCallTimeout,omitempty"`
+	RecentErrorsSize        int         `json:"recentErrorsSize,omitempty" yaml:"recentErrorsSize,omitempty"`
+	HalfOpenLatencyBudget   Duration    `json:"halfOpenLatencyBudget,omitempty" yaml:"halfOpenLatencyBudget,omitempty"`
+}
+
+// Settings returns the Settings configured by c.
+// Settings returns an error wrapping ErrInvalidSettings if c is invalid or the Settings don't pass Validate.
+func (c Config) Settings() (Settings, error) {
+	return c.ApplyTo(Settings{})
+}
+
+// ApplyTo returns base with the fields configured by c, e.g. to configure the Settings with hooks from a file.
+// The fields of base not in Config are kept, and so is ReadyToTrip if c.ReadyToTrip is nil.
+// ApplyTo returns an error wrapping ErrInvalidSettings if c is invalid or the Settings don't pass Validate.
+func (c Config) ApplyTo(base Settings) (Settings, error) {
+	st := base
+	st.Name = c.Name
+	st.MaxRequests = c.MaxRequests
+	st.Interval = time.Duration(c.Interval)
+	st.Timeout = time.Duration(c.Timeout)
+	st.DisablePanicRecovery = c.DisablePanicRecovery
+	st.DowntimeBudget = time.Duration(c.DowntimeBudget)
+	st.PublishExpvar = c.PublishExpvar
+	st.IdempotentProbes = c.IdempotentProbes
+	st.CounterShards = c.CounterShards
+	st.AuditLogSize = c.AuditLogSize
+	st.HistorySize = c.HistorySize
+	st.AdaptiveK = c.AdaptiveK
+	st.AdaptiveWindow = time.Duration(c.AdaptiveWindow)
+	st.HalfOpenProbeInterval = time.Duration(c.HalfOpenProbeInterval)
+	st.CallTimeout = time.Duration(c.CallTimeout)
+	st.CountWhileDisabled = c.CountWhileDisabled
+	st.TripDumpSize = c.TripDumpSize
+	st.CountResetThreshold = c.CountResetThreshold
+	st.MinimumRequestThreshold = c.MinimumRequestThreshold
+	st.MinRemainingBudget = time.Duration(c.MinRemainingBudget)
+	st.FailOpenRatio = c.FailOpenRatio
+	st.HalfOpenMaxConcurrent = c.HalfOpenMaxConcurrent
+	st.HalfOpenMaxTotal = c.HalfOpenMaxTotal
+	st.ShadowMode = c.ShadowMode
+	st.HalfOpenSuccessRatio = c.HalfOpenSuccessRatio
+	st.CountRejections = c.CountRejections
+	st.TimeoutJitter = time.Duration(c.TimeoutJitter)
+	st.WrapErrors = c.WrapErrors
+	st.DecayHalfLife = time.Duration(c.DecayHalfLife)
+	st.HalfOpenDuration = time.Duration(c.HalfOpenDuration)
+	st.CacheTTL = time.Duration(c.CacheTTL)
+	st.CacheSize = c.CacheSize
+	st.BurstWindow = time.Duration(c.BurstWindow)
+	st.BurstThreshold = c.BurstThreshold
+	st.InjectFailureRate = c.InjectFailureRate
+	st.InjectLatency = time.Duration(c.InjectLatency)
+	st.EnforceCallTimeout = c.EnforceCallTimeout
+	st.RecentErrorsSize = c.RecentErrorsSize
+	st.HalfOpenLatencyBudget = time.Duration(c.HalfOpenLatencyBudget)
+
+	switch c.Mode {
+	case "", ModeStandard.String():
+		st.Mode = ModeStandard
+	case ModeAdaptive.String():
+		st.Mode = ModeAdaptive
+	case ModeHighThroughput.String():
+		st.Mode = ModeHighThroughput
+	default:
+		return Settings{}, invalidSettings("unknown mode %q", c.Mode)
+	}
+
+	switch c.IntervalPolicy {
+	case "", IntervalNever.String():
+		st.IntervalPolicy = IntervalNever
+	case IntervalDefault.String():
+		st.IntervalPolicy = IntervalDefault
+	default:
+		return Settings{}, invalidSettings("unknown interval policy %q", c.IntervalPolicy)
+	}
+
+	if c.ReadyToTrip != nil {
+		readyToTrip, err := c.ReadyToTrip.ReadyToTrip()
+		if err != nil {
+			return Settings{}, err
+		}
+		st.ReadyToTrip = readyToTrip
+	}
+
+	if err := st.Validate(); err != nil {
+		return Settings{}, err
+	}
+	return st, nil
+}
+
+// SettingsFromJSON returns the Settings configured by the JSON representation of Config.
+// The unknown fields are rejected, so that a misspelled field doesn't silently fall back to the default.
+func SettingsFromJSON(data []byte) (Settings, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return Settings{}, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+	}
+	return c.Settings()
+}
@@ -0,0 +1,62 @@
+package gobreaker
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsFromJSON(t *testing.T) {
+	st, err := SettingsFromJSON([]byte(`{
+		"name": "db",
+		"maxRequests": 3,
+		"interval": "1m",
+		"timeout": "30s",
+		"readyToTrip": {"type": "failureRate", "rate": 0.5, "minRequests": 4},
+		"mode": "standard",
+		"intervalPolicy": "default"
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "db", st.Name)
+	assert.Equal(t, uint32(3), st.MaxRequests)
+	assert.Equal(t, time.Minute, st.Interval)
+	assert.Equal(t, time.Duration(30)*time.Second, st.Timeout)
+	assert.Equal(t, IntervalDefault, st.IntervalPolicy)
+	assert.False(t, st.ReadyToTrip(Counts{Requests: 3, TotalFailures: 3}))
+	assert.True(t, st.ReadyToTrip(Counts{Requests: 4, TotalFailures: 2}))
+
+	st, err = SettingsFromJSON([]byte(`{"readyToTrip": {"type": "consecutiveFailures", "failures": 2}}`))
+	assert.Nil(t, err)
+	assert.False(t, st.ReadyToTrip(Counts{ConsecutiveFailures: 1}))
+	assert.True(t, st.ReadyToTrip(Counts{ConsecutiveFailures: 2}))
+
+	invalid := []string{
+		`{"timeout": "soon"}`,
+		`{"maxRequest": 3}`,
+		`{"mode": "magic"}`,
+		`{"intervalPolicy": "sometimes"}`,
+		`{"readyToTrip": {"type": "coinFlip"}}`,
+		`{"readyToTrip": {"type": "failureRate", "rate": 2}}`,
+		`{"maxRequests": 1, "idempotentProbes": 2}`,
+	}
+	for _, data := range invalid {
+		_, err := SettingsFromJSON([]byte(data))
+		assert.True(t, errors.Is(err, ErrInvalidSettings), data)
+	}
+}
+
+func TestConfigMarshal(t *testing.T) {
+	c := Config{Name: "db", Timeout: Duration(90 * time.Second)}
+	data, err := json.Marshal(c)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"db","timeout":"1m30s"}`, string(data))
+}
+
+func TestConfigMinimumRequestThreshold(t *testing.T) {
+	st, err := SettingsFromJSON([]byte(`{"minimumRequestThreshold": 10}`))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(10), st.MinimumRequestThreshold)
+}
@@ -0,0 +1,62 @@
+package gobreaker
+
+import (
+	"context"
+	"time"
+)
+
+// CallCompleteEvent describes a request completed by the CircuitBreaker.
+// State is the state of the CircuitBreaker when the request was allowed.
+// Timeout is the effective timeout of the context passed to the request,
+// or 0 if the request was run without a timeout.
+// Metadata is the Metadata given to the request by WithMetadata.
+type CallCompleteEvent struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+	State    State
+	Timeout  time.Duration
+	Metadata Metadata
+}
+
+type callTimeoutKey struct{}
+
+// WithCallTimeout returns a copy of ctx that overrides Settings.CallTimeout
+// for the requests run by ExecuteContext with it,
+// e.g. a shorter timeout for a latency-critical path or a longer one for a batch job.
+// If d is 0, the request is run without a timeout.
+func WithCallTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutKey{}, d)
+}
+
+// ExecuteContext is like Execute but passes ctx to the request.
+// If ctx is already done, ExecuteContext returns the error of ctx without running the request.
+// If Settings.CallTimeout or WithCallTimeout gives a timeout,
+// the context passed to the request is canceled after the timeout.
+// The request is expected to return when its context is done.
+// With Settings.EnforceCallTimeout, the timeout of the context is also enforced on the request.
+func (cb *CircuitBreaker) ExecuteContext(ctx context.Context, req func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	adm, err := cb.beforeRequest(callOptions{ctx: ctx})
+	if err != nil {
+		return nil, err
+	}
+
+	adm.timeout = adm.callTimeout
+	if d, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok {
+		adm.timeout = d
+	}
+	if adm.enforcedTimeout > 0 {
+		adm.enforcedTimeout = adm.timeout
+	}
+	if adm.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, adm.timeout)
+		defer cancel()
+	}
+
+	return cb.run(adm, func() (interface{}, error) { return req(ctx) })
+}
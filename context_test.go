@@ -0,0 +1,67 @@
+package gobreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func deadlineOf(ctx context.Context) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Duration(0), nil
+	}
+	return time.Until(deadline), nil
+}
+
+func TestExecuteContext(t *testing.T) {
+	var events []CallCompleteEvent
+	cb := NewCircuitBreaker(Settings{
+		CallTimeout:            time.Duration(1) * time.Second,
+		OnCallCompleteDetailed: func(ev CallCompleteEvent) { events = append(events, ev) },
+	})
+
+	d, err := cb.ExecuteContext(context.Background(), deadlineOf)
+	assert.Nil(t, err)
+	assert.True(t, d.(time.Duration) > time.Duration(900)*time.Millisecond)
+	assert.True(t, d.(time.Duration) <= time.Duration(1)*time.Second)
+
+	ctx := WithCallTimeout(context.Background(), time.Duration(10)*time.Second)
+	d, err = cb.ExecuteContext(ctx, deadlineOf)
+	assert.Nil(t, err)
+	assert.True(t, d.(time.Duration) > time.Duration(9)*time.Second)
+
+	ctx = WithCallTimeout(context.Background(), 0)
+	d, err = cb.ExecuteContext(ctx, deadlineOf)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	assert.Nil(t, succeed(cb))
+
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, time.Duration(1)*time.Second, events[0].Timeout)
+	assert.Equal(t, time.Duration(10)*time.Second, events[1].Timeout)
+	assert.Equal(t, time.Duration(0), events[2].Timeout)
+	assert.Equal(t, time.Duration(0), events[3].Timeout)
+	assert.Equal(t, StateClosed, events[3].State)
+}
+
+func TestExecuteContextDone(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cb.ExecuteContext(ctx, deadlineOf)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+	ctx = WithCallTimeout(context.Background(), time.Millisecond)
+	_, err = cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+}
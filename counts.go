@@ -0,0 +1,103 @@
+package gobreaker
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// atomicCounts holds the internal Counts of CircuitBreaker in atomic fields,
+// so that Counts can be read without the lock, e.g. by the fan-out clients polling many CircuitBreakers.
+// atomicCounts is modified only under the lock of CircuitBreaker.
+// Every modification increments seq before and after it, like a seqlock,
+// so that snapshot can detect a concurrent modification and retry.
+type atomicCounts struct {
+	seq                  uint32
+	requests             uint32
+	totalSuccesses       uint32
+	totalFailures        uint32
+	consecutiveSuccesses uint32
+	consecutiveFailures  uint32
+}
+
+// snapshot returns a consistent copy of the Counts.
+func (c *atomicCounts) snapshot() Counts {
+	for {
+		seq := atomic.LoadUint32(&c.seq)
+		if seq%2 == 1 {
+			runtime.Gosched()
+			continue
+		}
+
+		counts := Counts{
+			Requests:             atomic.LoadUint32(&c.requests),
+			TotalSuccesses:       atomic.LoadUint32(&c.totalSuccesses),
+			TotalFailures:        atomic.LoadUint32(&c.totalFailures),
+			ConsecutiveSuccesses: atomic.LoadUint32(&c.consecutiveSuccesses),
+			ConsecutiveFailures:  atomic.LoadUint32(&c.consecutiveFailures),
+		}
+		if atomic.LoadUint32(&c.seq) == seq {
+			return counts
+		}
+	}
+}
+
+// store replaces the Counts with counts.
+func (c *atomicCounts) store(counts Counts) {
+	atomic.AddUint32(&c.seq, 1)
+	atomic.StoreUint32(&c.requests, counts.Requests)
+	atomic.StoreUint32(&c.totalSuccesses, counts.TotalSuccesses)
+	atomic.StoreUint32(&c.totalFailures, counts.TotalFailures)
+	atomic.StoreUint32(&c.consecutiveSuccesses, counts.ConsecutiveSuccesses)
+	atomic.StoreUint32(&c.consecutiveFailures, counts.ConsecutiveFailures)
+	atomic.AddUint32(&c.seq, 1)
+}
+
+func (c *atomicCounts) onRequest() {
+	counts := c.snapshot()
+	counts.onRequest()
+	c.store(counts)
+}
+
+func (c *atomicCounts) onSuccess() {
+	counts := c.snapshot()
+	counts.onSuccess()
+	c.store(counts)
+}
+
+func (c *atomicCounts) onFailure() {
+	counts := c.snapshot()
+	counts.onFailure()
+	c.store(counts)
+}
+
+// onCancel withdraws a request counted by onRequest.
+func (c *atomicCounts) onCancel() {
+	counts := c.snapshot()
+	if counts.Requests > 0 {
+		counts.Requests--
+		c.store(counts)
+	}
+}
+
+// add counts the requests and then their successes and failures at once.
+// The failures are assumed to have happened after the successes, so that a batch with failures
+// ends with ConsecutiveFailures.
+func (c *atomicCounts) add(requests, successes, failures uint32) {
+	counts := c.snapshot()
+	counts.Requests += requests
+	if successes > 0 {
+		counts.TotalSuccesses += successes
+		counts.ConsecutiveSuccesses += successes
+		counts.ConsecutiveFailures = 0
+	}
+	if failures > 0 {
+		counts.TotalFailures += failures
+		counts.ConsecutiveFailures += failures
+		counts.ConsecutiveSuccesses = 0
+	}
+	c.store(counts)
+}
+
+func (c *atomicCounts) clear() {
+	c.store(Counts{})
+}
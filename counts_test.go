@@ -0,0 +1,149 @@
+package gobreaker
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicCounts(t *testing.T) {
+	var c atomicCounts
+	c.onRequest()
+	c.onSuccess()
+	c.onRequest()
+	c.onFailure()
+	c.onRequest()
+	assert.Equal(t, Counts{3, 1, 1, 0, 1}, c.snapshot())
+
+	c.onCancel()
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, c.snapshot())
+
+	c.clear()
+	c.onCancel()
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, c.snapshot())
+}
+
+func TestAtomicCountsConsistent(t *testing.T) {
+	var c atomicCounts
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10000; i++ {
+			c.onRequest()
+			c.onSuccess()
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			assert.Equal(t, Counts{10000, 10000, 0, 10000, 0}, c.snapshot())
+			return
+		default:
+			counts := c.snapshot()
+			assert.True(t, counts.TotalSuccesses == counts.ConsecutiveSuccesses)
+			assert.True(t, counts.Requests-counts.TotalSuccesses <= 1)
+		}
+	}
+}
+
+func TestCountsWithoutLock(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	assert.Nil(t, succeed(cb))
+
+	cb.mutex.Lock()
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+	cb.mutex.Unlock()
+}
+
+// mutexCounts is the Counts guarded by a mutex, as CircuitBreaker had before atomicCounts.
+type mutexCounts struct {
+	mutex  sync.Mutex
+	counts Counts
+}
+
+func (c *mutexCounts) onSuccess() {
+	c.mutex.Lock()
+	c.counts.onSuccess()
+	c.mutex.Unlock()
+}
+
+func (c *mutexCounts) snapshot() Counts {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.counts
+}
+
+type benchCounts interface {
+	onSuccess()
+	snapshot() Counts
+}
+
+func BenchmarkCountsSnapshotAtomic(b *testing.B) {
+	benchmarkCountsSnapshot(b, &atomicCounts{})
+}
+
+func BenchmarkCountsSnapshotMutex(b *testing.B) {
+	benchmarkCountsSnapshot(b, &mutexCounts{})
+}
+
+// benchmarkCountsSnapshot reads c in parallel while another goroutine keeps updating it.
+func benchmarkCountsSnapshot(b *testing.B, c benchCounts) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				c.onSuccess()
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.snapshot()
+		}
+	})
+
+	close(done)
+	wg.Wait()
+}
+
+func BenchmarkCountsUpdateAtomic(b *testing.B) {
+	benchmarkCountsUpdate(b, &atomicCounts{})
+}
+
+func BenchmarkCountsUpdateMutex(b *testing.B) {
+	benchmarkCountsUpdate(b, &mutexCounts{})
+}
+
+func benchmarkCountsUpdate(b *testing.B, c benchCounts) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.onSuccess()
+	}
+}
+
+// BenchmarkCountsParallel reads Counts of a CircuitBreaker in parallel with the requests,
+// like a fan-out client polling its CircuitBreakers.
+func BenchmarkCountsParallel(b *testing.B) {
+	cb := NewCircuitBreaker(Settings{})
+	req := func() (interface{}, error) { return nil, nil }
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if i%8 == 0 {
+				cb.Execute(req)
+			} else {
+				cb.Counts()
+			}
+		}
+	})
+}
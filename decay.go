@@ -0,0 +1,83 @@
+package gobreaker
+
+import (
+	"math"
+	"time"
+)
+
+// decayedCounts counts the outcomes of the requests in the closed state with exponential decay by Settings.DecayHalfLife.
+// Like the windows of BurnRate, decayedCounts is not cleared by the generations but only when the CircuitBreaker closes.
+type decayedCounts struct {
+	halfLife  time.Duration
+	updated   time.Time
+	successes float64
+	failures  float64
+}
+
+// decay decays the counts from the last update to now.
+func (d *decayedCounts) decay(now time.Time) {
+	elapsed := now.Sub(d.updated)
+	if elapsed <= 0 {
+		return
+	}
+
+	f := math.Exp2(-float64(elapsed) / float64(d.halfLife))
+	d.successes *= f
+	d.failures *= f
+	d.updated = now
+}
+
+func (d *decayedCounts) add(now time.Time, successes, failures uint32) {
+	d.decay(now)
+	d.successes += float64(successes)
+	d.failures += float64(failures)
+}
+
+func (d *decayedCounts) clear() {
+	d.successes = 0
+	d.failures = 0
+}
+
+// counts returns c with the decayed successes and failures rounded to the nearest integers.
+// Requests is the decayed requests completed and the requests in flight.
+func (d *decayedCounts) counts(now time.Time, c Counts) Counts {
+	d.decay(now)
+
+	var inFlight uint32
+	if completed := c.TotalSuccesses + c.TotalFailures; c.Requests > completed {
+		inFlight = c.Requests - completed
+	}
+	c.TotalSuccesses = uint32(math.Round(d.successes))
+	c.TotalFailures = uint32(math.Round(d.failures))
+	c.Requests = c.TotalSuccesses + c.TotalFailures + inFlight
+	return c
+}
+
+// setDecayHalfLife applies Settings.DecayHalfLife, keeping the counted outcomes if the half-life is unchanged.
+func (cb *CircuitBreaker) setDecayHalfLife(halfLife time.Duration) {
+	if halfLife <= 0 {
+		cb.decay = nil
+		return
+	}
+
+	if cb.decay == nil || cb.decay.halfLife != halfLife {
+		cb.decay = &decayedCounts{halfLife: halfLife}
+	}
+}
+
+// countDecayed counts the outcomes of the requests in the closed state by DecayHalfLife.
+func (cb *CircuitBreaker) countDecayed(now time.Time, successes, failures uint32) {
+	if cb.decay != nil {
+		cb.decay.add(now, successes, failures)
+	}
+}
+
+// tripCounts returns the Counts that decide whether the CircuitBreaker trips in the closed state,
+// which are decayed if DecayHalfLife is set.
+func (cb *CircuitBreaker) tripCounts() Counts {
+	counts := cb.counts.snapshot()
+	if cb.decay == nil {
+		return counts
+	}
+	return cb.decay.counts(cb.clock.Now(), counts)
+}
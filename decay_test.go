@@ -0,0 +1,49 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecayHalfLife(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var tripCounts Counts
+	cb := NewCircuitBreaker(Settings{
+		Clock:         clock,
+		Interval:      time.Duration(30) * time.Second,
+		DecayHalfLife: time.Minute,
+		ReadyToTrip: func(counts Counts) bool {
+			tripCounts = counts
+			return counts.TotalFailures >= 4
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	clock.advance(time.Duration(31) * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{4, 1, 3, 0, 1}, tripCounts)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, tripCounts)
+
+	clock.advance(time.Duration(10) * time.Minute)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, tripCounts)
+
+	err := Settings{DecayHalfLife: time.Minute, BurnRate: &BurnRate{Target: 0.99}}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
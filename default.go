@@ -0,0 +1,33 @@
+package gobreaker
+
+import "sync"
+
+var (
+	defaultMutex sync.Mutex
+	defaultGroup = NewGroup(Settings{})
+)
+
+// Configure replaces the default Group used by Do with a new Group that creates CircuitBreakers
+// with the given Settings, so that small programs can use CircuitBreakers without passing them around.
+// The CircuitBreakers created by the previous default Group are no longer used by Do,
+// so Configure is usually called once at startup.
+func Configure(st Settings) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	defaultGroup = NewGroup(st)
+}
+
+// DefaultGroup returns the default Group used by Do, e.g. to export its CircuitBreakers.
+func DefaultGroup() *Group {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	return defaultGroup
+}
+
+// Do runs the given request with the CircuitBreaker of the given name in the default Group,
+// creating it with the Settings given to Configure if it doesn't exist. See CircuitBreaker.Execute.
+func Do(name string, req func() (interface{}, error)) (interface{}, error) {
+	return DefaultGroup().Execute(name, req)
+}
@@ -0,0 +1,28 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	defer Configure(Settings{})
+
+	Configure(Settings{ReadyToTrip: func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 }})
+	for i := 0; i < 2; i++ {
+		_, err := Do("db", func() (interface{}, error) { return nil, errors.New("fail") })
+		assert.EqualError(t, err, "fail")
+	}
+	_, err := Do("db", func() (interface{}, error) { return nil, nil })
+	assert.Equal(t, ErrOpenState, RejectionReason(err))
+
+	result, err := Do("cache", func() (interface{}, error) { return "hit", nil })
+	assert.Equal(t, "hit", result)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cache", "db"}, DefaultGroup().Names())
+
+	Configure(Settings{})
+	assert.Empty(t, DefaultGroup().Names())
+}
@@ -0,0 +1,192 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrDependencyCycle is wrapped by the error returned from Group.DependsOn when the dependency would make a cycle.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// DependencyPolicy is a type that represents how a CircuitBreaker of a Group follows a CircuitBreaker it depends on.
+type DependencyPolicy int
+
+// These constants are policies of the dependencies declared by Group.DependsOn.
+//
+// DependencyTrip trips the dependent CircuitBreaker when the dependency opens,
+// so that the requests bound to fail fail fast, and the dependent recovers by its own probes.
+//
+// DependencyIgnoreFailures makes the dependent CircuitBreaker ignore the failures while the dependency is not closed,
+// like MaintenanceNeverTrip, so that the failures caused by the dependency don't trip the dependent as well.
+const (
+	DependencyTrip DependencyPolicy = iota
+	DependencyIgnoreFailures
+)
+
+// String implements stringer interface.
+func (p DependencyPolicy) String() string {
+	switch p {
+	case DependencyTrip:
+		return "trip"
+	case DependencyIgnoreFailures:
+		return "ignore-failures"
+	default:
+		return fmt.Sprintf("unknown dependency policy: %d", p)
+	}
+}
+
+// Dependency is an edge of the dependency graph of a Group: the CircuitBreaker Name depends on DependsOn.
+type Dependency struct {
+	Name      string
+	DependsOn string
+	Policy    DependencyPolicy
+}
+
+// DependsOn declares that the CircuitBreaker name depends on the CircuitBreaker dependency with policy,
+// e.g. "search" on "elasticsearch", creating the CircuitBreakers if they don't exist.
+// Declaring the same dependency again replaces its policy.
+// The dependencies are kept by name, so they apply to the CircuitBreakers created again after Remove or the eviction.
+// DependsOn returns an error wrapping ErrDependencyCycle if dependency depends on name, directly or not.
+func (g *Group) DependsOn(name, dependency string, policy DependencyPolicy) error {
+	g.mutex.Lock()
+	if g.reaches(dependency, name) {
+		g.mutex.Unlock()
+		return fmt.Errorf("%w: %s depends on %s", ErrDependencyCycle, dependency, name)
+	}
+
+	if g.dependencies == nil {
+		g.dependencies = make(map[string]map[string]DependencyPolicy)
+	}
+	if g.dependencies[name] == nil {
+		g.dependencies[name] = make(map[string]DependencyPolicy)
+	}
+	g.dependencies[name][dependency] = policy
+
+	dep := g.get(dependency)
+	g.watch(dependency, dep)
+	cb := g.get(name)
+	cb.excuse(dependency, policy == DependencyIgnoreFailures && dep.State() != StateClosed)
+	evicted := g.evict()
+	g.mutex.Unlock()
+
+	g.notifyEvicted(evicted)
+	return nil
+}
+
+// Dependencies returns the dependency graph of the Group as the edges sorted by Name and DependsOn,
+// e.g. for visualization.
+func (g *Group) Dependencies() []Dependency {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	var deps []Dependency
+	for name, edges := range g.dependencies {
+		for dependency, policy := range edges {
+			deps = append(deps, Dependency{Name: name, DependsOn: dependency, Policy: policy})
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].DependsOn < deps[j].DependsOn
+	})
+	return deps
+}
+
+// reaches reports whether from is to or depends on to, directly or not.
+func (g *Group) reaches(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for dependency := range g.dependencies[from] {
+		if g.reaches(dependency, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// follow applies the dependencies to cb newly registered under name.
+func (g *Group) follow(name string, cb *CircuitBreaker) {
+	for _, edges := range g.dependencies {
+		if _, ok := edges[name]; ok {
+			g.watch(name, cb)
+			break
+		}
+	}
+
+	for dependency, policy := range g.dependencies[name] {
+		if dep, ok := g.breakers[dependency]; ok && policy == DependencyIgnoreFailures {
+			cb.excuse(dependency, dep.State() != StateClosed)
+		}
+	}
+}
+
+// watch attaches a Resource to cb, the dependency registered under name, that notifies its dependents.
+func (g *Group) watch(name string, cb *CircuitBreaker) {
+	if _, ok := g.watches[name]; ok {
+		return
+	}
+
+	if g.watches == nil {
+		g.watches = make(map[string]func())
+	}
+	g.watches[name] = cb.Attach(ResourceFuncs{
+		Open:  func(ev StateChangeEvent) { g.notifyDependents(name, true) },
+		Close: func(ev StateChangeEvent) { g.notifyDependents(name, false) },
+	})
+}
+
+// unwatch detaches the Resource attached by watch to the CircuitBreaker registered under name.
+func (g *Group) unwatch(name string) {
+	if detach, ok := g.watches[name]; ok {
+		detach()
+		delete(g.watches, name)
+	}
+}
+
+// notifyDependents applies the policies of the dependents of the dependency that has opened or closed.
+func (g *Group) notifyDependents(dependency string, open bool) {
+	type dependent struct {
+		cb     *CircuitBreaker
+		policy DependencyPolicy
+	}
+
+	g.mutex.Lock()
+	var dependents []dependent
+	for name, edges := range g.dependencies {
+		policy, ok := edges[dependency]
+		if cb, registered := g.breakers[name]; ok && registered {
+			dependents = append(dependents, dependent{cb: cb, policy: policy})
+		}
+	}
+	g.mutex.Unlock()
+
+	for _, d := range dependents {
+		switch d.policy {
+		case DependencyTrip:
+			if open {
+				d.cb.Trip()
+			}
+		case DependencyIgnoreFailures:
+			d.cb.excuse(dependency, open)
+		}
+	}
+}
+
+// excuse makes the CircuitBreaker ignore the failures while any of its dependencies excuses them.
+func (cb *CircuitBreaker) excuse(dependency string, excused bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if !excused {
+		delete(cb.excusedBy, dependency)
+		return
+	}
+	if cb.excusedBy == nil {
+		cb.excusedBy = make(map[string]bool)
+	}
+	cb.excusedBy[dependency] = true
+}
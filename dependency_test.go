@@ -0,0 +1,64 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependsOn(t *testing.T) {
+	g := NewGroup(Settings{Timeout: time.Duration(61) * time.Second})
+	assert.Nil(t, g.DependsOn("search", "elasticsearch", DependencyTrip))
+	assert.Nil(t, g.DependsOn("api", "elasticsearch", DependencyIgnoreFailures))
+	assert.Nil(t, g.DependsOn("api", "search", DependencyIgnoreFailures))
+	assert.True(t, errors.Is(g.DependsOn("elasticsearch", "api", DependencyTrip), ErrDependencyCycle))
+	assert.True(t, errors.Is(g.DependsOn("search", "search", DependencyTrip), ErrDependencyCycle))
+
+	assert.Equal(t, []Dependency{
+		{Name: "api", DependsOn: "elasticsearch", Policy: DependencyIgnoreFailures},
+		{Name: "api", DependsOn: "search", Policy: DependencyIgnoreFailures},
+		{Name: "search", DependsOn: "elasticsearch", Policy: DependencyTrip},
+	}, g.Dependencies())
+	assert.Equal(t, []string{"api", "elasticsearch", "search"}, g.Names())
+
+	es, _ := g.Lookup("elasticsearch")
+	search, _ := g.Lookup("search")
+	api, _ := g.Lookup("api")
+
+	es.Trip()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, StateOpen, search.State())
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fail(api))
+	}
+	assert.Equal(t, StateClosed, api.State())
+	assert.Equal(t, Counts{}, api.Counts())
+
+	// a CircuitBreaker created again follows the dependencies at once
+	g.Remove("api")
+	api = g.Get("api")
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fail(api))
+	}
+	assert.Equal(t, StateClosed, api.State())
+
+	// api still ignores the failures while search is open
+	es.Reset()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, StateOpen, search.State())
+	assert.Nil(t, fail(api))
+	assert.Equal(t, Counts{}, api.Counts())
+
+	search.Reset()
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Nil(t, fail(api))
+	assert.Equal(t, Counts{Requests: 1, TotalFailures: 1, ConsecutiveFailures: 1}, api.Counts())
+}
+
+func TestDependencyPolicyString(t *testing.T) {
+	assert.Equal(t, "trip", DependencyTrip.String())
+	assert.Equal(t, "ignore-failures", DependencyIgnoreFailures.String())
+	assert.Equal(t, "unknown dependency policy: 2", DependencyPolicy(2).String())
+}
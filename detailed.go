@@ -0,0 +1,29 @@
+package gobreaker
+
+import "errors"
+
+// ExecuteDetailed is like Execute but returns the Result annotated with the decision of the CircuitBreaker,
+// so that the caller or a middleware can log the state that allowed the request
+// without calling State afterwards, which may have changed in the meantime.
+// If a parent CircuitBreaker rejects the request, State is the state of the parent and Generation is 0.
+// ExecuteDetailed doesn't share the probes of the half-open state by Settings.ProbeKey.
+func (cb *CircuitBreaker) ExecuteDetailed(req func() (interface{}, error)) Result {
+	adm, err := cb.beforeRequest(callOptions{})
+	if err != nil {
+		r := Result{Err: err, State: adm.state, Generation: adm.generation, Rejected: true}
+		var re *RejectionError
+		if errors.As(err, &re) {
+			r.State = re.State
+		}
+		return r
+	}
+
+	value, err := cb.run(adm, req)
+	return Result{
+		Value:      value,
+		Err:        err,
+		State:      adm.state,
+		Generation: adm.generation,
+		Duration:   cb.clock.Now().Sub(adm.start),
+	}
+}
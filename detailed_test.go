@@ -0,0 +1,33 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteDetailed(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	cb := NewCircuitBreaker(Settings{Timeout: time.Duration(61) * time.Second, Clock: clock})
+	errFail := errors.New("fail")
+
+	r := cb.ExecuteDetailed(func() (interface{}, error) {
+		clock.advance(time.Second)
+		return "ok", nil
+	})
+	assert.Equal(t, Result{Value: "ok", State: StateClosed, Generation: 1, Duration: time.Second}, r)
+
+	cb.Trip()
+	r = cb.ExecuteDetailed(func() (interface{}, error) { return "ok", nil })
+	assert.True(t, errors.Is(r.Err, ErrOpenState))
+	assert.True(t, r.Rejected)
+	assert.Equal(t, StateOpen, r.State)
+	assert.Equal(t, uint64(2), r.Generation)
+
+	clock.advance(time.Duration(62) * time.Second)
+	r = cb.ExecuteDetailed(func() (interface{}, error) { return nil, errFail })
+	assert.Equal(t, Result{Err: errFail, State: StateHalfOpen, Generation: 3}, r)
+	assert.Equal(t, StateOpen, cb.State())
+}
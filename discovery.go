@@ -0,0 +1,42 @@
+package gobreaker
+
+import "context"
+
+// Discovery is a source of the names of the backends of a dependency, e.g. a watcher of a service registry.
+//
+// Watch calls update with the complete set of the names of the backends at the start
+// and whenever the set changes, until ctx is done or the watch fails.
+type Discovery interface {
+	Watch(ctx context.Context, update func(names []string)) error
+}
+
+// Sync makes the Group have a CircuitBreaker for each of the given names and no others,
+// creating the CircuitBreakers for new names like Preload and removing the rest like Remove,
+// so that the per-backend CircuitBreakers, their metrics and admin views follow the real topology.
+func (g *Group) Sync(names []string) {
+	g.mutex.Lock()
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+		g.get(name)
+	}
+	var removed []*CircuitBreaker
+	for name, cb := range g.breakers {
+		if !keep[name] {
+			delete(g.breakers, name)
+			g.forget(name)
+			removed = append(removed, cb)
+		}
+	}
+	g.mutex.Unlock()
+
+	for _, cb := range removed {
+		cb.Close()
+	}
+}
+
+// Discover keeps the Group in sync with d by calling Sync with every update from d.
+// Discover blocks until ctx is done or d fails, and returns the error of Watch.
+func (g *Group) Discover(ctx context.Context, d Discovery) error {
+	return d.Watch(ctx, g.Sync)
+}
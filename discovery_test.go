@@ -0,0 +1,41 @@
+package gobreaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type discoveryFunc func(ctx context.Context, update func(names []string)) error
+
+func (f discoveryFunc) Watch(ctx context.Context, update func(names []string)) error {
+	return f(ctx, update)
+}
+
+func TestDiscover(t *testing.T) {
+	g := NewGroup(Settings{})
+	a := g.Get("a")
+	updates := make(chan []string)
+	d := discoveryFunc(func(ctx context.Context, update func(names []string)) error {
+		for {
+			select {
+			case names := <-updates:
+				update(names)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- g.Discover(ctx, d) }()
+
+	updates <- []string{"a", "b", "c"}
+	updates <- []string{"a", "c", "d"}
+	cancel()
+	assert.Equal(t, context.Canceled, <-errc)
+	assert.Equal(t, []string{"a", "c", "d"}, g.Names())
+	assert.True(t, a == g.Get("a"))
+}
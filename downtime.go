@@ -0,0 +1,120 @@
+package gobreaker
+
+import "time"
+
+// DowntimeWindow is the rolling window over which the downtime of CircuitBreaker is accumulated.
+const DowntimeWindow = time.Duration(24) * time.Hour
+
+type openPeriod struct {
+	start time.Time
+	end   time.Time
+}
+
+// downtime tracks the periods of the open state within DowntimeWindow.
+type downtime struct {
+	periods  []openPeriod
+	openedAt time.Time
+	exceeded bool
+	timer    Timer
+}
+
+// total returns the time spent in the open state during DowntimeWindow until now.
+func (d *downtime) total(now time.Time) time.Duration {
+	from := now.Add(-DowntimeWindow)
+
+	var kept []openPeriod
+	var sum time.Duration
+	for _, p := range d.periods {
+		if !p.end.After(from) {
+			continue
+		}
+		kept = append(kept, p)
+		sum += p.end.Sub(later(p.start, from))
+	}
+	d.periods = kept
+
+	if !d.openedAt.IsZero() {
+		sum += now.Sub(later(d.openedAt, from))
+	}
+	return sum
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// Downtime returns the cumulative time the CircuitBreaker has spent in the open state
+// during the last DowntimeWindow.
+func (cb *CircuitBreaker) Downtime() time.Duration {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+	return cb.downtime.total(now)
+}
+
+func (cb *CircuitBreaker) onOpenStart(now time.Time) {
+	cb.downtime.openedAt = now
+	if cb.downtimeBudget <= 0 {
+		return
+	}
+
+	total := cb.downtime.total(now)
+	if total < cb.downtimeBudget {
+		cb.downtime.exceeded = false
+	}
+	cb.checkDowntimeBudget(now, total)
+}
+
+func (cb *CircuitBreaker) onOpenEnd(now time.Time) {
+	cb.downtime.periods = append(cb.downtime.periods, openPeriod{start: cb.downtime.openedAt, end: now})
+	cb.downtime.openedAt = time.Time{}
+	cb.downtime.total(now) // drops the periods out of DowntimeWindow
+	if cb.downtime.timer != nil {
+		cb.downtime.timer.Stop()
+		cb.downtime.timer = nil
+	}
+}
+
+// checkDowntimeBudget fires the budget alert if the downtime has crossed DowntimeBudget,
+// or otherwise schedules the check at the time the budget would be exhausted.
+func (cb *CircuitBreaker) checkDowntimeBudget(now time.Time, total time.Duration) {
+	if cb.downtime.exceeded {
+		return
+	}
+
+	if total < cb.downtimeBudget {
+		if cb.closed {
+			return
+		}
+		// The open state may start new generations, e.g. by a vetoed transition to the half-open state,
+		// so the timer follows the open period rather than the generation, and re-arms itself until the period ends.
+		openedAt := cb.downtime.openedAt
+		cb.downtime.timer = cb.clock.AfterFunc(cb.downtimeBudget-total, func() {
+			cb.mutex.Lock()
+			defer cb.mutex.Unlock()
+
+			if cb.closed {
+				return
+			}
+			now := cb.clock.Now()
+			cb.currentState(now) // ends the open period if Timeout has expired
+			if !cb.downtime.openedAt.Equal(openedAt) || openedAt.IsZero() {
+				return
+			}
+			cb.checkDowntimeBudget(now, cb.downtime.total(now))
+		})
+		return
+	}
+
+	cb.downtime.exceeded = true
+	if cb.onDowntimeBudgetExceeded != nil {
+		cb.onDowntimeBudgetExceeded(cb.name, total)
+	}
+	cb.publish(Event{Type: EventDowntimeBudgetExceeded, Time: now, State: cb.state, Counts: cb.counts.snapshot(), Downtime: total})
+}
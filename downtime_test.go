@@ -0,0 +1,84 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDowntimeTotal(t *testing.T) {
+	now := time.Now()
+	d := downtime{periods: []openPeriod{
+		{start: now.Add(-DowntimeWindow - time.Hour), end: now.Add(-DowntimeWindow - time.Minute)},
+		{start: now.Add(-DowntimeWindow - time.Minute), end: now.Add(-DowntimeWindow + time.Minute)},
+		{start: now.Add(-time.Hour), end: now.Add(-time.Hour + time.Minute)},
+	}}
+	assert.Equal(t, 2*time.Minute, d.total(now))
+	assert.Equal(t, 2, len(d.periods))
+
+	d.openedAt = now.Add(-time.Minute)
+	assert.Equal(t, 3*time.Minute, d.total(now))
+}
+
+func TestDowntimeBudget(t *testing.T) {
+	exceeded := make(chan time.Duration, 1)
+	cb := NewCircuitBreaker(Settings{
+		DowntimeBudget: time.Duration(50) * time.Millisecond,
+		OnDowntimeBudgetExceeded: func(name string, downtime time.Duration) {
+			exceeded <- downtime
+		},
+	})
+	ch := cb.Subscribe()
+
+	cb.Trip()
+	time.Sleep(time.Duration(20) * time.Millisecond)
+	cb.Reset()
+	assert.True(t, cb.Downtime() >= time.Duration(20)*time.Millisecond)
+	assert.Equal(t, 0, len(exceeded))
+
+	cb.Trip()
+	downtime := <-exceeded
+	assert.True(t, downtime >= time.Duration(50)*time.Millisecond)
+	for ev := range ch {
+		if ev.Type == EventDowntimeBudgetExceeded {
+			assert.Equal(t, downtime, ev.Downtime)
+			break
+		}
+	}
+
+	// no alert until the downtime falls below the budget
+	cb.Reset()
+	cb.Trip()
+	time.Sleep(time.Duration(20) * time.Millisecond)
+	assert.Equal(t, 0, len(exceeded))
+}
+
+func TestDowntimeBudgetNewGeneration(t *testing.T) {
+	exceeded := make(chan time.Duration, 1)
+	cb := NewCircuitBreaker(Settings{
+		Timeout:        time.Duration(10) * time.Millisecond,
+		DowntimeBudget: time.Duration(50) * time.Millisecond,
+		BeforeStateChange: func(name string, from, to State, counts Counts) bool {
+			return from != StateOpen
+		},
+		OnDowntimeBudgetExceeded: func(name string, downtime time.Duration) {
+			exceeded <- downtime
+		},
+	})
+
+	cb.Trip()
+	generation := cb.Snapshot().Generation
+	time.Sleep(time.Duration(20) * time.Millisecond)
+	s := cb.Snapshot() // the vetoed transition to the half-open state starts a new generation
+	assert.Equal(t, StateOpen, s.State)
+	assert.NotEqual(t, generation, s.Generation)
+
+	select {
+	case downtime := <-exceeded:
+		assert.True(t, downtime >= time.Duration(50)*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Error("downtime budget alert not fired in a new generation of the open state")
+	}
+	assert.Nil(t, cb.Close())
+}
@@ -0,0 +1,83 @@
+package gobreaker
+
+import "time"
+
+// CallRecord records the outcome of a request completed by CircuitBreaker.
+// State is the state of the CircuitBreaker when the request was allowed.
+// Err is the message of the error returned from the request, if any.
+type CallRecord struct {
+	Time     time.Time
+	Duration time.Duration
+	State    State
+	Verdict  Verdict
+	Err      string
+}
+
+// TripDump is the forensic detail captured when CircuitBreaker trips into the open state:
+// the Counts and Stats of the generation that tripped it, and the latest requests before the trip.
+type TripDump struct {
+	Time       time.Time
+	From       State
+	Generation uint64
+	Counts     Counts
+	Stats      Stats
+	Calls      []CallRecord
+}
+
+// recordCall records the outcome of the request admitted with adm for the next TripDump.
+func (cb *CircuitBreaker) recordCall(adm admission, success bool, now time.Time) {
+	if cb.calls.cap() == 0 {
+		return
+	}
+
+	r := CallRecord{
+		Time:     now,
+		Duration: now.Sub(adm.start),
+		State:    adm.state,
+		Verdict:  Failure,
+	}
+	if success {
+		r.Verdict = Success
+	}
+	if adm.err != nil {
+		r.Err = adm.err.Error()
+	}
+	cb.calls.add(r)
+}
+
+// captureTripDump captures the TripDump of the generation ending with a trip from the state from.
+func (cb *CircuitBreaker) captureTripDump(from State, now time.Time) {
+	if cb.calls.cap() == 0 {
+		return
+	}
+
+	cb.tripDump = &TripDump{
+		Time:       now,
+		From:       from,
+		Generation: cb.generation,
+		Counts:     cb.counts.snapshot(),
+		Stats:      cb.snapshotStats(),
+		Calls:      cb.callRecords(),
+	}
+}
+
+func (cb *CircuitBreaker) callRecords() []CallRecord {
+	var records []CallRecord
+	for _, r := range cb.calls.list() {
+		records = append(records, r.(CallRecord))
+	}
+	return records
+}
+
+// LastTripDump returns the TripDump captured when the CircuitBreaker tripped most recently.
+// LastTripDump returns false if Settings.TripDumpSize is 0 or the CircuitBreaker has never tripped.
+func (cb *CircuitBreaker) LastTripDump() (TripDump, bool) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.tripDump == nil {
+		return TripDump{}, false
+	}
+	return *cb.tripDump, true
+}
@@ -0,0 +1,35 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTripDump(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{TripDumpSize: 3})
+	_, ok := cb.LastTripDump()
+	assert.False(t, ok)
+
+	assert.Nil(t, succeed(cb))
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+
+	d, ok := cb.LastTripDump()
+	assert.True(t, ok)
+	assert.Equal(t, StateClosed, d.From)
+	assert.Equal(t, uint64(1), d.Generation)
+	assert.Equal(t, Counts{7, 1, 6, 0, 6}, d.Counts)
+	assert.Equal(t, uint32(7), d.Stats.Count)
+	assert.Equal(t, 3, len(d.Calls))
+	for _, c := range d.Calls {
+		assert.Equal(t, Failure, c.Verdict)
+		assert.Equal(t, "fail", c.Err)
+		assert.Equal(t, StateClosed, c.State)
+	}
+
+	_, ok = NewCircuitBreaker(Settings{}).LastTripDump()
+	assert.False(t, ok)
+}
@@ -0,0 +1,121 @@
+package gobreaker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProbeElector elects the replica that probes a recovering dependency when multiple replicas of a service
+// break for the same dependency, so that the dependency is not hit by the probes of all the replicas at once.
+// Set it to Settings.ProbeElector.
+//
+// Elect is called when the open state of the CircuitBreaker named name has expired,
+// and reports whether this replica holds the lease to probe for ttl, which is Settings.Timeout.
+// Each replica uses its own ProbeElector with its own identity over a shared storage,
+// e.g. SET name owner NX PX ttl on Redis, or a key attached to a lease of ttl in a transaction on etcd,
+// and Elect must return true again to the holder of an unexpired lease.
+// MemoryLeases provides ProbeElectors for the replicas in a process,
+// and the modules gobreakerredis and gobreakeretcd provide those on Redis and etcd.
+type ProbeElector interface {
+	Elect(ctx context.Context, name string, ttl time.Duration) (bool, error)
+}
+
+func (cb *CircuitBreaker) setProbeElector(e ProbeElector) {
+	cb.probeElector = e
+	if e != nil {
+		atomic.StoreUint32(&cb.hasProbeElector, 1)
+	} else {
+		atomic.StoreUint32(&cb.hasProbeElector, 0)
+	}
+}
+
+// electProbe asks the ProbeElector whether this replica may probe if the open state has expired.
+// The elected replica becomes half-open as usual, and the others stay open for another Timeout.
+// An error of the ProbeElector is treated as a lost election.
+func (cb *CircuitBreaker) electProbe(opts callOptions) {
+	if atomic.LoadUint32(&cb.hasProbeElector) == 0 {
+		return
+	}
+
+	cb.mutex.Lock()
+	now := cb.clock.Now()
+	elector, generation, ttl := cb.probeElector, cb.generation, cb.timeout
+	expired := elector != nil && cb.state == StateOpen && cb.expiry.Before(now)
+	if !expired || cb.electing || cb.elected == generation {
+		cb.mutex.Unlock()
+		return
+	}
+	cb.electing = true
+	cb.mutex.Unlock()
+
+	ctx := opts.ctx
+	if ctx == nil {
+		ctx = cb.ctx
+	}
+	elected, err := elector.Elect(ctx, cb.name, ttl)
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.electing = false
+	if cb.state != StateOpen || cb.generation != generation {
+		return
+	}
+	if err == nil && elected {
+		cb.elected = generation
+	} else {
+		cb.toNewGeneration(cb.clock.Now())
+	}
+}
+
+// probeElected reports whether the expired open state may become half-open.
+func (cb *CircuitBreaker) probeElected() bool {
+	return cb.probeElector == nil || cb.elected == cb.generation
+}
+
+// MemoryLeases is an in-memory storage of the leases of ProbeElectors,
+// e.g. for the replicas running in a process or for tests.
+type MemoryLeases struct {
+	clock  Clock
+	mutex  sync.Mutex
+	leases map[string]memoryLease
+}
+
+type memoryLease struct {
+	owner  string
+	expiry time.Time
+}
+
+// NewMemoryLeases returns a new MemoryLeases using clock.
+// If clock is nil, the system time is used.
+func NewMemoryLeases(clock Clock) *MemoryLeases {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &MemoryLeases{clock: clock, leases: make(map[string]memoryLease)}
+}
+
+// Elector returns the ProbeElector of the replica identified by owner.
+func (m *MemoryLeases) Elector(owner string) ProbeElector {
+	return memoryElector{leases: m, owner: owner}
+}
+
+type memoryElector struct {
+	leases *MemoryLeases
+	owner  string
+}
+
+func (e memoryElector) Elect(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	m := e.leases
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.clock.Now()
+	if l, ok := m.leases[name]; ok && l.owner != e.owner && now.Before(l.expiry) {
+		return false, nil
+	}
+	m.leases[name] = memoryLease{owner: e.owner, expiry: now.Add(ttl)}
+	return true, nil
+}
@@ -0,0 +1,44 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingElector struct{}
+
+func (failingElector) Elect(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+func TestProbeElector(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	leases := NewMemoryLeases(clock)
+	a := NewCircuitBreaker(Settings{Name: "db", Clock: clock, ProbeElector: leases.Elector("a")})
+	b := NewCircuitBreaker(Settings{Name: "db", Clock: clock, ProbeElector: leases.Elector("b")})
+	a.Trip()
+	b.Trip()
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Equal(t, StateOpen, a.State())
+	assert.Nil(t, succeed(a))
+	assert.Equal(t, StateClosed, a.State())
+
+	assert.Equal(t, ErrOpenState, RejectionReason(succeed(b)))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, clock.now.Add(time.Duration(60)*time.Second), b.Expiry())
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Nil(t, succeed(b))
+	assert.Equal(t, StateClosed, b.State())
+
+	c := NewCircuitBreaker(Settings{Clock: clock, ProbeElector: failingElector{}})
+	c.Trip()
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Equal(t, ErrOpenState, RejectionReason(succeed(c)))
+	assert.Equal(t, StateOpen, c.State())
+}
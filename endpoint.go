@@ -0,0 +1,39 @@
+package gobreaker
+
+// Endpoints guards the backends of a load-balanced dependency with a CircuitBreaker per backend address,
+// e.g. the addresses resolved from the hostname of the dependency,
+// so that only a failing backend is broken instead of the whole hostname.
+// The CircuitBreakers are named by the addresses and kept in a Group.
+type Endpoints struct {
+	group *Group
+}
+
+// NewEndpoints returns a new Endpoints that creates the CircuitBreakers of the backends with the given Settings.
+func NewEndpoints(st Settings) *Endpoints {
+	return &Endpoints{group: NewGroup(st)}
+}
+
+// Group returns the Group of the CircuitBreakers of the backends, e.g. for AdminHandler or Sync with Discovery.
+func (e *Endpoints) Group() *Group {
+	return e.group
+}
+
+// Execute runs the given request to the backend at addr with the CircuitBreaker of addr.
+// See CircuitBreaker.Execute.
+func (e *Endpoints) Execute(addr string, req func() (interface{}, error)) (interface{}, error) {
+	return e.group.Get(addr).Execute(req)
+}
+
+// Available returns the addresses among addrs whose CircuitBreakers are not open, in the same order,
+// so that a load balancer can pick a backend that is likely to accept the request.
+// If all the CircuitBreakers are open, Available returns nil.
+func (e *Endpoints) Available(addrs []string) []string {
+	var available []string
+	for _, addr := range addrs {
+		if cb, ok := e.group.Lookup(addr); ok && cb.State() == StateOpen {
+			continue
+		}
+		available = append(available, addr)
+	}
+	return available
+}
@@ -0,0 +1,32 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoints(t *testing.T) {
+	e := NewEndpoints(Settings{
+		ReadyToTrip: func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	})
+	addrs := []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80"}
+	assert.Equal(t, addrs, e.Available(addrs))
+
+	for i := 0; i < 2; i++ {
+		_, err := e.Execute(addrs[1], func() (interface{}, error) { return nil, errors.New("fail") })
+		assert.Equal(t, "fail", err.Error())
+	}
+	result, err := e.Execute(addrs[0], func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, "ok", result)
+	assert.Nil(t, err)
+
+	// only the failing backend is open
+	_, err = e.Execute(addrs[1], func() (interface{}, error) { return nil, nil })
+	assert.True(t, errors.Is(err, ErrOpenState))
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.3:80"}, e.Available(addrs))
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.2:80"}, e.Group().Names())
+
+	assert.Nil(t, e.Available(addrs[1:2]))
+}
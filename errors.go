@@ -0,0 +1,139 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RejectionError is returned when the CircuitBreaker rejects a request.
+// It wraps ErrOpenState, ErrTooManyRequests, ErrThrottled or ErrInsufficientBudget, so errors.Is works with the sentinels.
+//
+// Name, State and Counts are the snapshot of the CircuitBreaker at the time of the rejection.
+// RetryAfter is the time remaining until the CircuitBreaker becomes half-open.
+// RetryAfter is 0 if State is not StateOpen.
+type RejectionError struct {
+	Name       string
+	State      State
+	Counts     Counts
+	RetryAfter time.Duration
+
+	err error
+}
+
+// Error implements error interface.
+func (e *RejectionError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns ErrOpenState, ErrTooManyRequests, ErrThrottled or ErrInsufficientBudget.
+func (e *RejectionError) Unwrap() error {
+	return e.err
+}
+
+// IsRejection reports whether err, or any error it wraps, is a rejection by a CircuitBreaker
+// rather than an error returned from the request.
+func IsRejection(err error) bool {
+	return RejectionReason(err) != nil
+}
+
+// RejectionReason returns the sentinel error describing why the CircuitBreaker rejected the request,
+// such as ErrOpenState, ErrTooManyRequests, ErrThrottled or ErrInsufficientBudget.
+// RejectionReason returns nil if err is not a rejection.
+func RejectionReason(err error) error {
+	var re *RejectionError
+	if errors.As(err, &re) {
+		return re.err
+	}
+
+	for _, reason := range rejectionReasons {
+		if errors.Is(err, reason) {
+			return reason
+		}
+	}
+	return nil
+}
+
+// rejectionReasons are the sentinel errors of the rejections.
+var rejectionReasons = []error{ErrOpenState, ErrTooManyRequests, ErrThrottled, ErrInsufficientBudget}
+
+// IsCircuitError is the same as IsRejection: it reports whether err is a rejection by a CircuitBreaker,
+// so that retry logic doesn't have to check each of the sentinel errors.
+func IsCircuitError(err error) bool {
+	return IsRejection(err)
+}
+
+// Category is a type that represents how a caller should treat a rejection by a CircuitBreaker.
+type Category int
+
+// These constants are categories of the errors returned by CircuitBreaker.
+//
+// CategoryNotRejected is an error returned from the request, or no error.
+// CategoryOpen is a rejection by ErrOpenState: the dependency is considered down until RejectionError.RetryAfter.
+// CategoryThrottled is a rejection by ErrTooManyRequests or ErrThrottled: the load is being shed,
+// so the request may be retried shortly. Settings.HalfOpenWait makes a request wait for a probe instead.
+// CategoryRejected is any other rejection, such as ErrInsufficientBudget, which is not worth retrying.
+const (
+	CategoryNotRejected Category = iota
+	CategoryOpen
+	CategoryThrottled
+	CategoryRejected
+)
+
+// String implements stringer interface.
+func (c Category) String() string {
+	switch c {
+	case CategoryNotRejected:
+		return "not-rejected"
+	case CategoryOpen:
+		return "open"
+	case CategoryThrottled:
+		return "throttled"
+	case CategoryRejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("unknown category: %d", c)
+	}
+}
+
+// RejectionCategory returns the Category of err.
+func RejectionCategory(err error) Category {
+	switch RejectionReason(err) {
+	case nil:
+		return CategoryNotRejected
+	case ErrOpenState:
+		return CategoryOpen
+	case ErrTooManyRequests, ErrThrottled:
+		return CategoryThrottled
+	default:
+		return CategoryRejected
+	}
+}
+
+// BreakerError wraps an error returned from a request with the CircuitBreaker that ran the request
+// when Settings.WrapErrors is true, so that the error identifies the CircuitBreaker in the logs.
+// Name and State are the name of the CircuitBreaker and its state when the request was allowed.
+// errors.Is and errors.As see the error returned from the request through BreakerError.
+type BreakerError struct {
+	Name  string
+	State State
+	Err   error
+}
+
+// Error implements error interface.
+func (e *BreakerError) Error() string {
+	return fmt.Sprintf("%s (breaker=%s state=%s)", e.Err, e.Name, e.State)
+}
+
+// Unwrap returns the error returned from the request.
+func (e *BreakerError) Unwrap() error {
+	return e.Err
+}
+
+// wrapError wraps err, the error returned from the request admitted with adm, by Settings.WrapErrors.
+func (cb *CircuitBreaker) wrapError(adm admission, err error) error {
+	if err == nil || !adm.wrapErrors {
+		return err
+	}
+	return &BreakerError{Name: cb.name, State: adm.state, Err: err}
+}
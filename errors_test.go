@@ -0,0 +1,107 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRejection(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+
+	err := succeed(cb)
+	assert.True(t, IsRejection(err))
+	assert.Equal(t, ErrOpenState, RejectionReason(err))
+
+	wrapped := fmt.Errorf("get user: %w", err)
+	assert.True(t, IsRejection(wrapped))
+	assert.Equal(t, ErrOpenState, RejectionReason(wrapped))
+
+	tooMany := &RejectionError{err: ErrTooManyRequests}
+	assert.True(t, IsRejection(tooMany))
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(tooMany))
+
+	callErr := errors.New("connection refused")
+	assert.False(t, IsRejection(callErr))
+	assert.Nil(t, RejectionReason(callErr))
+	assert.False(t, IsRejection(nil))
+	assert.Nil(t, RejectionReason(nil))
+
+	assert.True(t, IsRejection(ErrOpenState))
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(fmt.Errorf("wrapped: %w", ErrTooManyRequests)))
+}
+
+func TestRejectionCategory(t *testing.T) {
+	assert.Equal(t, CategoryNotRejected, RejectionCategory(nil))
+	assert.Equal(t, CategoryNotRejected, RejectionCategory(errors.New("connection refused")))
+	assert.Equal(t, CategoryOpen, RejectionCategory(fmt.Errorf("wrapped: %w", &RejectionError{err: ErrOpenState})))
+	assert.Equal(t, CategoryThrottled, RejectionCategory(&RejectionError{err: ErrTooManyRequests}))
+	assert.Equal(t, CategoryThrottled, RejectionCategory(ErrThrottled))
+	assert.Equal(t, CategoryRejected, RejectionCategory(ErrInsufficientBudget))
+
+	assert.True(t, IsCircuitError(ErrTooManyRequests))
+	assert.False(t, IsCircuitError(errors.New("connection refused")))
+	assert.Equal(t, "throttled", CategoryThrottled.String())
+	assert.Equal(t, "unknown category: 9", Category(9).String())
+}
+
+func TestWrapErrors(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "wrap", WrapErrors: true})
+
+	_, err := cb.Execute(func() (interface{}, error) { return nil, statusError(503) })
+	assert.Equal(t, "status error (breaker=wrap state=closed)", err.Error())
+	var se statusError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, statusError(503), se)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	_, err = cb.Execute(func() (interface{}, error) { return nil, errNotFound })
+	assert.True(t, errors.Is(err, errNotFound))
+	var be *BreakerError
+	assert.True(t, errors.As(err, &be))
+	assert.Equal(t, "wrap", be.Name)
+	assert.Equal(t, StateClosed, be.State)
+
+	_, err = cb.Execute(func() (interface{}, error) { return nil, nil })
+	assert.Nil(t, err)
+
+	cb.Trip()
+	_, err = cb.Execute(func() (interface{}, error) { return nil, nil })
+	assert.False(t, errors.As(err, &be))
+	assert.True(t, errors.Is(err, ErrOpenState))
+}
+
+func TestRejectedError(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	var rejected error
+	cb := NewCircuitBreaker(Settings{
+		Name: "custom",
+		RejectedError: func(name string, state State) error {
+			if state == StateHalfOpen {
+				return nil
+			}
+			return fmt.Errorf("%s is %s: %w", name, state, errUnavailable)
+		},
+		OnReject: func(_ string, _ State, err error) { rejected = err },
+	})
+
+	cb.Trip()
+	_, err := cb.Execute(func() (interface{}, error) { return nil, nil })
+	assert.Equal(t, "custom is open: unavailable", err.Error())
+	assert.True(t, errors.Is(err, errUnavailable))
+	assert.False(t, IsRejection(err))
+	assert.True(t, errors.Is(rejected, ErrOpenState))
+
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	_, err = cb.Execute(func() (interface{}, error) {
+		_, err := cb.Execute(func() (interface{}, error) { return nil, nil })
+		return nil, err
+	})
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+}
@@ -0,0 +1,106 @@
+package gobreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType is a type that represents a kind of Event.
+type EventType int
+
+// These constants are kinds of Event.
+const (
+	EventStateChange EventType = iota
+	EventRejection
+	EventProbeResult
+	EventDowntimeBudgetExceeded
+)
+
+// String implements stringer interface.
+func (t EventType) String() string {
+	switch t {
+	case EventStateChange:
+		return "state-change"
+	case EventRejection:
+		return "rejection"
+	case EventProbeResult:
+		return "probe-result"
+	case EventDowntimeBudgetExceeded:
+		return "downtime-budget-exceeded"
+	default:
+		return fmt.Sprintf("unknown event type: %d", t)
+	}
+}
+
+// Event describes an activity of CircuitBreaker delivered to subscribers.
+//
+// State is the state of the CircuitBreaker when the Event occurred.
+// From and To are set for EventStateChange.
+// Counts is the snapshot of the internal Counts; for EventStateChange, it is taken just before the state change.
+// Err is the rejection error for EventRejection.
+// Success is the outcome of the probe request for EventProbeResult.
+// Downtime is the cumulative downtime for EventDowntimeBudgetExceeded.
+type Event struct {
+	Type       EventType
+	Name       string
+	Time       time.Time
+	State      State
+	From       State
+	To         State
+	Counts     Counts
+	Generation uint64
+	Err        error
+	Success    bool
+	Downtime   time.Duration
+}
+
+// EventBufferSize is the capacity of the channels returned by Subscribe.
+const EventBufferSize = 64
+
+// Subscribe returns a channel that receives the Events of the CircuitBreaker.
+// The CircuitBreaker never blocks on a subscriber: if the channel is full, the Event is dropped.
+// Call Unsubscribe to stop the delivery and close the channel.
+// The channels are closed by Close, and a channel returned after Close is closed already.
+func (cb *CircuitBreaker) Subscribe() <-chan Event {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	ch := make(chan Event, EventBufferSize)
+	if cb.closed {
+		close(ch)
+		return ch
+	}
+	cb.subscribers = append(cb.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops the delivery of the Events to the channel returned by Subscribe and closes it.
+func (cb *CircuitBreaker) Unsubscribe(ch <-chan Event) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	for i, sub := range cb.subscribers {
+		if (<-chan Event)(sub) == ch {
+			cb.subscribers = append(cb.subscribers[:i], cb.subscribers[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+func (cb *CircuitBreaker) publish(ev Event) {
+	if len(cb.subscribers) == 0 {
+		return
+	}
+
+	ev.Name = cb.name
+	ev.Generation = cb.generation
+	for _, sub := range cb.subscribers {
+		select {
+		case sub <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventTypeString(t *testing.T) {
+	assert.Equal(t, "state-change", EventStateChange.String())
+	assert.Equal(t, "rejection", EventRejection.String())
+	assert.Equal(t, "probe-result", EventProbeResult.String())
+	assert.Equal(t, "unknown event type: 100", EventType(100).String())
+}
+
+func TestSubscribe(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "sub"})
+	ch := cb.Subscribe()
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	ev := <-ch
+	assert.Equal(t, EventStateChange, ev.Type)
+	assert.Equal(t, "sub", ev.Name)
+	assert.Equal(t, StateClosed, ev.From)
+	assert.Equal(t, StateOpen, ev.To)
+	assert.Equal(t, Counts{6, 0, 6, 0, 6}, ev.Counts)
+
+	assert.Error(t, succeed(cb))
+	ev = <-ch
+	assert.Equal(t, EventRejection, ev.Type)
+	assert.Equal(t, StateOpen, ev.State)
+	assert.True(t, errors.Is(ev.Err, ErrOpenState))
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, succeed(cb))
+	ev = <-ch
+	assert.Equal(t, EventStateChange, ev.Type)
+	assert.Equal(t, StateHalfOpen, ev.To)
+	ev = <-ch
+	assert.Equal(t, EventProbeResult, ev.Type)
+	assert.True(t, ev.Success)
+	ev = <-ch
+	assert.Equal(t, EventStateChange, ev.Type)
+	assert.Equal(t, StateClosed, ev.To)
+
+	cb.Unsubscribe(ch)
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.Equal(t, 0, len(cb.subscribers))
+}
+
+func TestSubscribeDropsWhenFull(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	ch := cb.Subscribe()
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	for i := 0; i < EventBufferSize*2; i++ {
+		assert.Error(t, succeed(cb))
+	}
+	assert.Equal(t, EventBufferSize, len(ch))
+}
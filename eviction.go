@@ -0,0 +1,106 @@
+package gobreaker
+
+import (
+	"container/list"
+	"time"
+)
+
+// GroupEviction configures the eviction of the CircuitBreakers of a Group created by NewGroupWithEviction,
+// so that a Group of CircuitBreakers per dynamic key, e.g. per user or per URL, doesn't grow unboundedly.
+//
+// IdleTTL, if more than 0, evicts a CircuitBreaker that hasn't been used by Get, Execute, Preload or Add for IdleTTL.
+// MaxSize, if more than 0, evicts the least recently used CircuitBreakers while the Group has more than MaxSize.
+// The evictions take place when the Group is used, and the clock of the Settings of the Group measures IdleTTL.
+//
+// OnEvict, if not nil, is called with every evicted CircuitBreaker, without the lock of the Group.
+// An evicted CircuitBreaker is closed by Close after OnEvict.
+// The CircuitBreakers removed by Remove or Sync are not evicted.
+type GroupEviction struct {
+	IdleTTL time.Duration
+	MaxSize int
+	OnEvict func(name string, cb *CircuitBreaker)
+}
+
+// groupUse is the last use of a CircuitBreaker in the LRU list of a Group.
+type groupUse struct {
+	name     string
+	lastUsed time.Time
+}
+
+// NewGroupWithEviction is like NewGroup but evicts the CircuitBreakers as configured by ev.
+func NewGroupWithEviction(st Settings, ev GroupEviction) *Group {
+	g := NewGroup(st)
+	g.eviction = ev
+	g.clock = st.Clock
+	if g.clock == nil {
+		g.clock = systemClock{}
+	}
+	g.lru = list.New()
+	g.uses = make(map[string]*list.Element)
+	return g
+}
+
+// touch marks the CircuitBreaker of name as the most recently used.
+func (g *Group) touch(name string) {
+	if g.lru == nil {
+		return
+	}
+
+	now := g.clock.Now()
+	if e, ok := g.uses[name]; ok {
+		e.Value.(*groupUse).lastUsed = now
+		g.lru.MoveToFront(e)
+		return
+	}
+	g.uses[name] = g.lru.PushFront(&groupUse{name: name, lastUsed: now})
+}
+
+// forget removes name from the LRU list and stops notifying the dependents of name.
+func (g *Group) forget(name string) {
+	g.unwatch(name)
+	if g.lru == nil {
+		return
+	}
+
+	if e, ok := g.uses[name]; ok {
+		g.lru.Remove(e)
+		delete(g.uses, name)
+	}
+}
+
+// evict removes the idle and the least recently used CircuitBreakers, and returns them by name.
+func (g *Group) evict() map[string]*CircuitBreaker {
+	if g.lru == nil {
+		return nil
+	}
+
+	var evicted map[string]*CircuitBreaker
+	now := g.clock.Now()
+	for e := g.lru.Back(); e != nil; e = g.lru.Back() {
+		use := e.Value.(*groupUse)
+		idle := g.eviction.IdleTTL > 0 && now.Sub(use.lastUsed) >= g.eviction.IdleTTL
+		full := g.eviction.MaxSize > 0 && g.lru.Len() > g.eviction.MaxSize
+		if !idle && !full {
+			break
+		}
+
+		if evicted == nil {
+			evicted = make(map[string]*CircuitBreaker)
+		}
+		evicted[use.name] = g.breakers[use.name]
+		delete(g.breakers, use.name)
+		g.forget(use.name)
+	}
+	return evicted
+}
+
+// notifyEvicted calls OnEvict with the CircuitBreakers returned by evict, and closes them.
+// notifyEvicted must be called without the lock.
+func (g *Group) notifyEvicted(evicted map[string]*CircuitBreaker) {
+	for name, cb := range evicted {
+		if g.eviction.OnEvict != nil {
+			g.eviction.OnEvict(name, cb)
+		}
+		cb.Close()
+	}
+}
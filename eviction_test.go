@@ -0,0 +1,51 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupEviction(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var evicted []string
+	var g *Group
+	g = NewGroupWithEviction(Settings{Clock: clock}, GroupEviction{
+		IdleTTL: time.Minute,
+		MaxSize: 3,
+		OnEvict: func(name string, cb *CircuitBreaker) {
+			assert.Equal(t, name, cb.Name())
+			assert.NotContains(t, g.Names(), name)
+			assert.False(t, isClosed(cb))
+			evicted = append(evicted, name)
+		},
+	})
+
+	g.Preload("a", "b", "c")
+	assert.Nil(t, evicted)
+	b, _ := g.Lookup("b")
+	g.Get("a")
+	g.Get("d")
+	assert.Equal(t, []string{"b"}, evicted)
+	assert.True(t, isClosed(b))
+	assert.Equal(t, []string{"a", "c", "d"}, g.Names())
+
+	evicted = nil
+	clock.advance(time.Duration(30) * time.Second)
+	g.Get("a")
+	clock.advance(time.Duration(30) * time.Second)
+	_, err := g.Execute("e", func() (interface{}, error) { return nil, nil })
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"c", "d"}, evicted)
+	assert.Equal(t, []string{"a", "e"}, g.Names())
+
+	evicted = nil
+	e := g.Get("e")
+	g.Remove("a")
+	g.Sync([]string{"f"})
+	assert.True(t, isClosed(e))
+	assert.Nil(t, evicted)
+	assert.Equal(t, []string{"f"}, g.Names())
+	assert.Len(t, g.uses, 1)
+}
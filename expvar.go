@@ -0,0 +1,48 @@
+package gobreaker
+
+import (
+	"expvar"
+	"sync"
+)
+
+// ExpvarName is the name of the expvar.Map where CircuitBreakers with Settings.PublishExpvar are published.
+const ExpvarName = "gobreaker"
+
+var (
+	expvarOnce     sync.Once
+	expvarBreakers *expvar.Map
+)
+
+// expvarStatus is the value published for each CircuitBreaker in the expvar.Map.
+type expvarStatus struct {
+	State                string `json:"state"`
+	Requests             uint32 `json:"requests"`
+	Successes            uint32 `json:"successes"`
+	Failures             uint32 `json:"failures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
+}
+
+// publishExpvar publishes the state and Counts of the CircuitBreaker as ExpvarName.<name>,
+// replacing a CircuitBreaker previously published with the same name.
+func (cb *CircuitBreaker) publishExpvar() {
+	expvarOnce.Do(func() {
+		expvarBreakers = expvar.NewMap(ExpvarName)
+	})
+
+	expvarBreakers.Set(cb.name, expvar.Func(func() interface{} {
+		cb.mutex.Lock()
+		defer cb.mutex.Unlock()
+
+		state, _ := cb.currentState(cb.clock.Now())
+		counts := cb.counts.snapshot()
+		return expvarStatus{
+			State:                state.String(),
+			Requests:             counts.Requests,
+			Successes:            counts.TotalSuccesses,
+			Failures:             counts.TotalFailures,
+			ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+			ConsecutiveFailures:  counts.ConsecutiveFailures,
+		}
+	}))
+}
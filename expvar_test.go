@@ -0,0 +1,29 @@
+package gobreaker
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	NewCircuitBreaker(Settings{Name: "expvar", PublishExpvar: true})
+	cb := NewCircuitBreaker(Settings{Name: "expvar", PublishExpvar: true}) // replaces
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+
+	m, ok := expvar.Get(ExpvarName).(*expvar.Map)
+	assert.True(t, ok)
+
+	var status map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(m.Get("expvar").String()), &status))
+	assert.Equal(t, "closed", status["state"])
+	assert.Equal(t, float64(2), status["requests"])
+	assert.Equal(t, float64(1), status["successes"])
+	assert.Equal(t, float64(1), status["failures"])
+
+	NewCircuitBreaker(Settings{Name: "unpublished"})
+	assert.Nil(t, m.Get("unpublished"))
+}
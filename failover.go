@@ -0,0 +1,38 @@
+package gobreaker
+
+// Replica is a replica of a dependency guarded by its own CircuitBreaker.
+type Replica struct {
+	Breaker *CircuitBreaker
+	Request func() (interface{}, error)
+}
+
+// ExecuteFailover runs the requests of the replicas in order until one of them succeeds,
+// e.g. for client-side failover from a primary to its secondaries.
+// A replica is skipped if its CircuitBreaker rejects the request,
+// and the next replica is tried if the request fails as classified by the CircuitBreaker of the replica.
+// A request whose error is ignored by Settings.Classifier is not retried on the next replica.
+// The outcome of every request is recorded by the CircuitBreaker of its replica.
+//
+// ExecuteFailover returns the result of the first successful request.
+// If no request succeeds, ExecuteFailover returns the error of the last replica,
+// which is a *RejectionError if the last replica rejected the request.
+// If a panic occurs in a request, the panic propagates without trying the other replicas.
+func ExecuteFailover(replicas ...Replica) (interface{}, error) {
+	var result interface{}
+	var err error
+	for _, r := range replicas {
+		var adm admission
+		adm, err = r.Breaker.beforeRequest(callOptions{})
+		if err != nil {
+			result = nil
+			continue
+		}
+
+		var v Verdict
+		result, v, err = r.Breaker.runVerdict(adm, r.Request)
+		if v != Failure {
+			return result, err
+		}
+	}
+	return result, err
+}
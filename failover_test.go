@@ -0,0 +1,69 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteFailover(t *testing.T) {
+	primary := NewCircuitBreaker(Settings{Name: "primary"})
+	secondary := NewCircuitBreaker(Settings{Name: "secondary"})
+	tertiary := NewCircuitBreaker(Settings{
+		Name:         "tertiary",
+		IsSuccessful: func(err error) bool { return err == nil || err == errNotFound },
+	})
+
+	errDown := errors.New("down")
+	var called []string
+	replica := func(cb *CircuitBreaker, result interface{}, err error) Replica {
+		return Replica{Breaker: cb, Request: func() (interface{}, error) {
+			called = append(called, cb.Name())
+			return result, err
+		}}
+	}
+
+	res, err := ExecuteFailover(replica(primary, nil, errDown), replica(secondary, "b", nil), replica(tertiary, "c", nil))
+	assert.Equal(t, "b", res)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"primary", "secondary"}, called)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, primary.Counts())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, secondary.Counts())
+	assert.Equal(t, Counts{}, tertiary.Counts())
+
+	called = nil
+	primary.Trip()
+	res, err = ExecuteFailover(replica(primary, "a", nil), replica(secondary, nil, errDown), replica(tertiary, nil, errNotFound))
+	assert.Nil(t, res)
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, []string{"secondary", "tertiary"}, called)
+
+	secondary.Trip()
+	tertiary.Trip()
+	_, err = ExecuteFailover(replica(primary, "a", nil), replica(secondary, "b", nil), replica(tertiary, "c", nil))
+	assert.Equal(t, ErrOpenState, RejectionReason(err))
+	assert.Equal(t, "tertiary", err.(*RejectionError).Name)
+}
+
+func TestExecuteFailoverIsSuccessfulResult(t *testing.T) {
+	var classified int
+	primary := NewCircuitBreaker(Settings{
+		Name: "primary",
+		IsSuccessfulResult: func(result interface{}, err error) bool {
+			classified++
+			return err == nil && result != "failed"
+		},
+	})
+	secondary := NewCircuitBreaker(Settings{Name: "secondary"})
+
+	res, err := ExecuteFailover(
+		Replica{Breaker: primary, Request: func() (interface{}, error) { return "failed", nil }},
+		Replica{Breaker: secondary, Request: func() (interface{}, error) { return "ok", nil }},
+	)
+	assert.Equal(t, "ok", res)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, classified)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, primary.Counts())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, secondary.Counts())
+}
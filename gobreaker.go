@@ -3,9 +3,15 @@
 package gobreaker
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,6 +30,12 @@ var (
 	ErrTooManyRequests = errors.New("too many requests")
 	// ErrOpenState is returned when the CB state is open
 	ErrOpenState = errors.New("circuit breaker is open")
+	// ErrThrottled is returned when the CB is in ModeAdaptive and rejects the request by the throttling probability,
+	// or when the StateMachine rejects the request in a custom state
+	ErrThrottled = errors.New("request throttled")
+	// ErrInsufficientBudget is returned when the remaining time until the deadline of the request
+	// is shorter than Settings.MinRemainingBudget
+	ErrInsufficientBudget = errors.New("insufficient time budget")
 )
 
 // String implements stringer interface.
@@ -40,16 +52,98 @@ func (s State) String() string {
 	}
 }
 
+// ParseState returns the State named s, i.e. "closed", "half-open" or "open".
+// A custom state of StateMachine is parsed from its number.
+func ParseState(s string) (State, error) {
+	switch s {
+	case StateClosed.String():
+		return StateClosed, nil
+	case StateHalfOpen.String():
+		return StateHalfOpen, nil
+	case StateOpen.String():
+		return StateOpen, nil
+	}
+
+	if n, err := strconv.Atoi(s); err == nil && isCustomState(State(n)) {
+		return State(n), nil
+	}
+	return StateClosed, fmt.Errorf("unknown state: %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// A custom state of StateMachine is marshaled as its number.
+func (s State) MarshalText() ([]byte, error) {
+	if isCustomState(s) {
+		return []byte(strconv.Itoa(int(s))), nil
+	}
+	if s < StateClosed {
+		return nil, fmt.Errorf("unknown state: %d", s)
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *State) UnmarshalText(text []byte) error {
+	state, err := ParseState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s State) MarshalJSON() ([]byte, error) {
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// A number is accepted as well, which is how State was marshaled before it implemented json.Marshaler.
+func (s *State) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*s = State(n)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return s.UnmarshalText([]byte(text))
+}
+
+// StateChangeEvent describes a state change of CircuitBreaker.
+// Counts is the snapshot of the internal Counts just before they are cleared by the state change.
+// Generation is the generation that starts with the state change.
+// Cause is why the state has changed.
+// RecentErrors are the latest errors returned from the requests if Settings.RecentErrorsSize is more than 0,
+// e.g. to include examples of the errors in an alert when the CircuitBreaker trips.
+type StateChangeEvent struct {
+	Name         string
+	From         State
+	To           State
+	Time         time.Time
+	Counts       Counts
+	Generation   uint64
+	Cause        TransitionCause
+	RecentErrors []ErrorRecord
+}
+
 // Counts holds the numbers of requests and their successes/failures.
 // CircuitBreaker clears the internal Counts either
 // on the change of the state or at the closed-state intervals.
 // Counts ignores the results of the requests sent before clearing.
 type Counts struct {
-	Requests             uint32
-	TotalSuccesses       uint32
-	TotalFailures        uint32
-	ConsecutiveSuccesses uint32
-	ConsecutiveFailures  uint32
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"totalSuccesses"`
+	TotalFailures        uint32 `json:"totalFailures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
 }
 
 func (c *Counts) onRequest() {
@@ -86,7 +180,33 @@ func (c *Counts) clear() {
 //
 // Interval is the cyclic period of the closed state
 // for the CircuitBreaker to clear the internal Counts.
-// If Interval is less than or equal to 0, the CircuitBreaker doesn't clear internal Counts during the closed state.
+// If Interval is less than or equal to 0, IntervalPolicy decides whether the CircuitBreaker clears internal Counts.
+//
+// IntervalPolicy is the meaning of Interval less than or equal to 0. See IntervalPolicy.
+// If IntervalPolicy is IntervalNever, the CircuitBreaker doesn't clear internal Counts during the closed state.
+//
+// CountResetThreshold, if more than 0, makes the CircuitBreaker clear the internal Counts during the closed state
+// once the outcomes of CountResetThreshold requests are counted, in addition to every Interval,
+// e.g. for bursty traffic where a time-based Interval either keeps stale outcomes or gets too few samples.
+// ReadyToTrip sees every outcome before the internal Counts are cleared.
+//
+// BeforeStateChange, if not nil, is called before every automatic state change with the Counts that caused it,
+// and vetoes the state change by returning false, e.g. to keep the CircuitBreaker from closing during
+// a deploy freeze window. The manual operations such as Trip, Reset and Disable are never vetoed.
+// A vetoed transition from the open state is evaluated again after another Timeout
+// or another HealthCheckSuccesses successful health checks,
+// and a vetoed transition from the half-open state after another round of probes.
+//
+// MinimumRequestThreshold, if more than 0, is the minimum number of requests in the current closed generation
+// before the CircuitBreaker evaluates ReadyToTrip and its variants, including the custom ones,
+// so that the CircuitBreaker never trips on the first few requests after startup or after the Counts are cleared.
+//
+// Logger, if not nil, logs the state changes, the probe results and the changes of the settings at runtime.
+// See Logger.
+//
+// HistorySize is the number of the latest past generations kept with their state, start and end times
+// and the Counts at their end. See History.
+// If HistorySize is 0, the generations are not recorded.
 //
 // Timeout is the period of the open state,
 // after which the state of the CircuitBreaker becomes half-open.
@@ -103,31 +223,567 @@ func (c *Counts) clear() {
 // If IsSuccessful returns true, the error is counted as a success.
 // Otherwise the error is counted as a failure.
 // If IsSuccessful is nil, default IsSuccessful is used, which returns false for all non-nil errors.
+//
+// ReadyToTripExternal is called with the reason and a copy of Counts
+// whenever an external failure is reported by ReportExternalFailure in the closed state.
+// If ReadyToTripExternal returns true, the CircuitBreaker will be placed into the open state.
+// If ReadyToTripExternal is nil, ReadyToTrip is used instead.
+//
+// ReadyToClose is called with a copy of Counts whenever a request succeeds in the half-open state.
+// If ReadyToClose returns true, the CircuitBreaker will be placed into the closed state.
+// If ReadyToClose is nil, default ReadyToClose is used.
+// Default ReadyToClose returns true when the number of consecutive successes reaches MaxRequests.
+//
+// ReadyToReopen is called with a copy of Counts whenever a request fails in the half-open state.
+// If ReadyToReopen returns true, the CircuitBreaker will be placed into the open state.
+// If ReadyToReopen is nil, the CircuitBreaker reopens on the first failure.
+// Regardless of ReadyToClose and ReadyToReopen, the CircuitBreaker reopens
+// when all of the MaxRequests requests have completed without closing it.
+//
+// OnStateChangeDetailed is called with a StateChangeEvent whenever the state of the CircuitBreaker changes,
+// after OnStateChange. Unlike OnStateChange, it receives the Counts that caused the state change.
+//
+// Schedule is called with the current time at the start of every generation, and the returned Thresholds
+// override MaxRequests and ReadyToTrip for that generation, e.g. to be more sensitive at peak hours.
+// The zero fields of the returned Thresholds mean the values of Settings.
+// If Schedule is nil, MaxRequests and ReadyToTrip are always used.
+//
+// HealthCheck, if not nil, actively probes the dependency in the background while the CircuitBreaker is open.
+// HealthCheck is called every HealthCheckInterval with a context that expires after HealthCheckInterval,
+// and the CircuitBreaker is placed into the closed state after HealthCheckSuccesses consecutive successful checks.
+// In that case, the CircuitBreaker never becomes half-open, so live requests are never used as probes.
+// If HealthCheckInterval is less than or equal to 0, the interval is set to 1 second.
+// If HealthCheckSuccesses is 0, a single successful check closes the CircuitBreaker.
+//
+// DisablePanicRecovery, if true, makes Execute not recover and re-raise a panic in the request,
+// so the panic propagates with its original stack trace, e.g. to the caller's own panic middleware.
+// The request is still counted as a failure.
+//
+// OnCallComplete is called after every request run by Execute or ExecuteContext completes,
+// with the duration of the request, the error returned from it,
+// and the state of the CircuitBreaker when the request was allowed.
+// OnCallComplete is not called for rejected requests or for requests that panic.
+//
+// DowntimeBudget is the allowed cumulative time of the open state during DowntimeWindow.
+// OnDowntimeBudgetExceeded is called with the cumulative downtime when the downtime crosses DowntimeBudget,
+// and an EventDowntimeBudgetExceeded is published to subscribers.
+// The alert fires again only after the downtime has fallen below the budget and crossed it again.
+// If DowntimeBudget is less than or equal to 0, the downtime is not checked.
+//
+// PublishExpvar, if true, publishes the state and Counts of the CircuitBreaker via expvar
+// as ExpvarName.<Name>, e.g. gobreaker.<Name>.state and gobreaker.<Name>.failures.
+// A CircuitBreaker published later with the same Name replaces the earlier one.
+//
+// HealthReporter, if not nil, is notified when the CircuitBreaker trips and recovers.
+// See HealthReporter.
+//
+// Parent, if not nil, nests the CircuitBreaker under another CircuitBreaker,
+// e.g. a per-endpoint CircuitBreaker under a per-host CircuitBreaker.
+// A request must be allowed by the parent before the CircuitBreaker itself,
+// so tripping the parent rejects the requests of all its children with the parent's RejectionError.
+// The outcome of every request of the CircuitBreaker is also counted by the parent.
+// Parent is fixed at construction; UpdateSettings ignores it.
+//
+// IdempotentProbes is the number of the MaxRequests requests in the half-open state
+// reserved for the requests declared idempotent with WithIdempotent,
+// so that retry-safe requests are preferred as probes.
+// The other requests are allowed only while fewer than MaxRequests - IdempotentProbes requests have been allowed.
+// If IdempotentProbes is 0, all requests are treated equally.
+//
+// CounterShards, if more than 0, is the number of shards to count the successes in the closed state,
+// so that concurrent successful requests don't contend for the lock of the CircuitBreaker.
+// The sharded successes are added to Counts whenever the CircuitBreaker evaluates its state,
+// e.g. on a failure, so ConsecutiveSuccesses and ConsecutiveFailures are approximate under concurrency.
+// A good value is runtime.GOMAXPROCS(0) for a CircuitBreaker serving many goroutines.
+// While the CircuitBreaker is closed and its interval hasn't expired,
+// the requests are also allowed and counted without the lock.
+// If CounterShards is 0, every request and outcome is counted under the lock.
+//
+// AuditLogSize is the number of the latest manual operations, such as Trip and Reset,
+// kept with the state and the Counts before them, so that a Reset doesn't lose the evidence of
+// what the CircuitBreaker saw. See AuditLog.
+// If AuditLogSize is 0, manual operations are not recorded.
+//
+// Mode selects how the CircuitBreaker rejects requests. See Mode.
+// In ModeAdaptive, the rejected requests fail with ErrThrottled, the CircuitBreaker never trips by itself,
+// and CounterShards is not used.
+// In ModeHighThroughput, the failures in the closed state are counted by the shards as well,
+// so they are not included in Stats or TripDump, and ReadyToTripWithMetadata is called with nil Metadata.
+// If CounterShards is 0 in ModeHighThroughput, CounterShards is set to runtime.GOMAXPROCS(0).
+//
+// AdaptiveK is the multiplier K of ModeAdaptive. A lower K rejects requests more aggressively.
+// If AdaptiveK is less than or equal to 0, AdaptiveK is set to 2.
+//
+// AdaptiveWindow is the period over which ModeAdaptive counts the requests and accepts.
+// If AdaptiveWindow is less than or equal to 0, AdaptiveWindow is set to 2 minutes.
+//
+// ShedNonIdempotentFirst decides which requests ModeAdaptive sheds first.
+// By default, the requests declared with WithIdempotent are shed first because they are safe to retry,
+// and the other requests are shed only when that isn't enough.
+// If ShedNonIdempotentFirst is true, the other requests are shed first instead.
+//
+// HalfOpenProbeInterval, if more than 0, spreads the probes of the half-open state over time.
+// After a probe is allowed, the next probe is allowed only after a random delay
+// between HalfOpenProbeInterval / 2 and HalfOpenProbeInterval * 3 / 2,
+// so that a burst of requests can't instantly re-trip or falsely close the CircuitBreaker.
+// The requests arriving before the delay are rejected with ErrTooManyRequests.
+// If HalfOpenProbeInterval is 0, up to MaxRequests probes are allowed at once.
+//
+// CallTimeout, if more than 0, is the timeout of the context passed to the requests run by ExecuteContext.
+// A single call can override it with WithCallTimeout.
+// If EnforceCallTimeout is true, the CircuitBreaker also enforces CallTimeout on the requests run by Execute
+// and its variants, which then run in a new goroutine: a request that doesn't return within CallTimeout
+// is counted as ErrCallTimeout returned from the request at once, and its result is discarded when it returns,
+// so that the Counts aren't held hostage by a hung request. A single call can enforce a timeout with WithEnforcedTimeout.
+// A panic in a request with an enforced timeout crashes the program, like ExecuteAsync.
+//
+// OnCallCompleteDetailed is like OnCallComplete but is called with a CallCompleteEvent,
+// which also carries the effective timeout and the Metadata of the request.
+//
+// IsSuccessfulWithMetadata, if not nil, is used instead of IsSuccessful
+// and is called with the Metadata given to the request by WithMetadata,
+// so that a single CircuitBreaker wrapping a client can classify the outcomes differently per operation.
+//
+// ReadyToTripWithMetadata, if not nil, is used instead of ReadyToTrip
+// and is called with the Metadata of the failed request.
+//
+// IsPanicFailure is called with the value recovered from a panic in the request.
+// If IsPanicFailure returns true, the panic is counted as a failure.
+// Otherwise the request is not counted at all, e.g. for a programmer-error panic
+// that says nothing about the health of the dependency.
+// The panic is raised again in either case.
+// If IsPanicFailure is nil, every panic is counted as a failure.
+// IsPanicFailure is not used if DisablePanicRecovery is true.
+//
+// Clock is the source of time of the CircuitBreaker, e.g. a fake Clock for tests and simulations.
+// If Clock is nil, the system time is used.
+// Clock is fixed at construction; UpdateSettings ignores it.
+//
+// ReadyToTripWithStats, if not nil, is used instead of ReadyToTrip
+// and is called with a copy of Counts and the Stats of the current generation,
+// e.g. to trip when the requests become too slow even though they succeed eventually.
+// Unlike ReadyToTrip, ReadyToTripWithStats is called whenever a request completes in the closed state,
+// whether it succeeds or fails.
+// ReadyToTripWithMetadata takes precedence over ReadyToTripWithStats.
+//
+// PayloadMetric, if not nil, is called with the result and the error of every request run by Execute
+// or ExecuteContext, and returns a numeric metric of the payload, such as the size of the response,
+// and whether the request has the metric. The metrics are aggregated in Stats,
+// e.g. for ReadyToTripWithStats to trip on a sudden run of empty responses.
+//
+// Classifier, if not nil, is used instead of IsSuccessful, IsSuccessfulWithMetadata and IsSuccessfulResult
+// to classify the error returned from a request, e.g. a Chain of IgnoreErrors, ClassifyStatus and ClassifyFunc.
+// A request classified as Ignored is not counted at all.
+// If Classifier returns Undecided, the request succeeds if the error is nil and fails otherwise.
+//
+// HalfOpenWait, if more than 0, makes a request arriving in the half-open state while all the MaxRequests probes
+// are in flight wait for a probe to complete instead of being rejected with ErrTooManyRequests at once,
+// e.g. for a low-QPS service where a short wait is better than a rejection.
+// The request waits up to HalfOpenWait, or until the context given to ExecuteContext is done,
+// and is then rejected with ErrTooManyRequests if it still can't be allowed.
+//
+// TripDumpSize is the number of the latest completed requests kept with their durations and verdicts,
+// so that a TripDump with them is captured whenever the CircuitBreaker trips. See LastTripDump.
+// If TripDumpSize is 0, the requests are not recorded.
+//
+// CountWhileDisabled, if true, makes the CircuitBreaker keep counting the requests and their outcomes
+// while it is disabled by Disable, e.g. to watch a canary rollout before enabling the CircuitBreaker.
+// Otherwise Counts stay zero while the CircuitBreaker is disabled.
+//
+// OnReject is called whenever the CircuitBreaker rejects a request, with the state of the CircuitBreaker
+// and the *RejectionError returned to the caller, e.g. to log or alert on bursts of rejections,
+// which OnStateChange can't see. A request rejected by the parent CircuitBreaker is reported by OnReject of the parent.
+//
+// OnStaleResult is called with the outcome of a request that completes after the generation it was allowed in
+// has ended, e.g. a long-running request that fails while the CircuitBreaker changes its state.
+// Such an outcome is not counted unless CountStaleResults is true, in which case it is counted
+// as a request of the current generation if the CircuitBreaker is in the closed state.
+// With CounterShards, the stale successes counted without the lock are dropped without calling OnStaleResult.
+//
+// MinRemainingBudget is the minimum time remaining until the deadline of a request for the request to be allowed.
+// The deadline is given by WithDeadline or by the context passed to ExecuteContext.
+// A request with less time left is rejected with ErrInsufficientBudget without being counted,
+// because a request that will certainly time out would only pollute the Counts.
+// If MinRemainingBudget is 0, the deadlines are not checked.
+//
+// HalfOpenIsSuccessful, if not nil, classifies the probes allowed in the half-open state
+// instead of IsSuccessful, IsSuccessfulWithMetadata, IsSuccessfulResult and Classifier,
+// with the duration of the probe and the error returned from it,
+// since a probe usually needs stricter criteria than the steady-state traffic, e.g. a slow success as a failure.
+//
+// BurnRate, if not nil, is used instead of ReadyToTrip, ReadyToTripWithMetadata and ReadyToTripWithStats,
+// and trips the CircuitBreaker when the error budget of the SLO is burning too fast. See BurnRate.
+// CounterShards is not used with BurnRate, because every outcome has to be counted in the windows.
+//
+// ProbeKey, if not nil, deduplicates the concurrent identical probes in the half-open state like singleflight,
+// e.g. when many goroutines probe the same recovering endpoint.
+// ProbeKey is called with the Metadata given to a request run by Execute or ExecuteWithOptions,
+// and the concurrent requests with the same key share one call to the dependency:
+// only the first request is allowed and counted, and the others wait for it and return its result.
+// A request with the empty key is not deduplicated.
+//
+// FailOpenRatio is the fraction of the requests allowed through in the open state as canaries, e.g. 0.01,
+// so that the recovery of the dependency is detected continuously rather than only after Timeout.
+// The canaries are counted in Counts, and a successful canary places the CircuitBreaker into the half-open state.
+// If FailOpenRatio is 0, every request is rejected in the open state.
+//
+// StateMachine, if not nil, extends the transitions with custom states. See StateMachine.
+//
+// HalfOpenMaxTotal, if more than 0, is the number of the probes allowed and evaluated in a generation
+// of the half-open state instead of MaxRequests, and HalfOpenMaxConcurrent, if more than 0,
+// is the maximum number of the probes in flight at a time,
+// e.g. at most 2 probes in flight and the evaluation after 10 probes in total.
+//
+// IsMaintenanceWindow, if not nil, is called with the current time and reports whether a planned maintenance
+// of the dependency is in progress. MaintenancePolicy decides how the failures during the maintenance are handled,
+// so that the callers don't have to swap the CircuitBreakers at runtime. See MaintenancePolicy.
+//
+// ShadowMode, if true, runs the CircuitBreaker as a dry run: the state changes as usual,
+// and OnStateChange, OnReject and the events report what the CircuitBreaker would do,
+// but the requests that would be rejected are allowed through, e.g. to observe new settings in production
+// before enforcing them. The outcomes of those requests are not counted in Counts.
+//
+// HalfOpenSuccessRatio, if more than 0, decides the half-open state by the ratio of the successful probes
+// instead of the consecutive successes, so that a flaky failure among the probes doesn't reopen the CircuitBreaker:
+// once all the probes of HalfOpenMaxTotal, or MaxRequests, have completed, the CircuitBreaker closes
+// if the success ratio is HalfOpenSuccessRatio or more, and reopens otherwise.
+// HalfOpenSuccessRatio is not used with ReadyToClose or ReadyToReopen.
+//
+// Categorize, if not nil, is called with the error of every failed request and returns its category,
+// e.g. the class of the HTTP status or the gRPC code, and the failures of the current generation are counted
+// by category. See CountsByCategory. The failures in the empty category are not counted by category.
+//
+// ReadyToTripByCategory, if not nil, is used instead of ReadyToTrip with the failures counted by Categorize,
+// e.g. to trip on a few 503s but not on 429s.
+// ReadyToTripWithMetadata and ReadyToTripWithStats take precedence over ReadyToTripByCategory.
+//
+// RecoveryRamp, if not nil, ramps the traffic up gradually after the CircuitBreaker closes from the half-open state.
+// See RecoveryRamp.
+//
+// CountRejections, if true, counts the rejected requests in Stats.Rejections.
+//
+// ProbeElector, if not nil, elects one of the replicas sharing a dependency to probe it after the open state,
+// while the other replicas stay open for another Timeout. See ProbeElector.
+// The election takes place on the first request after the open state has expired,
+// so State reports the open state until then.
+//
+// HalfOpenAdmission decides the order in which the requests waiting by HalfOpenWait take the probes.
+// See HalfOpenAdmission.
+//
+// TimeoutJitter, if more than 0, adds a random duration between 0 and TimeoutJitter to Timeout
+// every time the CircuitBreaker enters the open state, so that the CircuitBreakers tripped at the same moment,
+// e.g. by an outage of a region, don't probe their dependencies at the same moment.
+// Every CircuitBreaker has its own random source for TimeoutJitter.
+//
+// WrapErrors, if true, makes Execute wrap the errors returned from the requests in *BreakerError
+// with the name and the state of the CircuitBreaker. ReadyToTrip and IsSuccessful see the errors unwrapped.
+//
+// ShedThresholds, if not empty, sheds the requests by their priorities set by WithPriority or ExecuteWithPriority.
+// In the closed state, a request of a priority in ShedThresholds is rejected with ErrThrottled
+// while the failure rate of the current generation is the threshold of the priority or more,
+// and MinimumRequestThreshold requests or more have completed.
+// In the half-open state, the requests of the priorities in ShedThresholds are rejected with ErrThrottled,
+// so that the probes are left to the other priorities.
+// The requests of the priorities not in ShedThresholds are never shed by their priorities,
+// e.g. {PriorityLow: 0.1, PriorityNormal: 0.3} keeps PriorityHigh allowed until the CircuitBreaker trips.
+//
+// DecayHalfLife, if more than 0, makes ReadyToTrip see the successes and the failures in the closed state
+// decayed exponentially with the half-life of DecayHalfLife instead of those of the current generation,
+// so that a low-traffic CircuitBreaker doesn't lose all the signal when Interval clears the Counts.
+// The decayed counts are rounded to the nearest integers, and cleared when the CircuitBreaker closes.
+// ConsecutiveSuccesses and ConsecutiveFailures are not decayed, and MinimumRequestThreshold applies to the decayed Requests.
+// DecayHalfLife is not used with BurnRate.
+//
+// RejectedError, if not nil, is called with the name and the state of the CircuitBreaker when it rejects a request,
+// and the returned error is returned instead of the *RejectionError, e.g. a gRPC status error of codes.Unavailable.
+// If RejectedError returns nil, the *RejectionError is returned. OnReject still receives the *RejectionError.
+// IsRejection, ExecuteWithRetry and the other helpers recognizing the rejections don't recognize the returned error
+// unless it wraps one of ErrOpenState, ErrTooManyRequests, ErrThrottled or ErrInsufficientBudget.
+//
+// HalfOpenDuration, if more than 0, makes the half-open state a trial period of HalfOpenDuration
+// instead of ending it at the first failure or after the successful probes, e.g. for a low-traffic service
+// that would otherwise reopen for a single unlucky probe. During the trial period, the requests are allowed
+// with up to HalfOpenMaxConcurrent, or MaxRequests, in flight at a time. At the end of the trial period,
+// the CircuitBreaker closes if the success ratio of the requests is HalfOpenSuccessRatio or more,
+// or 0.5 or more if HalfOpenSuccessRatio is 0, and reopens otherwise.
+// If no request has completed in the trial period, another trial period starts.
+// HalfOpenDuration is not used with ReadyToClose or ReadyToReopen.
+//
+// CacheTTL, if more than 0, makes ExecuteCached keep the result of the last successful request per key
+// for CacheTTL, and serve it while the CircuitBreaker rejects the requests, i.e. stale-while-error.
+// CacheSize is the maximum number of the keys, the least recently stored of which are dropped first.
+// If CacheSize is less than or equal to 0, CacheSize is set to 128.
+//
+// IsSuccessfulResult, if not nil, is used instead of IsSuccessful and IsSuccessfulWithMetadata
+// and is also called with the result returned from the request, e.g. to count an *http.Response
+// of status 500 returned with a nil error as a failure.
+// The result is nil for the outcomes reported through TwoStepCircuitBreaker.
+//
+// BurstWindow and BurstThreshold, if both more than 0, trip the CircuitBreaker in the closed state
+// as soon as the failures per second over the last BurstWindow reach BurstThreshold,
+// e.g. on a sudden spike of failures that the ratio of the whole generation doesn't reflect yet,
+// regardless of ReadyToTrip and MinimumRequestThreshold.
+// The failure velocity over BurstWindow is also in Stats.FailureVelocity, e.g. for ReadyToTripWithStats.
+// CounterShards is not used with BurstWindow, because every failure has to be counted in the window.
+//
+// ReadyToTripWithWindow, if not nil, is used instead of ReadyToTrip and is called with the Window
+// of the current and the previous generations whenever a request fails in the closed state,
+// e.g. to trip on Window.Sliding rather than on the Counts just cleared by Interval.
+// ReadyToTripWithWindow is not used with ReadyToTripWithMetadata, ReadyToTripWithStats or BurnRate.
+//
+// InjectFailureRate and InjectLatency inject faults into the requests run by Execute and its variants,
+// e.g. for a game day in staging to drive the CircuitBreaker and the fallbacks through the open and the half-open states
+// without breaking the real dependency. Every request is delayed by InjectLatency, and then,
+// at the probability of InjectFailureRate, fails with ErrInjectedFailure without running the real request.
+// The injected failures are classified and counted like the errors returned from the requests.
+// The requests of TwoStepCircuitBreaker are not injected with faults.
+//
+// MetricsCollector, if not nil, observes the time the CircuitBreaker takes to allow or reject every request
+// and the duration of every completed request. See MetricsCollector and HistogramCollector.
+//
+// RecentErrorsSize is the number of the latest errors returned from the requests kept with their times,
+// which are reported by RecentErrors and StateChangeEvent. If RecentErrorsSize is 0, the errors are not kept.
+// The errors are kept whether they are counted as failures or not.
+//
+// AdmissionPolicy, if not nil, decides whether the CircuitBreaker allows the requests its state allows.
+// See AdmissionPolicy.
+//
+// HalfOpenLatencyBudget, if more than 0, weights the successful probes of the half-open state by their durations,
+// so that the CircuitBreaker doesn't close onto a dependency that works but is much slower than usual:
+// a probe within HalfOpenLatencyBudget counts as a success, a probe within twice HalfOpenLatencyBudget
+// counts as half a success and lets one more probe in, and a slower probe counts as a failure.
+// The CircuitBreaker closes when the weighted successes reach MaxRequests, or HalfOpenMaxTotal.
+// HalfOpenLatencyBudget is not used with ReadyToClose, HalfOpenSuccessRatio or HalfOpenDuration.
 type Settings struct {
-	Name          string
-	MaxRequests   uint32
-	Interval      time.Duration
-	Timeout       time.Duration
-	ReadyToTrip   func(counts Counts) bool
-	OnStateChange func(name string, from State, to State)
-	IsSuccessful  func(err error) bool
+	Name                     string
+	MaxRequests              uint32
+	Interval                 time.Duration
+	Timeout                  time.Duration
+	ReadyToTrip              func(counts Counts) bool
+	OnStateChange            func(name string, from State, to State)
+	IsSuccessful             func(err error) bool
+	ReadyToTripExternal      func(reason string, counts Counts) bool
+	ReadyToClose             func(counts Counts) bool
+	ReadyToReopen            func(counts Counts) bool
+	OnStateChangeDetailed    func(ev StateChangeEvent)
+	Schedule                 func(now time.Time) Thresholds
+	HealthCheck              func(ctx context.Context) error
+	HealthCheckInterval      time.Duration
+	HealthCheckSuccesses     uint32
+	DisablePanicRecovery     bool
+	OnCallComplete           func(name string, d time.Duration, err error, state State)
+	DowntimeBudget           time.Duration
+	OnDowntimeBudgetExceeded func(name string, downtime time.Duration)
+	PublishExpvar            bool
+	HealthReporter           HealthReporter
+	Parent                   *CircuitBreaker
+	IdempotentProbes         uint32
+	CounterShards            int
+	AuditLogSize             int
+	Mode                     Mode
+	AdaptiveK                float64
+	AdaptiveWindow           time.Duration
+	ShedNonIdempotentFirst   bool
+	HalfOpenProbeInterval    time.Duration
+	CallTimeout              time.Duration
+	OnCallCompleteDetailed   func(ev CallCompleteEvent)
+	IsSuccessfulWithMetadata func(md Metadata, err error) bool
+	ReadyToTripWithMetadata  func(md Metadata, counts Counts) bool
+	IsPanicFailure           func(recovered interface{}) bool
+	Clock                    Clock
+	ReadyToTripWithStats     func(counts Counts, stats Stats) bool
+	CountWhileDisabled       bool
+	IntervalPolicy           IntervalPolicy
+	HistorySize              int
+	PayloadMetric            func(result interface{}, err error) (value float64, ok bool)
+	Classifier               Classifier
+	TripDumpSize             int
+	HalfOpenWait             time.Duration
+	OnReject                 func(name string, state State, err error)
+	OnStaleResult            func(name string, success bool)
+	CountStaleResults        bool
+	CountResetThreshold      uint32
+	Logger                   Logger
+	MinimumRequestThreshold  uint32
+	BeforeStateChange        func(name string, from, to State, counts Counts) bool
+	MinRemainingBudget       time.Duration
+	HalfOpenIsSuccessful     func(d time.Duration, err error) bool
+	BurnRate                 *BurnRate
+	ProbeKey                 func(md Metadata) string
+	FailOpenRatio            float64
+	StateMachine             StateMachine
+	HalfOpenMaxConcurrent    uint32
+	HalfOpenMaxTotal         uint32
+	IsMaintenanceWindow      func(now time.Time) bool
+	MaintenancePolicy        MaintenancePolicy
+	ShadowMode               bool
+	HalfOpenSuccessRatio     float64
+	Categorize               func(err error) string
+	ReadyToTripByCategory    func(counts Counts, failures map[string]uint32) bool
+	RecoveryRamp             *RecoveryRamp
+	CountRejections          bool
+	ProbeElector             ProbeElector
+	HalfOpenAdmission        HalfOpenAdmission
+	TimeoutJitter            time.Duration
+	WrapErrors               bool
+	ShedThresholds           map[Priority]float64
+	DecayHalfLife            time.Duration
+	RejectedError            func(name string, state State) error
+	HalfOpenDuration         time.Duration
+	CacheTTL                 time.Duration
+	CacheSize                int
+	IsSuccessfulResult       func(result interface{}, err error) bool
+	BurstWindow              time.Duration
+	BurstThreshold           float64
+	ReadyToTripWithWindow    func(w Window) bool
+	InjectFailureRate        float64
+	InjectLatency            time.Duration
+	EnforceCallTimeout       bool
+	MetricsCollector         MetricsCollector
+	RecentErrorsSize         int
+	AdmissionPolicy          AdmissionPolicy
+	HalfOpenLatencyBudget    time.Duration
+}
+
+// Thresholds holds the parameters of CircuitBreaker that can vary by Settings.Schedule.
+type Thresholds struct {
+	MaxRequests uint32
+	ReadyToTrip func(counts Counts) bool
 }
 
 // CircuitBreaker is a state machine to prevent sending requests that are likely to fail.
+// The zero value is ready to use with the default Settings, which are applied on the first use,
+// so a CircuitBreaker can be embedded as a plain struct field.
+// A CircuitBreaker must not be copied after the first use.
 type CircuitBreaker struct {
-	name          string
-	maxRequests   uint32
-	interval      time.Duration
-	timeout       time.Duration
-	readyToTrip   func(counts Counts) bool
-	isSuccessful  func(err error) bool
-	onStateChange func(name string, from State, to State)
+	parent                   *CircuitBreaker
+	name                     string
+	clock                    Clock
+	maxRequests              uint32
+	interval                 time.Duration
+	timeout                  time.Duration
+	readyToTrip              func(counts Counts) bool
+	isSuccessful             func(err error) bool
+	onStateChange            func(name string, from State, to State)
+	readyToTripExternal      func(reason string, counts Counts) bool
+	readyToClose             func(counts Counts) bool
+	readyToReopen            func(counts Counts) bool
+	onStateChangeDetailed    func(ev StateChangeEvent)
+	schedule                 func(now time.Time) Thresholds
+	unscheduled              Thresholds
+	healthCheck              func(ctx context.Context) error
+	healthCheckInterval      time.Duration
+	healthCheckSuccesses     uint32
+	disablePanicRecovery     bool
+	onCallComplete           func(name string, d time.Duration, err error, state State)
+	downtimeBudget           time.Duration
+	onDowntimeBudgetExceeded func(name string, downtime time.Duration)
+	healthReporter           *healthReporter
+	idempotentProbes         uint32
+	counterShards            int
+	mode                     Mode
+	adaptiveK                float64
+	shedNonIdempotentFirst   bool
+	halfOpenProbeInterval    time.Duration
+	callTimeout              time.Duration
+	onCallCompleteDetailed   func(ev CallCompleteEvent)
+	isSuccessfulWithMetadata func(md Metadata, err error) bool
+	readyToTripWithMetadata  func(md Metadata, counts Counts) bool
+	isPanicFailure           func(recovered interface{}) bool
+	readyToTripWithStats     func(counts Counts, stats Stats) bool
+	countWhileDisabled       bool
+	intervalPolicy           IntervalPolicy
+	payloadMetric            func(result interface{}, err error) (value float64, ok bool)
+	classifier               Classifier
+	halfOpenWait             time.Duration
+	onReject                 func(name string, state State, err error)
+	onStaleResult            func(name string, success bool)
+	countStaleResults        bool
+	countResetThreshold      uint32
+	logger                   Logger
+	minimumRequestThreshold  uint32
+	beforeStateChange        func(name string, from, to State, counts Counts) bool
+	minRemainingBudget       time.Duration
+	halfOpenIsSuccessful     func(d time.Duration, err error) bool
+	probeKey                 func(md Metadata) string
+	hasProbeKey              uint32
+	failOpenRatio            float64
+	stateMachine             StateMachine
+	halfOpenMaxConcurrent    uint32
+	halfOpenMaxTotal         uint32
+	isMaintenanceWindow      func(now time.Time) bool
+	maintenancePolicy        MaintenancePolicy
+	shadowMode               bool
+	halfOpenSuccessRatio     float64
+	categorize               func(err error) string
+	readyToTripByCategory    func(counts Counts, failures map[string]uint32) bool
+	recoveryRamp             *RecoveryRamp
+	countRejections          bool
+	probeElector             ProbeElector
+	hasProbeElector          uint32
+	halfOpenAdmission        HalfOpenAdmission
+	timeoutJitter            time.Duration
+	wrapErrors               bool
+	shedThresholds           map[Priority]float64
+	rejectedError            func(name string, state State) error
+	halfOpenDuration         time.Duration
+	cacheTTL                 time.Duration
+	cacheSize                int
+	isSuccessfulResult       func(result interface{}, err error) bool
+	readyToTripWithWindow    func(w Window) bool
+	injectFailureRate        float64
+	injectLatency            time.Duration
+	enforceCallTimeout       bool
+	metricsCollector         MetricsCollector
+	settings                 Settings
+	admissionPolicy          AdmissionPolicy
+	halfOpenLatencyBudget    time.Duration
 
-	mutex      sync.Mutex
-	state      State
-	generation uint64
-	counts     Counts
-	expiry     time.Time
+	initOnce    sync.Once
+	mutex       sync.Mutex
+	state       State
+	generation  uint64
+	counts      atomicCounts
+	stats       latencyStats
+	expiry      time.Time
+	subscribers []chan Event
+	downtime    downtime
+	shards      *shardSet
+	fast        atomic.Value
+	auditLog    ring
+	history     history
+	calls       ring
+	recentErrs  ring
+	tripDump    *TripDump
+	probeWait   chan struct{}
+	probeQueue  []probeTicket
+	resources   []*healthReporter
+	adaptive    *rollingWindow
+	burnRate    *burnRateWindows
+	decay       *decayedCounts
+	burst       *failureBurst
+	cache       resultCache
+	excusedBy   map[string]bool
+	probeCredit float64
+	slowProbes  uint32
+	probeCalls  map[string]*probeCall
+	nextProbe   time.Time
+	disabled    bool
+	lifetime    lifetime
+	ctx         context.Context
+	cancel      context.CancelFunc
+	background  sync.WaitGroup
+	closed      bool
+
+	generationState State
+	generationStart time.Time
+	previousCounts  Counts
+	lastTransition  Transition
+	categories      map[string]uint32
+	rampStart       time.Time
+	electing        bool
+	elected         uint64
+	rand            *lockedRand
 }
 
 // TwoStepCircuitBreaker is like CircuitBreaker but instead of surrounding a function
@@ -139,10 +795,174 @@ type TwoStepCircuitBreaker struct {
 
 // NewCircuitBreaker returns a new CircuitBreaker configured with the given Settings.
 func NewCircuitBreaker(st Settings) *CircuitBreaker {
+	return NewCircuitBreakerContext(context.Background(), st)
+}
+
+// NewCircuitBreakerContext is like NewCircuitBreaker, but the background goroutines of the CircuitBreaker,
+// such as the ones of HealthCheck, stop when ctx is done as well as when Close is called.
+func NewCircuitBreakerContext(ctx context.Context, st Settings) *CircuitBreaker {
 	cb := new(CircuitBreaker)
+	cb.initOnce.Do(func() { cb.init(ctx, st) })
+	return cb
+}
 
+func (cb *CircuitBreaker) init(ctx context.Context, st Settings) {
+	cb.ctx, cb.cancel = context.WithCancel(ctx)
 	cb.name = st.Name
+	cb.parent = st.Parent
+	if st.Clock == nil {
+		cb.clock = systemClock{}
+	} else {
+		cb.clock = st.Clock
+	}
+	cb.rand = newLockedRand()
+	cb.applySettings(st)
+
+	now := cb.clock.Now()
+	cb.lifetime.start(now)
+	cb.toNewGeneration(now)
+
+	if st.PublishExpvar {
+		cb.publishExpvar()
+	}
+}
+
+// lazyInit initializes a zero-value CircuitBreaker with the default Settings on its first use.
+func (cb *CircuitBreaker) lazyInit() {
+	cb.initOnce.Do(func() { cb.init(context.Background(), Settings{}) })
+}
+
+func (cb *CircuitBreaker) applySettings(st Settings) {
+	cb.settings = st
 	cb.onStateChange = st.OnStateChange
+	cb.readyToTripExternal = st.ReadyToTripExternal
+	cb.readyToClose = st.ReadyToClose
+	cb.readyToReopen = st.ReadyToReopen
+	cb.onStateChangeDetailed = st.OnStateChangeDetailed
+	cb.schedule = st.Schedule
+	cb.healthCheck = st.HealthCheck
+	cb.disablePanicRecovery = st.DisablePanicRecovery
+	cb.onCallComplete = st.OnCallComplete
+	cb.downtimeBudget = st.DowntimeBudget
+	cb.onDowntimeBudgetExceeded = st.OnDowntimeBudgetExceeded
+	cb.setHealthReporter(st.HealthReporter)
+	cb.idempotentProbes = st.IdempotentProbes
+	cb.counterShards = st.CounterShards
+	cb.mode = st.Mode
+	if cb.mode == ModeHighThroughput && cb.counterShards <= 0 {
+		cb.counterShards = runtime.GOMAXPROCS(0)
+	}
+	cb.shedNonIdempotentFirst = st.ShedNonIdempotentFirst
+	cb.halfOpenProbeInterval = st.HalfOpenProbeInterval
+	cb.callTimeout = st.CallTimeout
+	cb.onCallCompleteDetailed = st.OnCallCompleteDetailed
+	cb.isSuccessfulWithMetadata = st.IsSuccessfulWithMetadata
+	cb.readyToTripWithMetadata = st.ReadyToTripWithMetadata
+	cb.isPanicFailure = st.IsPanicFailure
+	cb.readyToTripWithStats = st.ReadyToTripWithStats
+	cb.countWhileDisabled = st.CountWhileDisabled
+	cb.intervalPolicy = st.IntervalPolicy
+	cb.payloadMetric = st.PayloadMetric
+	cb.classifier = st.Classifier
+	cb.halfOpenWait = st.HalfOpenWait
+	cb.onReject = st.OnReject
+	cb.onStaleResult = st.OnStaleResult
+	cb.countStaleResults = st.CountStaleResults
+	cb.countResetThreshold = st.CountResetThreshold
+	cb.logger = st.Logger
+	cb.minimumRequestThreshold = st.MinimumRequestThreshold
+	cb.beforeStateChange = st.BeforeStateChange
+	cb.minRemainingBudget = st.MinRemainingBudget
+	cb.halfOpenIsSuccessful = st.HalfOpenIsSuccessful
+	cb.setBurnRate(st.BurnRate)
+	cb.setDecayHalfLife(st.DecayHalfLife)
+	cb.probeKey = st.ProbeKey
+	cb.failOpenRatio = st.FailOpenRatio
+	cb.stateMachine = st.StateMachine
+	cb.halfOpenMaxConcurrent = st.HalfOpenMaxConcurrent
+	cb.halfOpenMaxTotal = st.HalfOpenMaxTotal
+	cb.isMaintenanceWindow = st.IsMaintenanceWindow
+	cb.maintenancePolicy = st.MaintenancePolicy
+	cb.shadowMode = st.ShadowMode
+	cb.halfOpenSuccessRatio = st.HalfOpenSuccessRatio
+	cb.categorize = st.Categorize
+	cb.readyToTripByCategory = st.ReadyToTripByCategory
+	cb.setRecoveryRamp(st.RecoveryRamp)
+	cb.countRejections = st.CountRejections
+	cb.setProbeElector(st.ProbeElector)
+	cb.halfOpenAdmission = st.HalfOpenAdmission
+	cb.timeoutJitter = st.TimeoutJitter
+	cb.wrapErrors = st.WrapErrors
+	cb.setShedThresholds(st.ShedThresholds)
+	cb.rejectedError = st.RejectedError
+	cb.halfOpenDuration = st.HalfOpenDuration
+	cb.setCache(st.CacheTTL, st.CacheSize)
+	cb.isSuccessfulResult = st.IsSuccessfulResult
+	cb.setBurst(st.BurstWindow, st.BurstThreshold)
+	cb.readyToTripWithWindow = st.ReadyToTripWithWindow
+	cb.injectFailureRate = st.InjectFailureRate
+	cb.injectLatency = st.InjectLatency
+	cb.enforceCallTimeout = st.EnforceCallTimeout
+	cb.metricsCollector = st.MetricsCollector
+	cb.admissionPolicy = st.AdmissionPolicy
+	cb.halfOpenLatencyBudget = st.HalfOpenLatencyBudget
+	if st.ProbeKey != nil {
+		atomic.StoreUint32(&cb.hasProbeKey, 1)
+	} else {
+		atomic.StoreUint32(&cb.hasProbeKey, 0)
+	}
+
+	if st.AdaptiveK <= 0 {
+		cb.adaptiveK = defaultAdaptiveK
+	} else {
+		cb.adaptiveK = st.AdaptiveK
+	}
+
+	adaptiveWindow := st.AdaptiveWindow
+	if adaptiveWindow <= 0 {
+		adaptiveWindow = defaultAdaptiveWindow
+	}
+	if cb.mode != ModeAdaptive {
+		cb.adaptive = nil
+	} else if cb.adaptive == nil || cb.adaptive.size != adaptiveWindow {
+		cb.adaptive = newRollingWindow(adaptiveWindow)
+	}
+
+	if st.AuditLogSize > 0 {
+		cb.auditLog.resize(st.AuditLogSize)
+	} else {
+		cb.auditLog.resize(0)
+	}
+
+	if st.HistorySize > 0 {
+		cb.history.resize(st.HistorySize)
+	} else {
+		cb.history.resize(0)
+	}
+
+	if st.TripDumpSize > 0 {
+		cb.calls.resize(st.TripDumpSize)
+	} else {
+		cb.calls.resize(0)
+	}
+
+	if st.RecentErrorsSize > 0 {
+		cb.recentErrs.resize(st.RecentErrorsSize)
+	} else {
+		cb.recentErrs.resize(0)
+	}
+
+	if st.HealthCheckInterval <= 0 {
+		cb.healthCheckInterval = defaultHealthCheckInterval
+	} else {
+		cb.healthCheckInterval = st.HealthCheckInterval
+	}
+
+	if st.HealthCheckSuccesses == 0 {
+		cb.healthCheckSuccesses = 1
+	} else {
+		cb.healthCheckSuccesses = st.HealthCheckSuccesses
+	}
 
 	if st.MaxRequests == 0 {
 		cb.maxRequests = 1
@@ -150,11 +970,7 @@ func NewCircuitBreaker(st Settings) *CircuitBreaker {
 		cb.maxRequests = st.MaxRequests
 	}
 
-	if st.Interval <= 0 {
-		cb.interval = defaultInterval
-	} else {
-		cb.interval = st.Interval
-	}
+	cb.interval = cb.intervalFor(st.Interval)
 
 	if st.Timeout <= 0 {
 		cb.timeout = defaultTimeout
@@ -174,9 +990,7 @@ func NewCircuitBreaker(st Settings) *CircuitBreaker {
 		cb.isSuccessful = st.IsSuccessful
 	}
 
-	cb.toNewGeneration(time.Now())
-
-	return cb
+	cb.unscheduled = Thresholds{MaxRequests: cb.maxRequests, ReadyToTrip: cb.readyToTrip}
 }
 
 // NewTwoStepCircuitBreaker returns a new TwoStepCircuitBreaker configured with the given Settings.
@@ -186,8 +1000,17 @@ func NewTwoStepCircuitBreaker(st Settings) *TwoStepCircuitBreaker {
 	}
 }
 
+// NewTwoStepCircuitBreakerContext is like NewTwoStepCircuitBreaker,
+// but the background goroutines stop when ctx is done. See NewCircuitBreakerContext.
+func NewTwoStepCircuitBreakerContext(ctx context.Context, st Settings) *TwoStepCircuitBreaker {
+	return &TwoStepCircuitBreaker{
+		cb: NewCircuitBreakerContext(ctx, st),
+	}
+}
+
 const defaultInterval = time.Duration(0) * time.Second
 const defaultTimeout = time.Duration(60) * time.Second
+const defaultHealthCheckInterval = time.Duration(1) * time.Second
 
 func defaultReadyToTrip(counts Counts) bool {
 	return counts.ConsecutiveFailures > 5
@@ -204,44 +1027,165 @@ func (cb *CircuitBreaker) Name() string {
 
 // State returns the current state of the CircuitBreaker.
 func (cb *CircuitBreaker) State() State {
+	cb.lazyInit()
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
 	state, _ := cb.currentState(now)
 	return state
 }
 
 // Counts returns internal counters
+// Counts doesn't take the lock unless some requests are counted by CounterShards.
 func (cb *CircuitBreaker) Counts() Counts {
+	cb.lazyInit()
+	if fp, _ := cb.fast.Load().(*fastPath); fp == nil {
+		return cb.counts.snapshot()
+	}
+
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	return cb.counts
+	cb.foldShards()
+	return cb.counts.snapshot()
 }
 
 // Execute runs the given request if the CircuitBreaker accepts it.
-// Execute returns an error instantly if the CircuitBreaker rejects the request.
+// Execute returns a *RejectionError instantly if the CircuitBreaker rejects the request.
 // Otherwise, Execute returns the result of the request.
 // If a panic occurs in the request, the CircuitBreaker handles it as an error
-// and causes the same panic again.
+// and causes the same panic again, unless Settings.DisablePanicRecovery is true.
 func (cb *CircuitBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
-	generation, err := cb.beforeRequest()
+	return cb.execute(req, callOptions{})
+}
+
+func (cb *CircuitBreaker) execute(req func() (interface{}, error), opts callOptions) (interface{}, error) {
+	result, _, err := cb.executeVerdict(req, opts)
+	return result, err
+}
+
+// executeVerdict is execute but also returns the Verdict recorded for the outcome, or Undecided if the request is rejected.
+func (cb *CircuitBreaker) executeVerdict(req func() (interface{}, error), opts callOptions) (interface{}, Verdict, error) {
+	for {
+		call, leader := cb.joinProbe(opts)
+		if call == nil {
+			break
+		}
+		if leader {
+			return cb.leadProbe(call, req, opts)
+		}
+
+		<-call.done
+		if call.ok {
+			return call.result, call.verdict, call.err
+		}
+	}
+
+	adm, err := cb.beforeRequest(opts)
 	if err != nil {
-		return nil, err
+		return nil, Undecided, err
+	}
+
+	return cb.runVerdict(adm, req)
+}
+
+// run runs req admitted with adm and records its outcome.
+func (cb *CircuitBreaker) run(adm admission, req func() (interface{}, error)) (interface{}, error) {
+	result, _, err := cb.runVerdict(adm, req)
+	return result, err
+}
+
+// runVerdict is run but also returns the Verdict recorded for the outcome,
+// so that the callers branching on the outcome don't classify it again.
+func (cb *CircuitBreaker) runVerdict(adm admission, req func() (interface{}, error)) (interface{}, Verdict, error) {
+	req = cb.injectFaults(adm, req)
+	if adm.enforcedTimeout > 0 {
+		return cb.runWithTimeout(adm, req)
+	}
+	if adm.disablePanicRecovery {
+		return cb.executeWithoutRecovery(adm, req)
 	}
 
 	defer func() {
 		e := recover()
 		if e != nil {
-			cb.afterRequest(generation, false)
+			if adm.isPanicFailure == nil || adm.isPanicFailure(e) {
+				cb.afterRequest(adm, false)
+			} else {
+				cb.releaseRequest(adm)
+			}
 			panic(e)
 		}
 	}()
 
 	result, err := req()
-	cb.afterRequest(generation, cb.isSuccessful(err))
-	return result, err
+	adm.measurePayload(result, err)
+	adm.result = result
+	v := cb.afterRequestWithError(adm, err)
+	return result, v, cb.wrapError(adm, err)
+}
+
+// executeWithoutRecovery runs req without recovering a panic.
+// A deferred function that doesn't call recover keeps the stack trace of the panic intact.
+func (cb *CircuitBreaker) executeWithoutRecovery(adm admission, req func() (interface{}, error)) (interface{}, Verdict, error) {
+	returned := false
+	defer func() {
+		if !returned {
+			cb.afterRequest(adm, false)
+		}
+	}()
+
+	result, err := req()
+	returned = true
+	adm.measurePayload(result, err)
+	adm.result = result
+	v := cb.afterRequestWithError(adm, err)
+	return result, v, cb.wrapError(adm, err)
+}
+
+// ReportExternalFailure records a failure reported by a source other than the requests,
+// e.g. a connection pool that has run out of connections or an SDK health signal.
+// In the closed state, the failure is counted as a failed request
+// and ReadyToTripExternal decides whether the CircuitBreaker trips.
+// In the half-open state, the CircuitBreaker is placed into the open state.
+// In the open state, the failure is ignored.
+func (cb *CircuitBreaker) ReportExternalFailure(reason string) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	state, _ := cb.currentState(now)
+
+	if cb.disabled {
+		if cb.countWhileDisabled {
+			cb.counts.onRequest()
+			cb.counts.onFailure()
+		}
+		return
+	}
+
+	switch state {
+	case StateClosed:
+		cb.counts.onRequest()
+		cb.counts.onFailure()
+		cb.countDecayed(now, 0, 1)
+
+		var trip bool
+		if cb.belowMinimumRequests() {
+			trip = false
+		} else if cb.readyToTripExternal == nil {
+			trip = cb.readyToTrip(cb.tripCounts())
+		} else {
+			trip = cb.readyToTripExternal(reason, cb.tripCounts())
+		}
+		if trip {
+			cb.transition(StateOpen, now, CauseReadyToTrip)
+		}
+	case StateHalfOpen:
+		cb.transition(StateOpen, now, CauseHalfOpenFailure)
+	}
 }
 
 // Name returns the name of the TwoStepCircuitBreaker.
@@ -259,112 +1203,642 @@ func (tscb *TwoStepCircuitBreaker) Counts() Counts {
 	return tscb.cb.Counts()
 }
 
+// ReportExternalFailure records a failure reported by a source other than the requests.
+// See CircuitBreaker.ReportExternalFailure.
+func (tscb *TwoStepCircuitBreaker) ReportExternalFailure(reason string) {
+	tscb.cb.ReportExternalFailure(reason)
+}
+
 // Allow checks if a new request can proceed. It returns a callback that should be used to
 // register the success or failure in a separate step. If the circuit breaker doesn't allow
 // requests, it returns an error.
+// The callback is allocated for every request; Acquire doesn't allocate on the hot path.
 func (tscb *TwoStepCircuitBreaker) Allow() (done func(success bool), err error) {
-	generation, err := tscb.cb.beforeRequest()
+	return tscb.allow(callOptions{})
+}
+
+func (tscb *TwoStepCircuitBreaker) allow(opts callOptions) (done func(success bool), err error) {
+	t, err := tscb.acquire(opts)
 	if err != nil {
 		return nil, err
 	}
+	return t.Done, nil
+}
+
+// admission holds what an admitted request needs to know about the CircuitBreaker
+// at the time of beforeRequest, so that it doesn't read the settings without the lock.
+type admission struct {
+	generation           uint64
+	state                State
+	start                time.Time
+	disablePanicRecovery bool
+	isPanicFailure       func(recovered interface{}) bool
+	payloadMetric        func(result interface{}, err error) (value float64, ok bool)
+	payload              float64
+	hasPayload           bool
+	onCallComplete       func(name string, d time.Duration, err error, state State)
+	onCallCompleteEvent  func(ev CallCompleteEvent)
+	callTimeout          time.Duration
+	timeout              time.Duration
+	isSuccessful         func(err error) bool
+	isSuccessfulWithMD   func(md Metadata, err error) bool
+	isSuccessfulResult   func(result interface{}, err error) bool
+	classifier           Classifier
+	halfOpenIsSuccessful func(d time.Duration, err error) bool
+	metadata             Metadata
+	err                  error
+	result               interface{}
+	shards               *shardSet
+	batchFailures        bool
+	shadowed             bool
+	weight               uint32
+	wrapErrors           bool
+	injectFailureRate    float64
+	injectLatency        time.Duration
+	enforcedTimeout      time.Duration
+	metricsCollector     MetricsCollector
+	recordErrors         bool
+	parent               *admission
+}
+
+func (cb *CircuitBreaker) beforeRequest(opts callOptions) (admission, error) {
+	cb.lazyInit()
+	start := cb.clock.Now()
+	adm, err := cb.admitWithParent(opts)
+	if adm.metricsCollector != nil {
+		adm.metricsCollector.ObserveDecision(cb.name, cb.clock.Now().Sub(start), err == nil)
+	}
+	return adm, err
+}
 
-	return func(success bool) {
-		tscb.cb.afterRequest(generation, success)
-	}, nil
+// admitWithParent allows the request by the parent CircuitBreaker, if any, and then by cb.
+func (cb *CircuitBreaker) admitWithParent(opts callOptions) (admission, error) {
+	if cb.parent == nil {
+		return cb.admit(opts)
+	}
+
+	parent, err := cb.parent.beforeRequest(opts)
+	if err != nil {
+		return admission{}, err
+	}
+
+	adm, err := cb.admit(opts)
+	if err != nil {
+		cb.parent.cancelRequest(parent)
+		return adm, err
+	}
+	adm.parent = &parent
+	return adm, nil
 }
 
-func (cb *CircuitBreaker) beforeRequest() (uint64, error) {
+// tryAdmit allows or rejects a request once.
+// If canWait is true, Settings.HalfOpenWait is more than 0, and the request would be rejected only because
+// all the probes of the half-open state are in flight, tryAdmit returns a channel closed when a probe completes
+// instead of rejecting the request.
+func (cb *CircuitBreaker) tryAdmit(opts callOptions, canWait bool) (adm admission, wait <-chan struct{}, err error) {
+	if opts.ticket == nil {
+		if adm, ok := cb.admitFast(opts); ok {
+			opts.configure(&adm)
+			return adm, nil, nil
+		}
+	}
+
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
+	defer func() {
+		if wait == nil {
+			cb.dequeueProbe(opts.ticket)
+		}
+	}()
+
+	return cb.tryAdmitLocked(opts, canWait)
+}
 
-	now := time.Now()
+// tryAdmitLocked is tryAdmit with the lock held.
+func (cb *CircuitBreaker) tryAdmitLocked(opts callOptions, canWait bool) (adm admission, wait <-chan struct{}, err error) {
+	now := cb.clock.Now()
 	state, generation := cb.currentState(now)
+	adm = cb.newAdmission(state, generation)
+	adm.start = now
+	opts.configure(&adm)
 
-	if state == StateOpen {
-		return generation, ErrOpenState
-	} else if state == StateHalfOpen && cb.counts.Requests >= cb.maxRequests {
-		return generation, ErrTooManyRequests
+	if cb.disabled {
+		if cb.countWhileDisabled {
+			cb.counts.onRequest()
+		}
+		return adm, nil, nil
+	}
+
+	if state == StateOpen && !cb.failOpen() {
+		return cb.rejectAdmission(adm, ErrOpenState, now)
+	} else if cb.shedsPriority(state, opts.priority) {
+		return cb.rejectAdmission(adm, ErrThrottled, now)
+	} else if state == StateHalfOpen && (cb.counts.snapshot().Requests >= cb.probeLimit(opts) || cb.probesSaturated() || cb.queuedAhead(opts.ticket)) {
+		if canWait && cb.halfOpenWait > 0 && !cb.shadowMode {
+			return adm, cb.probeSignal(opts.ticket), nil
+		}
+		return cb.rejectAdmission(adm, ErrTooManyRequests, now)
+	} else if state == StateHalfOpen && now.Before(cb.nextProbe) {
+		return cb.rejectAdmission(adm, ErrTooManyRequests, now)
+	} else if isCustomState(state) && cb.stateMachine != nil && !cb.stateMachine.Allow(state, cb.counts.snapshot()) {
+		return cb.rejectAdmission(adm, ErrThrottled, now)
+	} else if cb.insufficientBudget(opts, now) {
+		return cb.rejectAdmission(adm, ErrInsufficientBudget, now)
+	} else if cb.adaptive != nil && cb.throttle(now, opts) {
+		return cb.rejectAdmission(adm, ErrThrottled, now)
+	} else if state == StateClosed && cb.rampThrottles(now) {
+		return cb.rejectAdmission(adm, ErrThrottled, now)
+	} else if err := cb.admitByPolicy(state, opts, now); err != nil {
+		return cb.rejectAdmission(adm, err, now)
+	}
+
+	if state == StateHalfOpen && cb.halfOpenProbeInterval > 0 {
+		cb.nextProbe = now.Add(cb.probeDelay())
 	}
 
 	cb.counts.onRequest()
-	return generation, nil
+	return adm, nil, nil
+}
+
+func (cb *CircuitBreaker) newAdmission(state State, generation uint64) admission {
+	return admission{
+		generation:           generation,
+		state:                state,
+		disablePanicRecovery: cb.disablePanicRecovery,
+		isPanicFailure:       cb.isPanicFailure,
+		payloadMetric:        cb.payloadMetric,
+		onCallComplete:       cb.onCallComplete,
+		onCallCompleteEvent:  cb.onCallCompleteDetailed,
+		callTimeout:          cb.callTimeout,
+		isSuccessfulWithMD:   cb.isSuccessfulWithMetadata,
+		isSuccessfulResult:   cb.isSuccessfulResult,
+		isSuccessful:         cb.isSuccessful,
+		classifier:           cb.classifier,
+		halfOpenIsSuccessful: cb.halfOpenIsSuccessful,
+		shards:               cb.shards,
+		batchFailures:        cb.mode == ModeHighThroughput && cb.categorize == nil,
+		wrapErrors:           cb.wrapErrors,
+		injectFailureRate:    cb.injectFailureRate,
+		injectLatency:        cb.injectLatency,
+		enforcedTimeout:      cb.enforcedCallTimeout(),
+		metricsCollector:     cb.metricsCollector,
+		recordErrors:         cb.recentErrs.cap() > 0,
+	}
 }
 
-func (cb *CircuitBreaker) afterRequest(before uint64, success bool) {
+func (cb *CircuitBreaker) reject(err error, now time.Time) *RejectionError {
+	e := &RejectionError{
+		Name:   cb.name,
+		State:  cb.state,
+		Counts: cb.counts.snapshot(),
+		err:    err,
+	}
+	if cb.state == StateOpen {
+		e.RetryAfter = cb.expiry.Sub(now)
+	}
+	if cb.countRejections {
+		cb.stats.rejections++
+	}
+
+	cb.publish(Event{Type: EventRejection, Time: now, State: cb.state, Counts: e.Counts, Err: e})
+
+	if cb.onReject != nil {
+		cb.onReject(cb.name, cb.state, e)
+	}
+	return e
+}
+
+// rejectAdmission rejects the request of adm with err, or allows it without counting it in ShadowMode.
+func (cb *CircuitBreaker) rejectAdmission(adm admission, err error, now time.Time) (admission, <-chan struct{}, error) {
+	e := cb.reject(err, now)
+	if cb.shadowMode {
+		adm.shadowed = true
+		return adm, nil, nil
+	}
+	if cb.rejectedError != nil {
+		if custom := cb.rejectedError(cb.name, cb.state); custom != nil {
+			return adm, nil, custom
+		}
+	}
+	return adm, nil, e
+}
+
+// cancelRequest withdraws an admitted request without counting its outcome.
+func (cb *CircuitBreaker) cancelRequest(adm admission) {
+	if adm.shadowed {
+		cb.recordShadowedError(adm)
+		return
+	}
+
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
+	cb.recordError(adm, now)
+	_, generation := cb.currentState(now)
+	if generation == adm.generation && cb.counts.snapshot().Requests > 0 {
+		cb.counts.onCancel()
+		cb.signalProbe()
+	}
+}
+
+// releaseRequest forgets the request admitted with adm by the CircuitBreaker and its parents.
+func (cb *CircuitBreaker) releaseRequest(adm admission) {
+	cb.cancelRequest(adm)
+	if adm.parent != nil {
+		cb.parent.releaseRequest(*adm.parent)
+	}
+}
+
+func (cb *CircuitBreaker) afterRequest(adm admission, success bool) {
+	if adm.shadowed {
+		// The request would have been rejected, so its outcome is counted only by the parents.
+		cb.recordShadowedError(adm)
+		if adm.parent != nil {
+			cb.parent.afterRequest(*adm.parent, success)
+		}
+		return
+	}
+
+	// The errors are recorded under the lock, so the requests returning them bypass the shards.
+	recordsError := adm.err != nil && adm.recordErrors
+	if success && adm.shards != nil && !recordsError {
+		adm.shards.onSuccess()
+	} else if adm.shards != nil && adm.batchFailures && !recordsError {
+		adm.shards.onFailure()
+	} else {
+		cb.mutex.Lock()
+		cb.recordResult(adm, success)
+		cb.mutex.Unlock()
+	}
+
+	if adm.parent != nil {
+		cb.parent.afterRequest(*adm.parent, success)
+	}
+}
+
+// afterRequestWithError classifies err with IsSuccessful or Classifier at the time the request was allowed,
+// records the outcome and returns the Verdict.
+func (cb *CircuitBreaker) afterRequestWithError(adm admission, err error) Verdict {
+	adm.err = err
+	d := cb.clock.Now().Sub(adm.start)
+	v := adm.classify(d, err)
+	switch v {
+	case Success:
+		cb.afterRequest(adm, true)
+	case Failure:
+		cb.afterRequest(adm, false)
+	default: // Ignored
+		cb.releaseRequest(adm)
+	}
+
+	if adm.metricsCollector != nil {
+		adm.metricsCollector.ObserveCall(cb.name, d, adm.state)
+	}
+	if adm.onCallComplete == nil && adm.onCallCompleteEvent == nil {
+		return v
+	}
+
+	if adm.onCallComplete != nil {
+		adm.onCallComplete(cb.name, d, err, adm.state)
+	}
+	if adm.onCallCompleteEvent != nil {
+		adm.onCallCompleteEvent(CallCompleteEvent{
+			Name:     cb.name,
+			Duration: d,
+			Err:      err,
+			State:    adm.state,
+			Timeout:  adm.timeout,
+			Metadata: adm.metadata,
+		})
+	}
+	return v
+}
+
+func (cb *CircuitBreaker) recordResult(adm admission, success bool) {
+	now := cb.clock.Now()
+	cb.recordError(adm, now)
 	state, generation := cb.currentState(now)
-	if generation != before {
+	if generation != adm.generation {
+		cb.recordStaleResult(adm, state, success, now)
 		return
 	}
+	if success && state == StateHalfOpen && !cb.creditProbe(now.Sub(adm.start)) {
+		success = false
+	}
+
+	cb.stats.add(now.Sub(adm.start))
+	cb.recordCall(adm, success, now)
+	if adm.weight > 1 {
+		defer cb.recordWeight(adm.weight-1, success, now)
+	}
+	if state == StateHalfOpen {
+		defer cb.signalProbe()
+	}
+	if adm.hasPayload {
+		cb.stats.addPayload(adm.payload)
+	}
+
+	if cb.disabled {
+		cb.countDisabled(success)
+		return
+	}
+
+	if state == StateHalfOpen {
+		cb.publish(Event{Type: EventProbeResult, Time: now, State: state, Counts: cb.counts.snapshot(), Success: success})
+		cb.logProbeResult(success)
+	}
 
 	if success {
 		cb.onSuccess(state, now)
 	} else {
-		cb.onFailure(state, now)
+		cb.countCategory(adm.err, now)
+		cb.onFailure(state, now, adm.metadata)
 	}
 }
 
 func (cb *CircuitBreaker) onSuccess(state State, now time.Time) {
+	if cb.adaptive != nil {
+		cb.adaptive.onSuccess(now)
+	}
+
 	switch state {
 	case StateClosed:
 		cb.counts.onSuccess()
+		if cb.burnRate != nil {
+			cb.burnRate.onSuccess(now)
+		}
+		cb.countDecayed(now, 1, 0)
+		if cb.mode != ModeAdaptive && cb.burnRate == nil && cb.readyToTripWithMetadata == nil && cb.readyToTripWithStats != nil &&
+			!cb.belowMinimumRequests() && cb.readyToTripWithStats(cb.tripCounts(), cb.snapshotStats()) {
+			cb.transition(StateOpen, now, CauseReadyToTrip)
+		}
+	case StateOpen: // a canary allowed by FailOpenRatio
+		cb.counts.onSuccess()
+		cb.transition(StateHalfOpen, now, CauseCanary)
 	case StateHalfOpen:
 		cb.counts.onSuccess()
-		if cb.counts.ConsecutiveSuccesses >= cb.maxRequests {
-			cb.setState(StateClosed, now)
+		if cb.trialing() {
+			break
+		}
+		if cb.closesByRatio() {
+			cb.evaluateSuccessRatio(now)
+			break
+		}
+
+		var ready bool
+		if cb.readyToClose == nil {
+			ready = cb.probeSuccesses() >= float64(cb.probeTotal())
+		} else {
+			ready = cb.readyToClose(cb.counts.snapshot())
+		}
+		if ready {
+			cb.transition(StateClosed, now, CauseHalfOpenSuccess)
+		} else if cb.probesCompleted() {
+			cb.transition(StateOpen, now, CauseHalfOpenFailure)
 		}
+	default: // a custom state of StateMachine
+		cb.counts.onSuccess()
 	}
+	cb.nextState(state, true, now)
 }
 
-func (cb *CircuitBreaker) onFailure(state State, now time.Time) {
+func (cb *CircuitBreaker) onFailure(state State, now time.Time, md Metadata) {
+	if cb.ignoresFailure(now) {
+		cb.counts.onCancel()
+		return
+	}
+
 	switch state {
 	case StateClosed:
 		cb.counts.onFailure()
-		if cb.readyToTrip(cb.counts) {
-			cb.setState(StateOpen, now)
+		if cb.burnRate != nil {
+			cb.burnRate.onFailure(now)
+		}
+		cb.countDecayed(now, 0, 1)
+		if cb.burst != nil {
+			cb.burst.window.onFailure(now)
+		}
+		if cb.mode != ModeAdaptive && cb.tripReady(md) {
+			cb.transition(StateOpen, now, CauseReadyToTrip)
 		}
+	case StateOpen: // a canary allowed by FailOpenRatio
+		cb.counts.onFailure()
 	case StateHalfOpen:
-		cb.setState(StateOpen, now)
+		cb.counts.onFailure()
+		if cb.trialing() {
+			break
+		}
+		if cb.closesByRatio() {
+			cb.evaluateSuccessRatio(now)
+		} else if cb.readyToReopen == nil || cb.readyToReopen(cb.counts.snapshot()) || cb.probesCompleted() {
+			cb.transition(StateOpen, now, CauseHalfOpenFailure)
+		}
+	default: // a custom state of StateMachine
+		cb.counts.onFailure()
+	}
+	cb.nextState(state, false, now)
+}
+
+// failOpen reports whether a request in the open state is allowed as a canary by FailOpenRatio.
+func (cb *CircuitBreaker) failOpen() bool {
+	return cb.failOpenRatio > 0 && cb.rand.Float64() < cb.failOpenRatio
+}
+
+// probeDelay returns the jittered delay until the next probe in the half-open state.
+func (cb *CircuitBreaker) probeDelay() time.Duration {
+	return cb.halfOpenProbeInterval/2 + time.Duration(cb.rand.Int63n(int64(cb.halfOpenProbeInterval)+1))
+}
+
+// jitter returns the random duration added to Timeout by TimeoutJitter.
+func (cb *CircuitBreaker) jitter() time.Duration {
+	if cb.timeoutJitter <= 0 {
+		return 0
+	}
+	return time.Duration(cb.rand.Int63n(int64(cb.timeoutJitter) + 1))
+}
+
+// lockedRand is a *rand.Rand safe for concurrent use, seeded per CircuitBreaker,
+// so that the random decisions of a CircuitBreaker don't contend for the global source with the others.
+type lockedRand struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+func newLockedRand() *lockedRand {
+	return &lockedRand{rand: rand.New(rand.NewSource(rand.Int63()))}
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Float64()
+}
+
+func (r *lockedRand) Int63n(n int64) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Int63n(n)
+}
+
+// closesByRatio reports whether the half-open state is decided by HalfOpenSuccessRatio.
+func (cb *CircuitBreaker) closesByRatio() bool {
+	return cb.halfOpenSuccessRatio > 0 && cb.readyToClose == nil && cb.readyToReopen == nil
+}
+
+// evaluateSuccessRatio closes or reopens the CircuitBreaker by HalfOpenSuccessRatio
+// once all of the probes have completed.
+func (cb *CircuitBreaker) evaluateSuccessRatio(now time.Time) {
+	if !cb.probesCompleted() {
+		return
+	}
+
+	counts := cb.counts.snapshot()
+	if float64(counts.TotalSuccesses)/float64(counts.TotalSuccesses+counts.TotalFailures) >= cb.halfOpenSuccessRatio {
+		cb.transition(StateClosed, now, CauseHalfOpenSuccess)
+	} else {
+		cb.transition(StateOpen, now, CauseHalfOpenFailure)
 	}
 }
 
+// probesCompleted reports whether all of the requests allowed in the half-open state have completed.
+func (cb *CircuitBreaker) probesCompleted() bool {
+	counts := cb.counts.snapshot()
+	return counts.TotalSuccesses+counts.TotalFailures >= cb.probeTotal()+cb.slowProbes
+}
+
 func (cb *CircuitBreaker) currentState(now time.Time) (State, uint64) {
+	if cb.foldShards() > 0 && cb.state == StateClosed && cb.tripReady(nil) {
+		cb.transition(StateOpen, now, CauseReadyToTrip)
+	}
+
 	switch cb.state {
 	case StateClosed:
-		if !cb.expiry.IsZero() && cb.expiry.Before(now) {
+		if !cb.expiry.IsZero() && cb.expiry.Before(now) || cb.countResetReached() {
 			cb.toNewGeneration(now)
 		}
 	case StateOpen:
-		if cb.expiry.Before(now) {
-			cb.setState(StateHalfOpen, now)
+		if !cb.healthChecking() && cb.expiry.Before(now) && cb.probeElected() {
+			cb.transition(StateHalfOpen, now, CauseTimeout)
+		}
+	case StateHalfOpen:
+		if cb.trialing() && cb.expiry.Before(now) {
+			cb.endTrial(now)
 		}
 	}
 	return cb.state, cb.generation
 }
 
-func (cb *CircuitBreaker) setState(state State, now time.Time) {
+// transition changes the state of the CircuitBreaker automatically unless BeforeStateChange vetoes it.
+// A vetoed transition from the open or half-open state starts a new generation of the current state,
+// so that the transition is evaluated again after another Timeout or another round of probes.
+func (cb *CircuitBreaker) transition(state State, now time.Time, cause TransitionCause) {
+	if cb.vetoed(state) {
+		if cb.state != StateClosed {
+			cb.toNewGeneration(now)
+		}
+		return
+	}
+	cb.setState(state, now, cause)
+}
+
+// vetoed reports whether BeforeStateChange vetoes the transition to state.
+func (cb *CircuitBreaker) vetoed(state State) bool {
+	return cb.beforeStateChange != nil && cb.state != state &&
+		!cb.beforeStateChange(cb.name, cb.state, state, cb.counts.snapshot())
+}
+
+func (cb *CircuitBreaker) setState(state State, now time.Time, cause TransitionCause) {
 	if cb.state == state {
 		return
 	}
 
+	cb.foldShards()
 	prev := cb.state
+	counts := cb.counts.snapshot()
+	if state == StateOpen {
+		cb.captureTripDump(prev, now)
+	}
 	cb.state = state
+	cb.lifetime.onTransition(prev, state, now)
+	cb.lastTransition = Transition{From: prev, To: state, Cause: cause, Time: now, Counts: counts}
+	cb.startRamp(prev, state, now)
 
 	cb.toNewGeneration(now)
 
+	if prev == StateOpen {
+		cb.onOpenEnd(now)
+	}
+
+	if state == StateClosed && cb.burnRate != nil {
+		cb.burnRate.clear()
+	}
+	if state == StateClosed && cb.decay != nil {
+		cb.decay.clear()
+	}
+	if state == StateClosed && cb.burst != nil {
+		cb.burst.clear()
+	}
+
+	if state == StateOpen {
+		if cb.healthChecking() {
+			cb.startHealthCheck()
+		}
+		cb.onOpenStart(now)
+	}
+
 	if cb.onStateChange != nil {
 		cb.onStateChange(cb.name, prev, state)
 	}
+	cb.logStateChange(prev, state, cause, counts)
+
+	cb.publish(Event{Type: EventStateChange, Time: now, State: state, From: prev, To: state, Counts: counts})
+
+	ev := StateChangeEvent{
+		Name:         cb.name,
+		From:         prev,
+		To:           state,
+		Time:         now,
+		Counts:       counts,
+		Generation:   cb.generation,
+		Cause:        cause,
+		RecentErrors: cb.errorRecords(),
+	}
+
+	if cb.onStateChangeDetailed != nil {
+		cb.onStateChangeDetailed(ev)
+	}
+
+	if cb.healthReporter != nil {
+		cb.healthReporter.onStateChange(ev)
+	}
+	for _, hr := range cb.resources {
+		hr.onStateChange(ev)
+	}
 }
 
 func (cb *CircuitBreaker) toNewGeneration(now time.Time) {
+	cb.foldShards()
+	cb.endGeneration(now)
+	if cb.generationState == StateClosed && cb.state == StateClosed {
+		cb.previousCounts = cb.counts.snapshot()
+	} else {
+		cb.previousCounts = Counts{}
+	}
 	cb.generation++
+	cb.generationState = cb.state
+	cb.generationStart = now
 	cb.counts.clear()
+	cb.stats.clear()
+	cb.categories = nil
+	cb.probeCredit = 0
+	cb.slowProbes = 0
+	cb.signalProbe()
+	cb.resetShards()
+	cb.applySchedule(now)
 
 	var zero time.Time
+	cb.nextProbe = zero
 	switch cb.state {
 	case StateClosed:
 		if cb.interval == 0 {
@@ -373,8 +1847,34 @@ func (cb *CircuitBreaker) toNewGeneration(now time.Time) {
 			cb.expiry = now.Add(cb.interval)
 		}
 	case StateOpen:
-		cb.expiry = now.Add(cb.timeout)
+		cb.expiry = now.Add(cb.timeout + cb.jitter())
 	default: // StateHalfOpen
-		cb.expiry = zero
+		if cb.trialing() {
+			cb.expiry = now.Add(cb.halfOpenDuration)
+		} else {
+			cb.expiry = zero
+		}
+	}
+
+	cb.publishFastPath()
+}
+
+func (cb *CircuitBreaker) applySchedule(now time.Time) {
+	if cb.schedule == nil {
+		return
+	}
+
+	th := cb.schedule(now)
+
+	if th.MaxRequests == 0 {
+		cb.maxRequests = cb.unscheduled.MaxRequests
+	} else {
+		cb.maxRequests = th.MaxRequests
+	}
+
+	if th.ReadyToTrip == nil {
+		cb.readyToTrip = cb.unscheduled.ReadyToTrip
+	} else {
+		cb.readyToTrip = th.ReadyToTrip
 	}
 }
@@ -1,8 +1,11 @@
 package gobreaker
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -74,7 +77,7 @@ func fail2Step(cb *TwoStepCircuitBreaker) error {
 }
 
 func causePanic(cb *CircuitBreaker) error {
-	_, err := cb.Execute(func() (interface{}, error) { panic("oops"); return nil, nil })
+	_, err := cb.Execute(func() (interface{}, error) { panic("oops") })
 	return err
 }
 
@@ -125,6 +128,35 @@ func TestStateConstants(t *testing.T) {
 	assert.Equal(t, State(100).String(), "unknown state: 100")
 }
 
+func TestStateMarshal(t *testing.T) {
+	for _, s := range []State{StateClosed, StateHalfOpen, StateOpen, StateOpen + 1} {
+		text, err := s.MarshalText()
+		assert.Nil(t, err)
+		parsed, err := ParseState(string(text))
+		assert.Nil(t, err)
+		assert.Equal(t, s, parsed)
+	}
+
+	_, err := ParseState("ajar")
+	assert.NotNil(t, err)
+	_, err = ParseState("1")
+	assert.NotNil(t, err)
+
+	data, err := json.Marshal(struct {
+		State  State  `json:"state"`
+		Counts Counts `json:"counts"`
+	}{StateHalfOpen, Counts{3, 2, 1, 0, 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"state":"half-open","counts":{"requests":3,"totalSuccesses":2,"totalFailures":1,"consecutiveSuccesses":0,"consecutiveFailures":1}}`, string(data))
+
+	var s State
+	assert.Nil(t, json.Unmarshal([]byte(`"open"`), &s))
+	assert.Equal(t, StateOpen, s)
+	assert.Nil(t, json.Unmarshal([]byte(`1`), &s))
+	assert.Equal(t, StateHalfOpen, s)
+	assert.NotNil(t, json.Unmarshal([]byte(`"ajar"`), &s))
+}
+
 func TestNewCircuitBreaker(t *testing.T) {
 	defaultCB := NewCircuitBreaker(Settings{})
 	assert.Equal(t, "", defaultCB.name)
@@ -134,7 +166,7 @@ func TestNewCircuitBreaker(t *testing.T) {
 	assert.NotNil(t, defaultCB.readyToTrip)
 	assert.Nil(t, defaultCB.onStateChange)
 	assert.Equal(t, StateClosed, defaultCB.state)
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts.snapshot())
 	assert.True(t, defaultCB.expiry.IsZero())
 
 	customCB := newCustom()
@@ -145,7 +177,7 @@ func TestNewCircuitBreaker(t *testing.T) {
 	assert.NotNil(t, customCB.readyToTrip)
 	assert.NotNil(t, customCB.onStateChange)
 	assert.Equal(t, StateClosed, customCB.state)
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts.snapshot())
 	assert.False(t, customCB.expiry.IsZero())
 
 	negativeDurationCB := newNegativeDurationCB()
@@ -156,7 +188,7 @@ func TestNewCircuitBreaker(t *testing.T) {
 	assert.NotNil(t, negativeDurationCB.readyToTrip)
 	assert.Nil(t, negativeDurationCB.onStateChange)
 	assert.Equal(t, StateClosed, negativeDurationCB.state)
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, negativeDurationCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, negativeDurationCB.counts.snapshot())
 	assert.True(t, negativeDurationCB.expiry.IsZero())
 }
 
@@ -167,27 +199,27 @@ func TestDefaultCircuitBreaker(t *testing.T) {
 		assert.Nil(t, fail(defaultCB))
 	}
 	assert.Equal(t, StateClosed, defaultCB.State())
-	assert.Equal(t, Counts{5, 0, 5, 0, 5}, defaultCB.counts)
+	assert.Equal(t, Counts{5, 0, 5, 0, 5}, defaultCB.counts.snapshot())
 
 	assert.Nil(t, succeed(defaultCB))
 	assert.Equal(t, StateClosed, defaultCB.State())
-	assert.Equal(t, Counts{6, 1, 5, 1, 0}, defaultCB.counts)
+	assert.Equal(t, Counts{6, 1, 5, 1, 0}, defaultCB.counts.snapshot())
 
 	assert.Nil(t, fail(defaultCB))
 	assert.Equal(t, StateClosed, defaultCB.State())
-	assert.Equal(t, Counts{7, 1, 6, 0, 1}, defaultCB.counts)
+	assert.Equal(t, Counts{7, 1, 6, 0, 1}, defaultCB.counts.snapshot())
 
 	// StateClosed to StateOpen
 	for i := 0; i < 5; i++ {
 		assert.Nil(t, fail(defaultCB)) // 6 consecutive failures
 	}
 	assert.Equal(t, StateOpen, defaultCB.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts.snapshot())
 	assert.False(t, defaultCB.expiry.IsZero())
 
 	assert.Error(t, succeed(defaultCB))
 	assert.Error(t, fail(defaultCB))
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts.snapshot())
 
 	pseudoSleep(defaultCB, time.Duration(59)*time.Second)
 	assert.Equal(t, StateOpen, defaultCB.State())
@@ -200,7 +232,7 @@ func TestDefaultCircuitBreaker(t *testing.T) {
 	// StateHalfOpen to StateOpen
 	assert.Nil(t, fail(defaultCB))
 	assert.Equal(t, StateOpen, defaultCB.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts.snapshot())
 	assert.False(t, defaultCB.expiry.IsZero())
 
 	// StateOpen to StateHalfOpen
@@ -211,7 +243,7 @@ func TestDefaultCircuitBreaker(t *testing.T) {
 	// StateHalfOpen to StateClosed
 	assert.Nil(t, succeed(defaultCB))
 	assert.Equal(t, StateClosed, defaultCB.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, defaultCB.counts.snapshot())
 	assert.True(t, defaultCB.expiry.IsZero())
 }
 
@@ -223,23 +255,23 @@ func TestCustomCircuitBreaker(t *testing.T) {
 		assert.Nil(t, fail(customCB))
 	}
 	assert.Equal(t, StateClosed, customCB.State())
-	assert.Equal(t, Counts{10, 5, 5, 0, 1}, customCB.counts)
+	assert.Equal(t, Counts{10, 5, 5, 0, 1}, customCB.counts.snapshot())
 
 	pseudoSleep(customCB, time.Duration(29)*time.Second)
 	assert.Nil(t, succeed(customCB))
 	assert.Equal(t, StateClosed, customCB.State())
-	assert.Equal(t, Counts{11, 6, 5, 1, 0}, customCB.counts)
+	assert.Equal(t, Counts{11, 6, 5, 1, 0}, customCB.counts.snapshot())
 
 	pseudoSleep(customCB, time.Duration(1)*time.Second) // over Interval
 	assert.Nil(t, fail(customCB))
 	assert.Equal(t, StateClosed, customCB.State())
-	assert.Equal(t, Counts{1, 0, 1, 0, 1}, customCB.counts)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, customCB.counts.snapshot())
 
 	// StateClosed to StateOpen
 	assert.Nil(t, succeed(customCB))
 	assert.Nil(t, fail(customCB)) // failure ratio: 2/3 >= 0.6
 	assert.Equal(t, StateOpen, customCB.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts.snapshot())
 	assert.False(t, customCB.expiry.IsZero())
 	assert.Equal(t, StateChange{"cb", StateClosed, StateOpen}, stateChange)
 
@@ -252,16 +284,16 @@ func TestCustomCircuitBreaker(t *testing.T) {
 	assert.Nil(t, succeed(customCB))
 	assert.Nil(t, succeed(customCB))
 	assert.Equal(t, StateHalfOpen, customCB.State())
-	assert.Equal(t, Counts{2, 2, 0, 2, 0}, customCB.counts)
+	assert.Equal(t, Counts{2, 2, 0, 2, 0}, customCB.counts.snapshot())
 
 	// StateHalfOpen to StateClosed
 	ch := succeedLater(customCB, time.Duration(100)*time.Millisecond) // 3 consecutive successes
 	time.Sleep(time.Duration(50) * time.Millisecond)
-	assert.Equal(t, Counts{3, 2, 0, 2, 0}, customCB.counts)
+	assert.Equal(t, Counts{3, 2, 0, 2, 0}, customCB.counts.snapshot())
 	assert.Error(t, succeed(customCB)) // over MaxRequests
 	assert.Nil(t, <-ch)
 	assert.Equal(t, StateClosed, customCB.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts.snapshot())
 	assert.False(t, customCB.expiry.IsZero())
 	assert.Equal(t, StateChange{"cb", StateHalfOpen, StateClosed}, stateChange)
 }
@@ -275,27 +307,27 @@ func TestTwoStepCircuitBreaker(t *testing.T) {
 	}
 
 	assert.Equal(t, StateClosed, tscb.State())
-	assert.Equal(t, Counts{5, 0, 5, 0, 5}, tscb.cb.counts)
+	assert.Equal(t, Counts{5, 0, 5, 0, 5}, tscb.cb.counts.snapshot())
 
 	assert.Nil(t, succeed2Step(tscb))
 	assert.Equal(t, StateClosed, tscb.State())
-	assert.Equal(t, Counts{6, 1, 5, 1, 0}, tscb.cb.counts)
+	assert.Equal(t, Counts{6, 1, 5, 1, 0}, tscb.cb.counts.snapshot())
 
 	assert.Nil(t, fail2Step(tscb))
 	assert.Equal(t, StateClosed, tscb.State())
-	assert.Equal(t, Counts{7, 1, 6, 0, 1}, tscb.cb.counts)
+	assert.Equal(t, Counts{7, 1, 6, 0, 1}, tscb.cb.counts.snapshot())
 
 	// StateClosed to StateOpen
 	for i := 0; i < 5; i++ {
 		assert.Nil(t, fail2Step(tscb)) // 6 consecutive failures
 	}
 	assert.Equal(t, StateOpen, tscb.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts.snapshot())
 	assert.False(t, tscb.cb.expiry.IsZero())
 
 	assert.Error(t, succeed2Step(tscb))
 	assert.Error(t, fail2Step(tscb))
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts.snapshot())
 
 	pseudoSleep(tscb.cb, time.Duration(59)*time.Second)
 	assert.Equal(t, StateOpen, tscb.State())
@@ -308,7 +340,7 @@ func TestTwoStepCircuitBreaker(t *testing.T) {
 	// StateHalfOpen to StateOpen
 	assert.Nil(t, fail2Step(tscb))
 	assert.Equal(t, StateOpen, tscb.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts.snapshot())
 	assert.False(t, tscb.cb.expiry.IsZero())
 
 	// StateOpen to StateHalfOpen
@@ -319,13 +351,13 @@ func TestTwoStepCircuitBreaker(t *testing.T) {
 	// StateHalfOpen to StateClosed
 	assert.Nil(t, succeed2Step(tscb))
 	assert.Equal(t, StateClosed, tscb.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, tscb.cb.counts.snapshot())
 	assert.True(t, tscb.cb.expiry.IsZero())
 }
 
 func TestPanicInRequest(t *testing.T) {
 	assert.Panics(t, func() { causePanic(defaultCB) })
-	assert.Equal(t, Counts{1, 0, 1, 0, 1}, defaultCB.counts)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, defaultCB.counts.snapshot())
 }
 
 func TestGeneration(t *testing.T) {
@@ -333,15 +365,15 @@ func TestGeneration(t *testing.T) {
 	assert.Nil(t, succeed(customCB))
 	ch := succeedLater(customCB, time.Duration(1500)*time.Millisecond)
 	time.Sleep(time.Duration(500) * time.Millisecond)
-	assert.Equal(t, Counts{2, 1, 0, 1, 0}, customCB.counts)
+	assert.Equal(t, Counts{2, 1, 0, 1, 0}, customCB.counts.snapshot())
 
 	time.Sleep(time.Duration(500) * time.Millisecond) // over Interval
 	assert.Equal(t, StateClosed, customCB.State())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts.snapshot())
 
 	// the request from the previous generation has no effect on customCB.counts
 	assert.Nil(t, <-ch)
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, customCB.counts.snapshot())
 }
 
 func TestCustomIsSuccessful(t *testing.T) {
@@ -354,7 +386,7 @@ func TestCustomIsSuccessful(t *testing.T) {
 		assert.Nil(t, fail(cb))
 	}
 	assert.Equal(t, StateClosed, cb.State())
-	assert.Equal(t, Counts{5, 5, 0, 5, 0}, cb.counts)
+	assert.Equal(t, Counts{5, 5, 0, 5, 0}, cb.counts.snapshot())
 
 	cb.counts.clear()
 
@@ -390,5 +422,511 @@ func TestCircuitBreakerInParallel(t *testing.T) {
 		err := <-ch
 		assert.Nil(t, err)
 	}
-	assert.Equal(t, Counts{total, total, 0, total, 0}, customCB.counts)
+	assert.Equal(t, Counts{total, total, 0, total, 0}, customCB.counts.snapshot())
+}
+
+func TestRejectionError(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "re"})
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+
+	err := succeed(cb)
+	assert.True(t, errors.Is(err, ErrOpenState))
+	assert.False(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, ErrOpenState.Error(), err.Error())
+
+	var re *RejectionError
+	assert.True(t, errors.As(err, &re))
+	assert.Equal(t, "re", re.Name)
+	assert.Equal(t, StateOpen, re.State)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, re.Counts)
+	assert.True(t, re.RetryAfter > time.Duration(59)*time.Second)
+	assert.True(t, re.RetryAfter <= time.Duration(60)*time.Second)
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	ch := succeedLater(cb, time.Duration(100)*time.Millisecond)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	err = succeed(cb)
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.True(t, errors.As(err, &re))
+	assert.Equal(t, StateHalfOpen, re.State)
+	assert.Equal(t, Counts{1, 0, 0, 0, 0}, re.Counts)
+	assert.Equal(t, time.Duration(0), re.RetryAfter)
+	assert.Nil(t, <-ch)
+}
+
+func TestReportExternalFailure(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	for i := 0; i < 5; i++ {
+		cb.ReportExternalFailure("pool exhausted")
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{5, 0, 5, 0, 5}, cb.counts.snapshot())
+
+	cb.ReportExternalFailure("pool exhausted") // 6 consecutive failures
+	assert.Equal(t, StateOpen, cb.State())
+
+	cb.ReportExternalFailure("pool exhausted") // ignored in the open state
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.counts.snapshot())
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	cb.ReportExternalFailure("pool exhausted")
+	assert.Equal(t, StateOpen, cb.State())
+
+	var reasons []string
+	tscb := NewTwoStepCircuitBreaker(Settings{
+		ReadyToTripExternal: func(reason string, counts Counts) bool {
+			reasons = append(reasons, reason)
+			return reason == "pool exhausted"
+		},
+	})
+	tscb.ReportExternalFailure("slow dial")
+	assert.Equal(t, StateClosed, tscb.State())
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, tscb.Counts())
+	tscb.ReportExternalFailure("pool exhausted")
+	assert.Equal(t, StateOpen, tscb.State())
+	assert.Equal(t, []string{"slow dial", "pool exhausted"}, reasons)
+}
+
+func TestOnStateChangeDetailed(t *testing.T) {
+	var events []StateChangeEvent
+	cb := NewCircuitBreaker(Settings{
+		Name: "detailed",
+		OnStateChangeDetailed: func(ev StateChangeEvent) {
+			events = append(events, ev)
+		},
+	})
+
+	assert.Nil(t, succeed(cb))
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "detailed", events[0].Name)
+	assert.Equal(t, StateClosed, events[0].From)
+	assert.Equal(t, StateOpen, events[0].To)
+	assert.Equal(t, Counts{7, 1, 6, 0, 6}, events[0].Counts)
+	assert.Equal(t, uint64(2), events[0].Generation)
+	assert.False(t, events[0].Time.IsZero())
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, StateOpen, events[1].From)
+	assert.Equal(t, StateHalfOpen, events[1].To)
+	assert.Equal(t, uint64(3), events[1].Generation)
+}
+
+func TestSchedule(t *testing.T) {
+	peak := true
+	cb := NewCircuitBreaker(Settings{
+		MaxRequests: 2,
+		Interval:    time.Duration(10) * time.Second,
+		Schedule: func(now time.Time) Thresholds {
+			if !peak {
+				return Thresholds{}
+			}
+			return Thresholds{
+				MaxRequests: 5,
+				ReadyToTrip: func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 },
+			}
+		},
+	})
+	assert.Equal(t, uint32(5), cb.maxRequests)
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, uint32(5), cb.maxRequests)
+
+	// the schedule is evaluated at the next generation
+	peak = false
+	assert.Equal(t, uint32(5), cb.maxRequests)
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Equal(t, uint32(2), cb.maxRequests)
+
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State()) // default ReadyToTrip
+}
+
+func TestScheduleReconfigure(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &stepClock{now: start}
+	cb := NewCircuitBreaker(Settings{
+		Clock:    clock,
+		Interval: time.Duration(60) * time.Second,
+		Schedule: func(now time.Time) Thresholds {
+			if now.Before(start.Add(time.Duration(30) * time.Second)) {
+				return Thresholds{MaxRequests: 5}
+			}
+			return Thresholds{}
+		},
+	})
+	assert.Equal(t, uint32(5), cb.maxRequests)
+
+	// the schedule is evaluated at the start of the current generation, not when reconfigured
+	clock.advance(time.Duration(40) * time.Second)
+	cb.SetMaxRequests(3)
+	assert.Equal(t, uint32(5), cb.maxRequests)
+	cb.SetReadyToTrip(nil)
+	assert.Equal(t, uint32(5), cb.maxRequests)
+
+	clock.advance(time.Duration(30) * time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint32(3), cb.maxRequests)
+}
+
+func TestDisablePanicRecovery(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{DisablePanicRecovery: true})
+
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		causePanic(cb)
+	}()
+	assert.Equal(t, "oops", recovered)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.counts.snapshot())
+
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{3, 1, 2, 0, 1}, cb.counts.snapshot())
+}
+
+func TestOnCallComplete(t *testing.T) {
+	type call struct {
+		name  string
+		err   error
+		state State
+	}
+	var calls []call
+	var durations []time.Duration
+	cb := NewCircuitBreaker(Settings{
+		Name: "complete",
+		OnCallComplete: func(name string, d time.Duration, err error, state State) {
+			calls = append(calls, call{name, err, state})
+			durations = append(durations, d)
+		},
+	})
+
+	<-succeedLater(cb, time.Duration(20)*time.Millisecond)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, 2, len(calls))
+	assert.Equal(t, call{"complete", nil, StateClosed}, calls[0])
+	assert.True(t, durations[0] >= time.Duration(20)*time.Millisecond)
+	assert.Equal(t, "fail", calls[1].err.Error())
+
+	assert.Panics(t, func() { causePanic(cb) })
+	assert.Equal(t, 2, len(calls))
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Error(t, succeed(cb)) // rejected
+	assert.Equal(t, 6, len(calls))
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateHalfOpen, calls[6].state)
+}
+
+func TestZeroValueCircuitBreaker(t *testing.T) {
+	var cb CircuitBreaker
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint32(1), cb.maxRequests)
+	assert.Equal(t, defaultTimeout, cb.timeout)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(&cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(&cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestZeroValueCircuitBreakerInParallel(t *testing.T) {
+	var cb CircuitBreaker
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = succeed(&cb)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, Counts{10, 10, 0, 10, 0}, cb.Counts())
+}
+
+func TestHalfOpenProbeInterval(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{MaxRequests: 3, HalfOpenProbeInterval: time.Duration(1) * time.Second})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+	done, err := tscb.Allow()
+	assert.Nil(t, err)
+	assert.True(t, cb.nextProbe.After(time.Now().Add(time.Duration(499)*time.Millisecond)))
+	assert.True(t, cb.nextProbe.Before(time.Now().Add(time.Duration(1501)*time.Millisecond)))
+
+	_, err = tscb.Allow()
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	done(true)
+
+	cb.nextProbe = cb.nextProbe.Add(time.Duration(-2) * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{2, 2, 0, 2, 0}, cb.Counts())
+
+	// the delay is cleared on the change of the state
+	cb.nextProbe = cb.nextProbe.Add(time.Duration(-2) * time.Second)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.True(t, cb.nextProbe.IsZero())
+}
+
+func TestIsPanicFailure(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		IsPanicFailure: func(recovered interface{}) bool { return recovered != "oops" },
+	})
+
+	assert.Panics(t, func() { _ = causePanic(cb) })
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+	assert.Panics(t, func() {
+		_, _ = cb.Execute(func() (interface{}, error) { panic("connection lost") })
+	})
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+}
+
+func TestOnReject(t *testing.T) {
+	type rejection struct {
+		name  string
+		state State
+		err   error
+	}
+	var rejections []rejection
+	cb := NewCircuitBreaker(Settings{
+		Name: "reject",
+		OnReject: func(name string, state State, err error) {
+			rejections = append(rejections, rejection{name, state, err})
+		},
+	})
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, 0, len(rejections))
+
+	err := succeed(cb)
+	assert.Equal(t, 1, len(rejections))
+	assert.Equal(t, "reject", rejections[0].name)
+	assert.Equal(t, StateOpen, rejections[0].state)
+	assert.Equal(t, err, rejections[0].err)
+	assert.True(t, errors.Is(rejections[0].err, ErrOpenState))
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+	done, err := tscb.Allow()
+	assert.Nil(t, err)
+	_, err = tscb.Allow()
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, 2, len(rejections))
+	assert.Equal(t, StateHalfOpen, rejections[1].state)
+	done(true)
+}
+
+func TestBeforeStateChange(t *testing.T) {
+	type transition struct {
+		from, to State
+	}
+	var transitions []transition
+	freeze := true
+	cb := NewCircuitBreaker(Settings{
+		BeforeStateChange: func(name string, from, to State, counts Counts) bool {
+			transitions = append(transitions, transition{from, to})
+			return !freeze || to != StateClosed
+		},
+	})
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	// closing is vetoed and the CircuitBreaker probes again
+	generation := cb.generation
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Equal(t, generation+1, cb.generation)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	assert.Equal(t, []transition{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}, transitions)
+
+	freeze = false
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	// the manual operations are never vetoed
+	freeze = true
+	cb.Trip()
+	cb.Reset()
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, 4, len(transitions))
+}
+
+func TestBeforeStateChangeOpen(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		BeforeStateChange: func(name string, from, to State, counts Counts) bool { return from != StateOpen },
+	})
+
+	cb.Trip()
+	expiry := cb.Expiry()
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.True(t, cb.Expiry().After(expiry.Add(time.Duration(-60)*time.Second)))
+	assert.True(t, cb.Expiry().After(time.Now()))
+}
+
+func TestFailOpenRatio(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{FailOpenRatio: 1})
+	cb.Trip()
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Counts{2, 0, 2, 0, 2}, cb.Counts())
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	cb.UpdateSettings(Settings{FailOpenRatio: 0.5})
+	cb.Trip()
+	var allowed int
+	for i := 0; i < 1000; i++ {
+		if err := fail(cb); err == nil {
+			allowed++
+		}
+	}
+	assert.True(t, allowed > 350 && allowed < 650)
+	assert.Equal(t, StateOpen, cb.State())
+
+	err := Settings{FailOpenRatio: 1.5}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
+
+func TestShadowMode(t *testing.T) {
+	var changes []State
+	var rejections int
+	cb := NewCircuitBreaker(Settings{
+		ShadowMode:    true,
+		OnStateChange: func(_ string, _ State, to State) { changes = append(changes, to) },
+		OnReject:      func(_ string, _ State, _ error) { rejections++ },
+	})
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, []State{StateOpen}, changes)
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	assert.Equal(t, 2, rejections)
+
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed}, changes)
+	assert.Equal(t, 2, rejections)
+}
+
+func TestHalfOpenSuccessRatio(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{HalfOpenMaxTotal: 20, HalfOpenSuccessRatio: 0.9})
+	probe := func(successes, failures int) {
+		for i := 0; i < successes; i++ {
+			assert.Nil(t, succeed(cb))
+		}
+		for i := 0; i < failures; i++ {
+			assert.Nil(t, fail(cb))
+		}
+	}
+
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	probe(10, 1)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	probe(8, 1)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, CauseHalfOpenSuccess, cb.LastTransition().Cause)
+
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	probe(17, 3)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, CauseHalfOpenFailure, cb.LastTransition().Cause)
+
+	err := Settings{HalfOpenSuccessRatio: 1.5}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
+
+func TestTimeoutJitter(t *testing.T) {
+	expiries := make(map[time.Time]bool)
+	for i := 0; i < 20; i++ {
+		cb := NewCircuitBreaker(Settings{TimeoutJitter: time.Duration(30) * time.Second})
+		start := time.Now()
+		cb.Trip()
+		end := time.Now()
+
+		assert.False(t, cb.expiry.Before(start.Add(time.Duration(60)*time.Second)))
+		assert.False(t, cb.expiry.After(end.Add(time.Duration(90)*time.Second)))
+		expiries[cb.expiry] = true
+	}
+	assert.True(t, len(expiries) > 1)
+
+	cb := NewCircuitBreaker(Settings{TimeoutJitter: time.Duration(30) * time.Second})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(91)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	err := Settings{TimeoutJitter: -time.Second}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
+
+func TestLockedRand(t *testing.T) {
+	r := newLockedRand()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.True(t, r.Float64() < 1)
+				assert.True(t, r.Int63n(10) < 10)
+			}
+		}()
+	}
+	wg.Wait()
 }
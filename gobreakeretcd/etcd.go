@@ -0,0 +1,71 @@
+// Package gobreakeretcd provides a gobreaker.ProbeElector holding the leases of the probes on etcd,
+// so that only one of the replicas of a service probes a recovering dependency.
+//
+// gobreakeretcd is a separate module, so that gobreaker itself doesn't depend on etcd.
+package gobreakeretcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/sony/gobreaker"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// DefaultPrefix is the prefix of the keys of the leases by default.
+const DefaultPrefix = "/gobreaker/probe/"
+
+// Elector is a gobreaker.ProbeElector holding the lease of each CircuitBreaker as the key of etcd
+// named Prefix and the name of the CircuitBreaker, whose value is Owner and which is attached to a lease of etcd
+// of the ttl of the lease, rounded up to seconds.
+//
+// Owner identifies the replica, e.g. the hostname, and must be unique among the replicas.
+// If Prefix is empty, DefaultPrefix is used.
+type Elector struct {
+	Client *clientv3.Client
+	Owner  string
+	Prefix string
+}
+
+var _ gobreaker.ProbeElector = Elector{}
+
+// Elect implements gobreaker.ProbeElector.
+// The key is created in a transaction only if it doesn't exist, and the holder of the key keeps its lease alive.
+func (e Elector) Elect(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	key := prefix + name
+
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	if seconds <= 0 {
+		seconds = 1
+	}
+	lease, err := e.Client.Grant(ctx, seconds)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := e.Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, e.Owner, clientv3.WithLease(lease.ID))).
+		Else(clientv3.OpGet(key)).
+		Commit()
+	if err == nil && resp.Succeeded {
+		return true, nil
+	}
+	e.Client.Revoke(ctx, lease.ID)
+	if err != nil {
+		return false, err
+	}
+
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 || string(kvs[0].Value) != e.Owner {
+		return false, nil
+	}
+	if _, err := e.Client.KeepAliveOnce(ctx, clientv3.LeaseID(kvs[0].Lease)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
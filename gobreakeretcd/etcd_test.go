@@ -0,0 +1,96 @@
+package gobreakeretcd
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startEtcd starts an embedded etcd and returns a client of it.
+func startEtcd(t *testing.T) *clientv3.Client {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	local, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenClientUrls, cfg.AdvertiseClientUrls = []url.URL{*local}, []url.URL{*local}
+	cfg.ListenPeerUrls, cfg.AdvertisePeerUrls = []url.URL{*local}, []url.URL{*local}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(time.Duration(10) * time.Second):
+		t.Fatal("etcd not ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{e.Clients[0].Addr().String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestElector(t *testing.T) {
+	client := startEtcd(t)
+	ctx := context.Background()
+	a := Elector{Client: client, Owner: "a"}
+	b := Elector{Client: client, Owner: "b"}
+
+	elected, err := a.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+	resp, err := client.Get(ctx, DefaultPrefix+"db")
+	assert.Nil(t, err)
+	assert.Equal(t, "a", string(resp.Kvs[0].Value))
+
+	elected, err = b.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, elected)
+
+	elected, err = a.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+
+	elected, err = b.Elect(ctx, "cache", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+
+	// the lease of "a" is gone with its key
+	_, err = client.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
+	assert.Nil(t, err)
+	elected, err = b.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+}
+
+func TestElectorCircuitBreaker(t *testing.T) {
+	client := startEtcd(t)
+	replica := func(owner string) *gobreaker.CircuitBreaker {
+		return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:         "db",
+			Timeout:      time.Millisecond,
+			ProbeElector: Elector{Client: client, Owner: owner, Prefix: "/test/"},
+		})
+	}
+	a, b := replica("a"), replica("b")
+	a.Trip()
+	b.Trip()
+	time.Sleep(time.Duration(5) * time.Millisecond)
+
+	probe := func() (interface{}, error) { return nil, nil }
+	_, err := a.Execute(probe)
+	assert.Nil(t, err)
+	_, err = b.Execute(probe)
+	assert.Equal(t, gobreaker.ErrOpenState, gobreaker.RejectionReason(err))
+}
@@ -0,0 +1,167 @@
+// Package gobreakergossip provides a gobreaker.Coordinator exchanging the states of the CircuitBreakers
+// among the instances of a service by gossip with memberlist.
+//
+// gobreakergossip is a separate module, so that gobreaker itself doesn't depend on memberlist.
+package gobreakergossip
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/sony/gobreaker"
+)
+
+// Coordinator is a gobreaker.Coordinator based on a memberlist.Memberlist.
+// The name of the local memberlist node is the instance ID of gobreaker.ClusterEvent,
+// and a node leaving the memberlist is delivered as a ClusterEvent with the empty Name.
+type Coordinator struct {
+	list  *memberlist.Memberlist
+	queue *memberlist.TransmitLimitedQueue
+
+	mutex     sync.Mutex
+	receivers map[int]func(ev gobreaker.ClusterEvent)
+	next      int
+}
+
+// New creates a memberlist.Memberlist with conf, joins the cluster through the existing members, if any,
+// and returns a Coordinator on it.
+// conf.Delegate and conf.Events are overwritten by the Coordinator.
+func New(conf *memberlist.Config, existing []string) (*Coordinator, error) {
+	c := &Coordinator{receivers: make(map[int]func(ev gobreaker.ClusterEvent))}
+	conf.Delegate = delegate{c}
+	conf.Events = events{c}
+
+	list, err := memberlist.Create(conf)
+	if err != nil {
+		return nil, err
+	}
+	c.list = list
+	c.queue = &memberlist.TransmitLimitedQueue{
+		NumNodes:       list.NumMembers,
+		RetransmitMult: conf.RetransmitMult,
+	}
+
+	if len(existing) > 0 {
+		if _, err := list.Join(existing); err != nil {
+			list.Shutdown()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Memberlist returns the underlying memberlist.Memberlist.
+func (c *Coordinator) Memberlist() *memberlist.Memberlist {
+	return c.list
+}
+
+// Broadcast implements gobreaker.Coordinator.
+// ev is queued and piggybacked on the gossip messages of memberlist.
+func (c *Coordinator) Broadcast(ev gobreaker.ClusterEvent) error {
+	msg, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	c.queue.QueueBroadcast(broadcast(msg))
+	return nil
+}
+
+// Watch implements gobreaker.Coordinator.
+// Watch always returns the error of ctx.
+func (c *Coordinator) Watch(ctx context.Context, receive func(ev gobreaker.ClusterEvent)) error {
+	c.mutex.Lock()
+	id := c.next
+	c.next++
+	c.receivers[id] = receive
+	c.mutex.Unlock()
+
+	<-ctx.Done()
+
+	c.mutex.Lock()
+	delete(c.receivers, id)
+	c.mutex.Unlock()
+	return ctx.Err()
+}
+
+// Leave broadcasts the leave of the local node and shuts down the memberlist.
+func (c *Coordinator) Leave(ctx context.Context) error {
+	timeout := defaultLeaveTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if err := c.list.Leave(timeout); err != nil {
+		return err
+	}
+	return c.list.Shutdown()
+}
+
+func (c *Coordinator) deliver(ev gobreaker.ClusterEvent) {
+	c.mutex.Lock()
+	receivers := make([]func(ev gobreaker.ClusterEvent), 0, len(c.receivers))
+	for _, receive := range c.receivers {
+		receivers = append(receivers, receive)
+	}
+	c.mutex.Unlock()
+
+	for _, receive := range receivers {
+		receive(ev)
+	}
+}
+
+const defaultLeaveTimeout = time.Duration(5) * time.Second
+
+// broadcast is a memberlist.Broadcast of an encoded gobreaker.ClusterEvent.
+type broadcast []byte
+
+func (b broadcast) Invalidates(other memberlist.Broadcast) bool {
+	return false
+}
+
+func (b broadcast) Message() []byte {
+	return b
+}
+
+func (b broadcast) Finished() {}
+
+// delegate receives the ClusterEvents gossiped by the other nodes.
+type delegate struct {
+	c *Coordinator
+}
+
+func (d delegate) NodeMeta(limit int) []byte {
+	return nil
+}
+
+func (d delegate) NotifyMsg(msg []byte) {
+	var ev gobreaker.ClusterEvent
+	if err := json.Unmarshal(msg, &ev); err != nil {
+		return
+	}
+	d.c.deliver(ev)
+}
+
+func (d delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.c.queue.GetBroadcasts(overhead, limit)
+}
+
+func (d delegate) LocalState(join bool) []byte {
+	return nil
+}
+
+func (d delegate) MergeRemoteState(buf []byte, join bool) {}
+
+// events turns the leaves of the nodes into ClusterEvents.
+type events struct {
+	c *Coordinator
+}
+
+func (e events) NotifyJoin(node *memberlist.Node) {}
+
+func (e events) NotifyLeave(node *memberlist.Node) {
+	e.c.deliver(gobreaker.ClusterEvent{Instance: node.Name})
+}
+
+func (e events) NotifyUpdate(node *memberlist.Node) {}
@@ -0,0 +1,72 @@
+package gobreakergossip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCoordinator(t *testing.T, name string, existing []string) *Coordinator {
+	conf := memberlist.DefaultLocalConfig()
+	conf.Name = name
+	conf.BindAddr = "127.0.0.1"
+	conf.BindPort = 0
+	conf.AdvertisePort = 0
+	conf.GossipInterval = time.Duration(10) * time.Millisecond
+	conf.Logger = log.New(io.Discard, "", 0)
+
+	c, err := New(conf, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Duration(5) * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Duration(10) * time.Millisecond)
+	}
+}
+
+func TestCoordinator(t *testing.T) {
+	a := newCoordinator(t, "a", nil)
+	addr := fmt.Sprintf("127.0.0.1:%d", a.Memberlist().LocalNode().Port)
+	b := newCoordinator(t, "b", []string{addr})
+	c := newCoordinator(t, "c", []string{addr})
+	defer b.Leave(context.Background())
+	defer c.Leave(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cbs := map[string]*gobreaker.CircuitBreaker{}
+	clusters := map[string]*gobreaker.Cluster{}
+	for name, coord := range map[string]*Coordinator{"a": a, "b": b, "c": c} {
+		cbs[name] = gobreaker.NewCircuitBreaker(gobreaker.Settings{Name: "db"})
+		clusters[name] = gobreaker.NewCluster(cbs[name], name, 1, coord)
+		go clusters[name].Run(ctx)
+	}
+
+	cbs["a"].Trip()
+	eventually(t, func() bool {
+		return cbs["b"].State() == gobreaker.StateOpen && cbs["c"].State() == gobreaker.StateOpen
+	})
+
+	// b and c are free to recover when a leaves
+	assert.Equal(t, 1, clusters["b"].Open())
+	assert.Nil(t, a.Leave(context.Background()))
+	eventually(t, func() bool { return clusters["b"].Open() == 0 })
+	cbs["b"].Reset()
+	assert.Equal(t, gobreaker.StateClosed, cbs["b"].State())
+}
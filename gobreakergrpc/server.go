@@ -0,0 +1,95 @@
+// Package gobreakergrpc protects gRPC servers with gobreaker.
+//
+// UnaryServerInterceptor and StreamServerInterceptor keep a CircuitBreaker per method in a gobreaker.Group,
+// named by the full method name, e.g. "/package.Service/Method", so that a pathological method is shed alone
+// while the other methods of the service keep serving.
+// While the CircuitBreaker of a method rejects the calls, they fail with codes.Unavailable without calling
+// the handler, and the trailer "grpc-retry-pushback-ms" tells the clients retrying by the gRPC retry policy when to retry.
+//
+// gobreakergrpc is a separate module, so that gobreaker itself doesn't depend on gRPC.
+package gobreakergrpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// IsFailure decides whether a call of the method that returned err is a failure of the handler.
+type IsFailure func(method string, err error) bool
+
+// DefaultIsFailure counts the errors with the codes that indicate a problem of the server as failures:
+// Unknown, DeadlineExceeded, ResourceExhausted, Internal, Unavailable and DataLoss.
+// The errors caused by the clients, such as InvalidArgument and NotFound, are not failures.
+func DefaultIsFailure(method string, err error) bool {
+	switch status.Code(err) {
+	case codes.Unknown, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor protecting every method with its CircuitBreaker in g.
+// If isFailure is nil, DefaultIsFailure is used. A panic in the handler is counted as a failure.
+func UnaryServerInterceptor(g *gobreaker.Group, isFailure IsFailure) grpc.UnaryServerInterceptor {
+	if isFailure == nil {
+		isFailure = DefaultIsFailure
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var ran bool
+		resp, err := execute(g, info.FullMethod, isFailure, func() (interface{}, error) {
+			ran = true
+			return handler(ctx, req)
+		})
+		if err != nil && !ran {
+			return nil, unavailable(ctx, info.FullMethod, err)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor protecting every method with its CircuitBreaker in g.
+// The outcome of a stream is counted when its handler returns, i.e. a stream is a single call however many messages it carries.
+// If isFailure is nil, DefaultIsFailure is used. A panic in the handler is counted as a failure.
+func StreamServerInterceptor(g *gobreaker.Group, isFailure IsFailure) grpc.StreamServerInterceptor {
+	if isFailure == nil {
+		isFailure = DefaultIsFailure
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var ran bool
+		_, err := execute(g, info.FullMethod, isFailure, func() (interface{}, error) {
+			ran = true
+			return nil, handler(srv, ss)
+		})
+		if err != nil && !ran {
+			return unavailable(ss.Context(), info.FullMethod, err)
+		}
+		return err
+	}
+}
+
+func execute(g *gobreaker.Group, method string, isFailure IsFailure, req func() (interface{}, error)) (interface{}, error) {
+	return g.Get(method).ExecuteWithOptions(req, gobreaker.WithIsSuccessful(func(err error) bool {
+		return !isFailure(method, err)
+	}))
+}
+
+// unavailable returns the error of codes.Unavailable for the call of the method rejected with err,
+// and sets the trailer "grpc-retry-pushback-ms" from the RetryAfter of err.
+func unavailable(ctx context.Context, method string, err error) error {
+	var re *gobreaker.RejectionError
+	if errors.As(err, &re) && re.RetryAfter > 0 {
+		pushback := strconv.FormatInt(re.RetryAfter.Milliseconds(), 10)
+		grpc.SetTrailer(ctx, metadata.Pairs("grpc-retry-pushback-ms", pushback))
+	}
+	return status.Errorf(codes.Unavailable, "gobreaker: %s: %v", method, err)
+}
@@ -0,0 +1,91 @@
+package gobreakergrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newGroup() *gobreaker.Group {
+	return gobreaker.NewGroup(gobreaker.Settings{
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	g := newGroup()
+	intercept := UnaryServerInterceptor(g, nil)
+	slow := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Slow"}
+	fast := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Fast"}
+
+	var calls int
+	failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, status.Error(codes.Internal, "boom")
+	}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	invalid := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := intercept(context.Background(), nil, slow, failing)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	}
+	assert.Equal(t, gobreaker.StateOpen, g.Get(slow.FullMethod).State())
+
+	_, err := intercept(context.Background(), nil, slow, failing)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 2, calls)
+
+	for i := 0; i < 3; i++ {
+		_, err = intercept(context.Background(), nil, fast, invalid)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+	resp, err := intercept(context.Background(), nil, fast, ok)
+	assert.Equal(t, "ok", resp)
+	assert.Nil(t, err)
+	assert.Equal(t, gobreaker.StateClosed, g.Get(fast.FullMethod).State())
+}
+
+type serverStream struct {
+	grpc.ServerStream
+}
+
+func (s serverStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	g := newGroup()
+	intercept := StreamServerInterceptor(g, func(method string, err error) bool { return err != nil })
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch", IsServerStream: true}
+
+	failing := func(srv interface{}, ss grpc.ServerStream) error {
+		return status.Error(codes.Unknown, "broken stream")
+	}
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, codes.Unknown, status.Code(intercept(nil, serverStream{}, info, failing)))
+	}
+
+	var ran bool
+	err := intercept(nil, serverStream{}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		ran = true
+		return nil
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.False(t, ran)
+}
+
+func TestDefaultIsFailure(t *testing.T) {
+	assert.False(t, DefaultIsFailure("/m", nil))
+	assert.False(t, DefaultIsFailure("/m", status.Error(codes.NotFound, "")))
+	assert.True(t, DefaultIsFailure("/m", status.Error(codes.Unavailable, "")))
+}
@@ -0,0 +1,225 @@
+// Package gobreakerhttp provides net/http integrations of gobreaker.
+package gobreakerhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// BreakerStatus is the JSON representation of a CircuitBreaker served by AdminHandler.
+type BreakerStatus struct {
+	Name     string     `json:"name"`
+	State    string     `json:"state"`
+	Counts   Counts     `json:"counts"`
+	Expiry   *time.Time `json:"expiry,omitempty"`
+	Disabled bool       `json:"disabled,omitempty"`
+	Interval string     `json:"interval,omitempty"`
+}
+
+// Counts is the JSON representation of gobreaker.Counts.
+type Counts struct {
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"totalSuccesses"`
+	TotalFailures        uint32 `json:"totalFailures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
+}
+
+// CallRecord is the JSON representation of gobreaker.CallRecord.
+type CallRecord struct {
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	State    string    `json:"state"`
+	Verdict  string    `json:"verdict"`
+	Err      string    `json:"err,omitempty"`
+}
+
+// TripDump is the JSON representation of gobreaker.TripDump served by AdminHandler.
+type TripDump struct {
+	Name       string       `json:"name"`
+	Time       time.Time    `json:"time"`
+	From       string       `json:"from"`
+	Generation uint64       `json:"generation"`
+	Counts     Counts       `json:"counts"`
+	MeanTime   string       `json:"meanTime"`
+	MaxTime    string       `json:"maxTime"`
+	Calls      []CallRecord `json:"calls"`
+}
+
+// NewTripDump returns the TripDump of the given CircuitBreaker captured by its last trip.
+func NewTripDump(cb *gobreaker.CircuitBreaker) (TripDump, bool) {
+	d, ok := cb.LastTripDump()
+	if !ok {
+		return TripDump{}, false
+	}
+
+	dump := TripDump{
+		Name:       cb.Name(),
+		Time:       d.Time,
+		From:       d.From.String(),
+		Generation: d.Generation,
+		Counts:     newCounts(d.Counts),
+		MeanTime:   d.Stats.Mean.String(),
+		MaxTime:    d.Stats.Max.String(),
+		Calls:      make([]CallRecord, 0, len(d.Calls)),
+	}
+	for _, c := range d.Calls {
+		dump.Calls = append(dump.Calls, CallRecord{
+			Time:     c.Time,
+			Duration: c.Duration.String(),
+			State:    c.State.String(),
+			Verdict:  c.Verdict.String(),
+			Err:      c.Err,
+		})
+	}
+	return dump, true
+}
+
+func newCounts(c gobreaker.Counts) Counts {
+	return Counts{
+		Requests:             c.Requests,
+		TotalSuccesses:       c.TotalSuccesses,
+		TotalFailures:        c.TotalFailures,
+		ConsecutiveSuccesses: c.ConsecutiveSuccesses,
+		ConsecutiveFailures:  c.ConsecutiveFailures,
+	}
+}
+
+// NewBreakerStatus returns the BreakerStatus of the given CircuitBreaker.
+func NewBreakerStatus(cb *gobreaker.CircuitBreaker) BreakerStatus {
+	status := BreakerStatus{
+		Name:     cb.Name(),
+		State:    cb.State().String(),
+		Counts:   newCounts(cb.Counts()),
+		Disabled: cb.Disabled(),
+	}
+	if expiry := cb.Expiry(); !expiry.IsZero() {
+		status.Expiry = &expiry
+	}
+	if interval := cb.Interval(); interval > 0 {
+		status.Interval = interval.String()
+	}
+	return status
+}
+
+// AdminHandler is an http.Handler to inspect and operate the CircuitBreakers in a Group,
+// e.g. mounted at /debug/breakers.
+//
+// GET responds with the JSON array of BreakerStatus of all the CircuitBreakers,
+// or the JSON object of a single CircuitBreaker if the query parameter "name" is given.
+// If the query parameter "dump" is also given, GET responds with the TripDump of the CircuitBreaker instead,
+// or 404 if the CircuitBreaker has no TripDump.
+// If the query parameter "format" is "export", GET responds with the Export of all the CircuitBreakers,
+// whose schema is stable for consumers not written in Go,
+// and if "format" is "openmetrics", GET responds with the Export in the OpenMetrics text format.
+//
+// POST with the form values "name" and "action" operates the CircuitBreaker and responds with its BreakerStatus.
+// The action "trip" places the CircuitBreaker into the open state,
+// and the action "reset" places it into the closed state.
+// The actions "disable" and "enable" start and stop bypassing the CircuitBreaker.
+type AdminHandler struct {
+	group *gobreaker.Group
+}
+
+// NewAdminHandler returns a new AdminHandler for the given Group.
+func NewAdminHandler(g *gobreaker.Group) *AdminHandler {
+	return &AdminHandler{group: g}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.get(w, r)
+	case http.MethodPost:
+		h.post(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *AdminHandler) get(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("format") {
+	case "":
+	case "export":
+		writeJSON(w, NewExport(h.breakers()...))
+		return
+	case "openmetrics":
+		w.Header().Set("Content-Type", OpenMetricsContentType)
+		WriteOpenMetrics(w, NewExport(h.breakers()...))
+		return
+	default:
+		http.Error(w, "unknown format", http.StatusBadRequest)
+		return
+	}
+
+	if name := r.URL.Query().Get("name"); name != "" {
+		cb, ok := h.group.Lookup(name)
+		if !ok {
+			http.Error(w, "circuit breaker not found", http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("dump") != "" {
+			dump, ok := NewTripDump(cb)
+			if !ok {
+				http.Error(w, "trip dump not found", http.StatusNotFound)
+				return
+			}
+			writeJSON(w, dump)
+			return
+		}
+		writeJSON(w, NewBreakerStatus(cb))
+		return
+	}
+
+	cbs := h.breakers()
+	statuses := make([]BreakerStatus, 0, len(cbs))
+	for _, cb := range cbs {
+		statuses = append(statuses, NewBreakerStatus(cb))
+	}
+	writeJSON(w, statuses)
+}
+
+// breakers returns the CircuitBreakers in the Group in the order of their names.
+func (h *AdminHandler) breakers() []*gobreaker.CircuitBreaker {
+	names := h.group.Names()
+	cbs := make([]*gobreaker.CircuitBreaker, 0, len(names))
+	for _, name := range names {
+		if cb, ok := h.group.Lookup(name); ok {
+			cbs = append(cbs, cb)
+		}
+	}
+	return cbs
+}
+
+func (h *AdminHandler) post(w http.ResponseWriter, r *http.Request) {
+	cb, ok := h.group.Lookup(r.FormValue("name"))
+	if !ok {
+		http.Error(w, "circuit breaker not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.FormValue("action") {
+	case "trip":
+		cb.Trip()
+	case "reset":
+		cb.Reset()
+	case "disable":
+		cb.Disable()
+	case "enable":
+		cb.Enable()
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, NewBreakerStatus(cb))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,111 @@
+package gobreakerhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(h http.Handler, method, target string, form url.Values) *httptest.ResponseRecorder {
+	var r *http.Request
+	if form == nil {
+		r = httptest.NewRequest(method, target, nil)
+	} else {
+		r = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestAdminHandlerTripDump(t *testing.T) {
+	g := gobreaker.NewGroup(gobreaker.Settings{
+		TripDumpSize: 2,
+		ReadyToTrip:  func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	})
+	for i := 0; i < 2; i++ {
+		g.Execute("a", func() (interface{}, error) { return nil, errors.New("fail") })
+	}
+	h := NewAdminHandler(g)
+
+	w := serve(h, http.MethodGet, "/debug/breakers?name=a&dump=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var dump TripDump
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &dump))
+	assert.Equal(t, "a", dump.Name)
+	assert.Equal(t, "closed", dump.From)
+	assert.Equal(t, Counts{Requests: 2, TotalFailures: 2, ConsecutiveFailures: 2}, dump.Counts)
+	assert.Equal(t, 2, len(dump.Calls))
+	assert.Equal(t, "failure", dump.Calls[1].Verdict)
+	assert.Equal(t, "fail", dump.Calls[1].Err)
+}
+
+func TestAdminHandler(t *testing.T) {
+	g := gobreaker.NewGroup(gobreaker.Settings{})
+	g.Get("b")
+	g.Get("a").Execute(func() (interface{}, error) { return nil, nil })
+	h := NewAdminHandler(g)
+
+	w := serve(h, http.MethodGet, "/debug/breakers", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var statuses []BreakerStatus
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	assert.Equal(t, 2, len(statuses))
+	assert.Equal(t, "a", statuses[0].Name)
+	assert.Equal(t, "closed", statuses[0].State)
+	assert.Equal(t, Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}, statuses[0].Counts)
+	assert.Nil(t, statuses[0].Expiry)
+	assert.Equal(t, "b", statuses[1].Name)
+
+	w = serve(h, http.MethodPost, "/debug/breakers", url.Values{"name": {"a"}, "action": {"trip"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var status BreakerStatus
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "open", status.State)
+	assert.NotNil(t, status.Expiry)
+	assert.Equal(t, gobreaker.StateOpen, g.Get("a").State())
+
+	w = serve(h, http.MethodGet, "/debug/breakers?name=a", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "a", status.Name)
+	assert.Equal(t, "open", status.State)
+
+	w = serve(h, http.MethodGet, "/debug/breakers?name=a&dump=1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(h, http.MethodPost, "/debug/breakers", url.Values{"name": {"a"}, "action": {"reset"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, gobreaker.StateClosed, g.Get("a").State())
+
+	w = serve(h, http.MethodPost, "/debug/breakers", url.Values{"name": {"a"}, "action": {"disable"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.True(t, status.Disabled)
+
+	w = serve(h, http.MethodPost, "/debug/breakers", url.Values{"name": {"a"}, "action": {"enable"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, g.Get("a").Disabled())
+
+	w = serve(h, http.MethodPost, "/debug/breakers", url.Values{"name": {"a"}, "action": {"explode"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(h, http.MethodPost, "/debug/breakers", url.Values{"name": {"c"}, "action": {"trip"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(h, http.MethodGet, "/debug/breakers?name=c", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(h, http.MethodDelete, "/debug/breakers", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, POST", w.Header().Get("Allow"))
+}
@@ -0,0 +1,63 @@
+package gobreakerhttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sony/gobreaker"
+)
+
+// Doer is the interface of the HTTP clients sending a request with Do, e.g. *http.Client,
+// so that the wrappers of the clients such as go-retryablehttp and resty can plug a CircuitBreaker in.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ClientMiddleware guards a Doer with a CircuitBreaker of Group per host.
+// See Middleware for the inbound requests of a server.
+//
+// Key returns the name of the CircuitBreaker for a request.
+// If Key is nil, the host of the URL of the request is used.
+//
+// A response is a failure if IsFailure returns true for it.
+// If IsFailure is nil, the responses with the status 5xx are failures.
+// The errors of the Doer are always failures.
+type ClientMiddleware struct {
+	Group     *gobreaker.Group
+	Key       func(req *http.Request) string
+	IsFailure func(resp *http.Response) bool
+}
+
+// Wrap returns a Doer sending the requests with next through the CircuitBreakers of m.Group.
+// The returned Doer returns a *gobreaker.RejectionError if the CircuitBreaker rejects the request.
+// Wrap is usable as a func(next Doer) Doer middleware.
+func (m ClientMiddleware) Wrap(next Doer) Doer {
+	return DoerFunc(func(req *http.Request) (*http.Response, error) {
+		key := req.URL.Host
+		if m.Key != nil {
+			key = m.Key(req)
+		}
+
+		var resp *http.Response
+		_, err := m.Group.Execute(key, func() (interface{}, error) {
+			var err error
+			resp, err = next.Do(req)
+			if err == nil && isFailure(m.IsFailure, resp) {
+				return nil, errFailureResponse
+			}
+			return nil, err
+		})
+		if errors.Is(err, errFailureResponse) {
+			return resp, nil
+		}
+		return resp, err
+	})
+}
@@ -0,0 +1,92 @@
+package gobreakerhttp
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	g := gobreaker.NewGroup(gobreaker.Settings{
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	})
+	errDown := errors.New("down")
+	next := DoerFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Host {
+		case "down.example.com":
+			return nil, errDown
+		case "busy.example.com":
+			return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+		default:
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}
+	})
+	doer := ClientMiddleware{Group: g}.Wrap(next)
+
+	do := func(url string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.Nil(t, err)
+		return doer.Do(req)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := do("http://down.example.com/")
+		assert.Equal(t, errDown, err)
+		resp, err := do("http://busy.example.com/")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	_, err := do("http://down.example.com/")
+	assert.True(t, errors.Is(err, gobreaker.ErrOpenState))
+	_, err = do("http://busy.example.com/")
+	assert.True(t, errors.Is(err, gobreaker.ErrOpenState))
+
+	resp, err := do("http://ok.example.com/")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"busy.example.com", "down.example.com", "ok.example.com"}, g.Names())
+}
+
+func TestClientMiddlewareWrapErrors(t *testing.T) {
+	g := gobreaker.NewGroup(gobreaker.Settings{WrapErrors: true})
+	next := DoerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+	})
+	doer := ClientMiddleware{Group: g}.Wrap(next)
+
+	req, err := http.NewRequest(http.MethodGet, "http://busy.example.com/", nil)
+	assert.Nil(t, err)
+	resp, err := doer.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, uint32(1), g.Get("busy.example.com").Counts().TotalFailures)
+}
+
+func TestClientMiddlewareKey(t *testing.T) {
+	g := gobreaker.NewGroup(gobreaker.Settings{})
+	m := ClientMiddleware{
+		Group:     g,
+		Key:       func(req *http.Request) string { return "api" },
+		IsFailure: func(resp *http.Response) bool { return resp.StatusCode == http.StatusTooManyRequests },
+	}
+	var wrap func(next Doer) Doer = m.Wrap
+	doer := wrap(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody}, nil
+	})})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://a.example.com/", nil)
+	resp, err := doer.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, []string{"api"}, g.Names())
+	assert.Equal(t, uint32(1), g.Get("api").Counts().TotalFailures)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
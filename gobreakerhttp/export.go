@@ -0,0 +1,182 @@
+package gobreakerhttp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// ExportSchema identifies the version of the schema of Export.
+// Fields may be added to the schema without changing ExportSchema,
+// but fields are never removed or renamed, and their meanings never change.
+const ExportSchema = "gobreaker.export/v1"
+
+// Export is the stable representation of the CircuitBreakers intended for consumers written in any language,
+// such as dashboards and services not written in Go.
+//
+// All the durations are in seconds as JSON numbers, and all the times are RFC 3339 strings.
+// StateCode is 0 for "closed", 1 for "half-open" and 2 for "open".
+// Counts and Latency are of the current generation of the CircuitBreaker, so they are reset with it.
+type Export struct {
+	Schema   string          `json:"schema"`
+	Time     time.Time       `json:"time"`
+	Breakers []BreakerExport `json:"breakers"`
+}
+
+// BreakerExport is a CircuitBreaker in Export.
+type BreakerExport struct {
+	Name            string        `json:"name"`
+	State           string        `json:"state"`
+	StateCode       int           `json:"stateCode"`
+	Disabled        bool          `json:"disabled"`
+	Counts          Counts        `json:"counts"`
+	Latency         LatencyExport `json:"latency"`
+	Expiry          *time.Time    `json:"expiry,omitempty"`
+	IntervalSeconds float64       `json:"intervalSeconds"`
+	DowntimeSeconds float64       `json:"downtimeSeconds"`
+}
+
+// LatencyExport is gobreaker.Stats in Export.
+type LatencyExport struct {
+	Count       uint32  `json:"count"`
+	MinSeconds  float64 `json:"minSeconds"`
+	MaxSeconds  float64 `json:"maxSeconds"`
+	MeanSeconds float64 `json:"meanSeconds"`
+	P50Seconds  float64 `json:"p50Seconds"`
+	P90Seconds  float64 `json:"p90Seconds"`
+	P99Seconds  float64 `json:"p99Seconds"`
+}
+
+// NewExport returns the Export of the given CircuitBreakers at the current time.
+func NewExport(cbs ...*gobreaker.CircuitBreaker) Export {
+	e := Export{
+		Schema:   ExportSchema,
+		Time:     time.Now(),
+		Breakers: make([]BreakerExport, 0, len(cbs)),
+	}
+	for _, cb := range cbs {
+		e.Breakers = append(e.Breakers, newBreakerExport(cb))
+	}
+	return e
+}
+
+func newBreakerExport(cb *gobreaker.CircuitBreaker) BreakerExport {
+	state := cb.State()
+	stats := cb.Stats()
+	b := BreakerExport{
+		Name:      cb.Name(),
+		State:     state.String(),
+		StateCode: int(state),
+		Disabled:  cb.Disabled(),
+		Counts:    newCounts(cb.Counts()),
+		Latency: LatencyExport{
+			Count:       stats.Count,
+			MinSeconds:  stats.Min.Seconds(),
+			MaxSeconds:  stats.Max.Seconds(),
+			MeanSeconds: stats.Mean.Seconds(),
+			P50Seconds:  stats.P50.Seconds(),
+			P90Seconds:  stats.P90.Seconds(),
+			P99Seconds:  stats.P99.Seconds(),
+		},
+		IntervalSeconds: cb.Interval().Seconds(),
+		DowntimeSeconds: cb.Downtime().Seconds(),
+	}
+	if expiry := cb.Expiry(); !expiry.IsZero() {
+		b.Expiry = &expiry
+	}
+	return b
+}
+
+// OpenMetricsContentType is the content type of the text written by WriteOpenMetrics.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// WriteOpenMetrics writes e in the OpenMetrics text format, e.g. for Prometheus to scrape.
+// Every metric is a gauge with the label "name" of the CircuitBreaker,
+// and the metric names are those of the JSON fields of Export prefixed by "gobreaker_" in snake case.
+func WriteOpenMetrics(w io.Writer, e Export) error {
+	bw := bufio.NewWriter(w)
+
+	gauge := func(name, help string, value func(b BreakerExport) float64) {
+		fmt.Fprintf(bw, "# TYPE gobreaker_%s gauge\n", name)
+		fmt.Fprintf(bw, "# HELP gobreaker_%s %s\n", name, help)
+		for _, b := range e.Breakers {
+			fmt.Fprintf(bw, "gobreaker_%s{name=\"%s\"} %g\n", name, escapeLabel(b.Name), value(b))
+		}
+	}
+
+	gauge("state_code", "State of the circuit breaker: 0 closed, 1 half-open, 2 open.",
+		func(b BreakerExport) float64 { return float64(b.StateCode) })
+	gauge("disabled", "Whether the circuit breaker is disabled.",
+		func(b BreakerExport) float64 { return boolValue(b.Disabled) })
+	gauge("counts_requests", "Requests in the current generation.",
+		func(b BreakerExport) float64 { return float64(b.Counts.Requests) })
+	gauge("counts_total_successes", "Successes in the current generation.",
+		func(b BreakerExport) float64 { return float64(b.Counts.TotalSuccesses) })
+	gauge("counts_total_failures", "Failures in the current generation.",
+		func(b BreakerExport) float64 { return float64(b.Counts.TotalFailures) })
+	gauge("counts_consecutive_successes", "Consecutive successes in the current generation.",
+		func(b BreakerExport) float64 { return float64(b.Counts.ConsecutiveSuccesses) })
+	gauge("counts_consecutive_failures", "Consecutive failures in the current generation.",
+		func(b BreakerExport) float64 { return float64(b.Counts.ConsecutiveFailures) })
+	gauge("latency_mean_seconds", "Mean duration of the requests in the current generation.",
+		func(b BreakerExport) float64 { return b.Latency.MeanSeconds })
+	gauge("latency_p99_seconds", "Estimated 99th percentile duration of the requests in the current generation.",
+		func(b BreakerExport) float64 { return b.Latency.P99Seconds })
+	gauge("downtime_seconds", "Cumulative time of the open state in the downtime window.",
+		func(b BreakerExport) float64 { return b.DowntimeSeconds })
+
+	fmt.Fprint(bw, "# EOF\n")
+	return bw.Flush()
+}
+
+// WriteOpenMetricsHistograms writes the histograms of hc in the OpenMetrics text format, e.g. for Prometheus to scrape.
+// The decisions are gobreaker_decision_duration_seconds and the requests are gobreaker_call_duration_seconds,
+// both with the label "name" of the CircuitBreaker.
+func WriteOpenMetricsHistograms(w io.Writer, hc *gobreaker.HistogramCollector) error {
+	bw := bufio.NewWriter(w)
+
+	histograms := func(name, help string, hs map[string]gobreaker.Histogram) {
+		fmt.Fprintf(bw, "# TYPE gobreaker_%s histogram\n", name)
+		fmt.Fprintf(bw, "# HELP gobreaker_%s %s\n", name, help)
+
+		names := make([]string, 0, len(hs))
+		for n := range hs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			h, label := hs[n], escapeLabel(n)
+			for _, b := range h.Buckets {
+				fmt.Fprintf(bw, "gobreaker_%s_bucket{name=\"%s\",le=\"%g\"} %d\n", name, label, b.UpperBound.Seconds(), b.Count)
+			}
+			fmt.Fprintf(bw, "gobreaker_%s_bucket{name=\"%s\",le=\"+Inf\"} %d\n", name, label, h.Count)
+			fmt.Fprintf(bw, "gobreaker_%s_count{name=\"%s\"} %d\n", name, label, h.Count)
+			fmt.Fprintf(bw, "gobreaker_%s_sum{name=\"%s\"} %g\n", name, label, h.Sum.Seconds())
+		}
+	}
+
+	histograms("decision_duration_seconds", "Time the circuit breaker took to allow or reject a request.", hc.Decisions())
+	histograms("call_duration_seconds", "Duration of the requests run by the circuit breaker.", hc.Calls())
+
+	fmt.Fprint(bw, "# EOF\n")
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package gobreakerhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandlerExport(t *testing.T) {
+	g := gobreaker.NewGroup(gobreaker.Settings{})
+	g.Get("a").Execute(func() (interface{}, error) { return nil, nil })
+	g.Get(`b"\`).Execute(func() (interface{}, error) { return nil, errors.New("fail") })
+	g.Get(`b"\`).Trip()
+	h := NewAdminHandler(g)
+
+	w := serve(h, http.MethodGet, "/debug/breakers?format=export", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var raw map[string]interface{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &raw))
+	assert.Equal(t, ExportSchema, raw["schema"])
+
+	var e Export
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, 2, len(e.Breakers))
+	assert.Equal(t, "a", e.Breakers[0].Name)
+	assert.Equal(t, "closed", e.Breakers[0].State)
+	assert.Equal(t, 0, e.Breakers[0].StateCode)
+	assert.Equal(t, Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}, e.Breakers[0].Counts)
+	assert.Equal(t, uint32(1), e.Breakers[0].Latency.Count)
+	assert.Nil(t, e.Breakers[0].Expiry)
+	assert.Equal(t, "open", e.Breakers[1].State)
+	assert.Equal(t, 2, e.Breakers[1].StateCode)
+	assert.NotNil(t, e.Breakers[1].Expiry)
+
+	w = serve(h, http.MethodGet, "/debug/breakers?format=openmetrics", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, OpenMetricsContentType, w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.True(t, strings.Contains(body, "# TYPE gobreaker_state_code gauge\n"))
+	assert.True(t, strings.Contains(body, "gobreaker_state_code{name=\"a\"} 0\n"))
+	assert.True(t, strings.Contains(body, `gobreaker_state_code{name="b\"\\"} 2`+"\n"))
+	assert.True(t, strings.Contains(body, "gobreaker_counts_requests{name=\"a\"} 1\n"))
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	w = serve(h, http.MethodGet, "/debug/breakers?format=xml", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWriteOpenMetricsHistograms(t *testing.T) {
+	hc := gobreaker.NewHistogramCollector(time.Millisecond, time.Second)
+	hc.ObserveDecision("db", time.Microsecond, true)
+	hc.ObserveCall("db", time.Duration(500)*time.Millisecond, gobreaker.StateClosed)
+
+	var b strings.Builder
+	assert.Nil(t, WriteOpenMetricsHistograms(&b, hc))
+	assert.Equal(t, `# TYPE gobreaker_decision_duration_seconds histogram
+# HELP gobreaker_decision_duration_seconds Time the circuit breaker took to allow or reject a request.
+gobreaker_decision_duration_seconds_bucket{name="db",le="0.001"} 1
+gobreaker_decision_duration_seconds_bucket{name="db",le="1"} 1
+gobreaker_decision_duration_seconds_bucket{name="db",le="+Inf"} 1
+gobreaker_decision_duration_seconds_count{name="db"} 1
+gobreaker_decision_duration_seconds_sum{name="db"} 1e-06
+# TYPE gobreaker_call_duration_seconds histogram
+# HELP gobreaker_call_duration_seconds Duration of the requests run by the circuit breaker.
+gobreaker_call_duration_seconds_bucket{name="db",le="0.001"} 0
+gobreaker_call_duration_seconds_bucket{name="db",le="1"} 1
+gobreaker_call_duration_seconds_bucket{name="db",le="+Inf"} 1
+gobreaker_call_duration_seconds_count{name="db"} 1
+gobreaker_call_duration_seconds_sum{name="db"} 0.5
+# EOF
+`, b.String())
+}
@@ -0,0 +1,105 @@
+package gobreakerhttp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Middleware returns a middleware protecting an http.Handler with a CircuitBreaker configured with st,
+// so that an overloaded service sheds its load instead of melting down.
+// Middleware protects the inbound requests of a server; ClientMiddleware protects the outbound requests of a client.
+// While the CircuitBreaker rejects the requests, the middleware responds with 503 Service Unavailable
+// and the Retry-After header, rounded up to seconds, without calling the handler.
+//
+// isFailure decides whether a request handled with the status in d is a failure,
+// e.g. to count the slow responses over the latency budget as failures.
+// If isFailure is nil, the responses with the status 5xx are failures.
+// A panic in the handler is counted as a failure.
+// The latency is measured by st.Clock, or the system time if st.Clock is nil.
+func Middleware(st gobreaker.Settings, isFailure func(r *http.Request, status int, d time.Duration) bool) func(next http.Handler) http.Handler {
+	tscb := gobreaker.NewTwoStepCircuitBreaker(st)
+	now := time.Now
+	if st.Clock != nil {
+		now = st.Clock.Now
+	}
+	if isFailure == nil {
+		isFailure = func(r *http.Request, status int, d time.Duration) bool {
+			return status >= http.StatusInternalServerError
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done, err := tscb.Allow()
+			if err != nil {
+				writeUnavailable(w, err)
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := now()
+			success := false
+			defer func() {
+				done(success)
+			}()
+
+			next.ServeHTTP(rec, r)
+			success = !isFailure(r, rec.status, now().Sub(start))
+		})
+	}
+}
+
+func writeUnavailable(w http.ResponseWriter, err error) {
+	retryAfter := time.Duration(1) * time.Second
+	var re *gobreaker.RejectionError
+	if errors.As(err, &re) && re.RetryAfter > retryAfter {
+		retryAfter = re.RetryAfter
+	}
+	seconds := (retryAfter + time.Second - 1) / time.Second
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
+
+// statusRecorder records the status written by a handler.
+// It implements http.Flusher and http.Hijacker by the wrapped http.ResponseWriter, and Unwrap for http.ResponseController,
+// so that the handlers streaming the responses or taking over the connections keep working behind Middleware.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+var errNotHijacker = errors.New("gobreakerhttp: http.ResponseWriter does not implement http.Hijacker")
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errNotHijacker
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,88 @@
+package gobreakerhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/sony/gobreaker/breakertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	status := http.StatusInternalServerError
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	protect := Middleware(gobreaker.Settings{
+		Timeout:     time.Duration(90) * time.Second,
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	}, nil)
+	h := protect(handler)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, serve().Code)
+	assert.Equal(t, http.StatusInternalServerError, serve().Code)
+
+	status = http.StatusOK
+	w := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+}
+
+func TestMiddlewareLatency(t *testing.T) {
+	slow := func(r *http.Request, status int, d time.Duration) bool {
+		return r.URL.Path == "/slow"
+	}
+	h := Middleware(gobreaker.Settings{}, slow)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, http.StatusOK, serve("/slow"))
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/"))
+}
+
+func TestMiddlewareClock(t *testing.T) {
+	clock := breakertest.NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	var latency time.Duration
+	h := Middleware(gobreaker.Settings{Clock: clock}, func(r *http.Request, status int, d time.Duration) bool {
+		latency = d
+		return false
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.Advance(time.Duration(3) * time.Second)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, time.Duration(3)*time.Second, latency)
+}
+
+func TestMiddlewareResponseWriter(t *testing.T) {
+	var unwrapped http.ResponseWriter
+	var hijackErr error
+	h := Middleware(gobreaker.Settings{}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		_, _, hijackErr = w.(http.Hijacker).Hijack()
+		unwrapped = w.(interface{ Unwrap() http.ResponseWriter }).Unwrap()
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, w, unwrapped)
+	assert.Equal(t, errNotHijacker, hijackErr) // httptest.ResponseRecorder is not an http.Hijacker
+}
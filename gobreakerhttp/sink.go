@@ -0,0 +1,77 @@
+package gobreakerhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Push is the JSON body posted by JSONSink.
+type Push struct {
+	Time     time.Time         `json:"time"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Breakers []BreakerStatus   `json:"breakers"`
+}
+
+// JSONSink is a gobreaker.Sink posting the snapshots as Push in JSON to URL, e.g. for a gobreaker.Reporter.
+// If Client is nil, http.DefaultClient is used. A response with a status other than 2xx is an error.
+type JSONSink struct {
+	URL    string
+	Client Doer
+}
+
+// Push implements gobreaker.Sink.
+func (s JSONSink) Push(ctx context.Context, snapshots []gobreaker.BreakerSnapshot, labels map[string]string) error {
+	p := Push{
+		Time:     time.Now(),
+		Labels:   labels,
+		Breakers: make([]BreakerStatus, 0, len(snapshots)),
+	}
+	for _, snap := range snapshots {
+		b := BreakerStatus{
+			Name:     snap.Name,
+			State:    snap.State.String(),
+			Counts:   newCounts(snap.Counts),
+			Disabled: snap.Disabled,
+		}
+		if !snap.Expiry.IsZero() {
+			expiry := snap.Expiry
+			b.Expiry = &expiry
+		}
+		p.Breakers = append(p.Breakers, b)
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("gobreakerhttp: push to %s: %s", s.URL, resp.Status)
+	}
+	return nil
+}
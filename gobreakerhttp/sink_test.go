@@ -0,0 +1,37 @@
+package gobreakerhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSink(t *testing.T) {
+	var pushed Push
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&pushed))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{Name: "db"})
+	cb.Trip()
+	r := gobreaker.NewReporter(JSONSink{URL: server.URL}, gobreaker.ReporterConfig{Labels: map[string]string{"service": "api"}}, cb)
+	assert.Nil(t, r.Push(context.Background()))
+	assert.Equal(t, map[string]string{"service": "api"}, pushed.Labels)
+	assert.Equal(t, 1, len(pushed.Breakers))
+	assert.Equal(t, "db", pushed.Breakers[0].Name)
+	assert.Equal(t, "open", pushed.Breakers[0].State)
+	assert.NotNil(t, pushed.Breakers[0].Expiry)
+
+	status = http.StatusBadGateway
+	assert.NotNil(t, r.Push(context.Background()))
+}
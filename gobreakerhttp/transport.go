@@ -0,0 +1,66 @@
+package gobreakerhttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sony/gobreaker"
+)
+
+// Transport is an http.RoundTripper guarding every backend address with its own CircuitBreaker of Endpoints,
+// so that a failing backend behind a load-balanced hostname doesn't break the others.
+//
+// Endpoint returns the backend address of a request.
+// If Endpoint is nil, the host of the URL of the request is used, which is the resolved address
+// when a client-side load balancer has rewritten the URL to the address of the chosen backend.
+//
+// A response is a failure if IsFailure returns true for it.
+// If IsFailure is nil, the responses with the status 5xx are failures.
+// The errors of Base are always failures.
+type Transport struct {
+	Base      http.RoundTripper
+	Endpoints *gobreaker.Endpoints
+	Endpoint  func(req *http.Request) string
+	IsFailure func(resp *http.Response) bool
+}
+
+// errFailureResponse reports a response counted as a failure to the CircuitBreaker.
+var errFailureResponse = errors.New("gobreakerhttp: failure response")
+
+// RoundTrip implements http.RoundTripper.
+// RoundTrip returns a *gobreaker.RejectionError if the CircuitBreaker of the backend rejects the request.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	addr := req.URL.Host
+	if t.Endpoint != nil {
+		addr = t.Endpoint(req)
+	}
+
+	var resp *http.Response
+	_, err := t.Endpoints.Execute(addr, func() (interface{}, error) {
+		var err error
+		resp, err = t.base().RoundTrip(req)
+		if err == nil && isFailure(t.IsFailure, resp) {
+			return nil, errFailureResponse
+		}
+		return nil, err
+	})
+	if errors.Is(err, errFailureResponse) {
+		return resp, nil
+	}
+	return resp, err
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// isFailure reports whether resp is a failure by f, or by the status 5xx if f is nil.
+func isFailure(f func(resp *http.Response) bool, resp *http.Response) bool {
+	if f == nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+	return f(resp)
+}
@@ -0,0 +1,80 @@
+package gobreakerhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	endpoints := gobreaker.NewEndpoints(gobreaker.Settings{
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	})
+	client := &http.Client{Transport: &Transport{Endpoints: endpoints}}
+	healthyAddr, _ := url.Parse(healthy.URL)
+	failingAddr, _ := url.Parse(failing.URL)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(failing.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		resp.Body.Close()
+	}
+	_, err := client.Get(failing.URL)
+	assert.True(t, errors.Is(err, gobreaker.ErrOpenState))
+
+	resp, err := client.Get(healthy.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	addrs := []string{healthyAddr.Host, failingAddr.Host}
+	assert.Equal(t, []string{healthyAddr.Host}, endpoints.Available(addrs))
+}
+
+func TestTransportWrapErrors(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	endpoints := gobreaker.NewEndpoints(gobreaker.Settings{WrapErrors: true})
+	client := &http.Client{Transport: &Transport{Endpoints: endpoints}}
+	failingAddr, _ := url.Parse(failing.URL)
+
+	resp, err := client.Get(failing.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+	assert.Equal(t, uint32(1), endpoints.Group().Get(failingAddr.Host).Counts().TotalFailures)
+}
+
+func TestTransportEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	endpoints := gobreaker.NewEndpoints(gobreaker.Settings{})
+	client := &http.Client{Transport: &Transport{
+		Endpoints: endpoints,
+		Endpoint:  func(req *http.Request) string { return req.Header.Get("X-Backend") },
+	}}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-Backend", "backend-1")
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"backend-1"}, endpoints.Group().Names())
+}
@@ -0,0 +1,47 @@
+package gobreakerlog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func trip(logger gobreaker.Logger) {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "log",
+		Logger:      logger,
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	})
+	cb.Execute(func() (interface{}, error) { return nil, errors.New("fail") })
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	trip(Slog(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	line := buf.String()
+	assert.True(t, strings.Contains(line, "level=WARN"))
+	assert.True(t, strings.Contains(line, `circuit breaker state changed name=\"log\" from=closed to=open`))
+
+	buf.Reset()
+	Slog(slog.New(slog.NewTextHandler(&buf, nil))).Debugf("hidden")
+	assert.Equal(t, "", buf.String())
+}
+
+func TestZap(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	trip(Zap(zap.New(core)))
+
+	entries := logs.All()
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.True(t, strings.HasPrefix(entries[0].Message, `circuit breaker state changed name="log" from=closed to=open`))
+}
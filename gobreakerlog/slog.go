@@ -0,0 +1,42 @@
+// Package gobreakerlog adapts the structured loggers log/slog and zap to gobreaker.Logger.
+//
+// gobreakerlog is a separate module, so that gobreaker itself doesn't depend on zap or a recent Go version.
+package gobreakerlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/sony/gobreaker"
+)
+
+// slogLogger is a gobreaker.Logger writing to a *slog.Logger.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// Slog returns a gobreaker.Logger writing to l at the levels slog.LevelDebug, slog.LevelInfo and slog.LevelWarn.
+func Slog(l *slog.Logger) gobreaker.Logger {
+	return slogLogger{l: l}
+}
+
+func (s slogLogger) Debugf(format string, args ...interface{}) {
+	s.logf(slog.LevelDebug, format, args)
+}
+
+func (s slogLogger) Infof(format string, args ...interface{}) {
+	s.logf(slog.LevelInfo, format, args)
+}
+
+func (s slogLogger) Warnf(format string, args ...interface{}) {
+	s.logf(slog.LevelWarn, format, args)
+}
+
+func (s slogLogger) logf(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+	s.l.Log(ctx, level, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,12 @@
+package gobreakerlog
+
+import (
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// Zap returns a gobreaker.Logger writing to l.
+// The returned Logger is l.Sugar(), which implements gobreaker.Logger by itself.
+func Zap(l *zap.Logger) gobreaker.Logger {
+	return l.Sugar()
+}
@@ -0,0 +1,128 @@
+// Package gobreakerotel records the decisions of gobreaker on OpenTelemetry trace spans.
+//
+// Execute and Allow add a span event named EventDecision to the span in the context of every request,
+// with the attributes describing whether the CircuitBreaker allowed or rejected the request,
+// and a span event named EventStateChange when the state of the CircuitBreaker changed during the request.
+//
+// gobreakerotel is a separate module, so that gobreaker itself doesn't depend on OpenTelemetry.
+package gobreakerotel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// These constants are the names of the span events.
+const (
+	EventDecision    = "gobreaker.decision"
+	EventStateChange = "gobreaker.state_change"
+)
+
+// These constants are the keys of the attributes of the span events.
+const (
+	AttrName     = attribute.Key("gobreaker.name")
+	AttrState    = attribute.Key("gobreaker.state")
+	AttrDecision = attribute.Key("gobreaker.decision")
+	AttrFrom     = attribute.Key("gobreaker.from")
+	AttrTo       = attribute.Key("gobreaker.to")
+)
+
+// These constants are the values of AttrDecision.
+const (
+	DecisionAllowed          = "allowed"
+	DecisionRejectedOpen     = "rejected-open"
+	DecisionRejectedTooMany  = "rejected-too-many"
+	DecisionRejectedThrottle = "rejected-throttled"
+	DecisionRejectedBudget   = "rejected-budget"
+)
+
+// Decision returns the value of AttrDecision for the error returned by a CircuitBreaker,
+// or DecisionAllowed if err is not a rejection.
+func Decision(err error) string {
+	switch gobreaker.RejectionReason(err) {
+	case gobreaker.ErrOpenState:
+		return DecisionRejectedOpen
+	case gobreaker.ErrTooManyRequests:
+		return DecisionRejectedTooMany
+	case gobreaker.ErrThrottled:
+		return DecisionRejectedThrottle
+	case gobreaker.ErrInsufficientBudget:
+		return DecisionRejectedBudget
+	default:
+		return DecisionAllowed
+	}
+}
+
+// Execute runs req with cb.ExecuteContext and records the decision of cb on the span in ctx.
+func Execute(ctx context.Context, cb *gobreaker.CircuitBreaker, req func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	span := trace.SpanFromContext(ctx)
+	from := cb.State()
+
+	allowed := false
+	result, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		allowed = true
+		addDecision(span, cb.Name(), from, DecisionAllowed)
+		return req(ctx)
+	})
+	if !allowed && ctx.Err() == nil {
+		addDecision(span, cb.Name(), rejectedState(err, from), Decision(err))
+	}
+
+	addStateChange(span, cb.Name(), from, cb.State())
+	return result, err
+}
+
+// Allow calls tscb.Allow and records the decision of tscb on the span in ctx.
+// The returned done also records the state change caused by the outcome, if any.
+func Allow(ctx context.Context, tscb *gobreaker.TwoStepCircuitBreaker) (done func(success bool), err error) {
+	span := trace.SpanFromContext(ctx)
+	from := tscb.State()
+
+	allowed, err := tscb.Allow()
+	if err != nil {
+		addDecision(span, tscb.Name(), rejectedState(err, from), Decision(err))
+		addStateChange(span, tscb.Name(), from, tscb.State())
+		return nil, err
+	}
+
+	addDecision(span, tscb.Name(), from, DecisionAllowed)
+	return func(success bool) {
+		allowed(success)
+		addStateChange(span, tscb.Name(), from, tscb.State())
+	}, nil
+}
+
+// rejectedState returns the state of the CircuitBreaker at the rejection err, or state if unknown.
+func rejectedState(err error, state gobreaker.State) gobreaker.State {
+	var re *gobreaker.RejectionError
+	if errors.As(err, &re) {
+		return re.State
+	}
+	return state
+}
+
+func addDecision(span trace.Span, name string, state gobreaker.State, decision string) {
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(EventDecision, trace.WithAttributes(
+		AttrName.String(name),
+		AttrState.String(state.String()),
+		AttrDecision.String(decision),
+	))
+}
+
+func addStateChange(span trace.Span, name string, from, to gobreaker.State) {
+	if from == to || !span.IsRecording() {
+		return
+	}
+	span.AddEvent(EventStateChange, trace.WithAttributes(
+		AttrName.String(name),
+		AttrFrom.String(from.String()),
+		AttrTo.String(to.String()),
+	))
+}
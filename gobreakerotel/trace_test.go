@@ -0,0 +1,89 @@
+package gobreakerotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func decisions(span sdktrace.ReadOnlySpan) []string {
+	var ds []string
+	for _, ev := range span.Events() {
+		for _, kv := range ev.Attributes {
+			switch kv.Key {
+			case AttrDecision:
+				ds = append(ds, kv.Value.AsString())
+			case AttrTo:
+				ds = append(ds, "to "+kv.Value.AsString())
+			}
+		}
+	}
+	return ds
+}
+
+func attr(ev sdktrace.Event, key attribute.Key) string {
+	for _, kv := range ev.Attributes {
+		if kv.Key == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestExecute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        "db",
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	})
+	failure := func(ctx context.Context) (interface{}, error) { return nil, errors.New("fail") }
+
+	ctx, span := tracer.Start(context.Background(), "call")
+	_, err := Execute(ctx, cb, failure)
+	assert.Equal(t, "fail", err.Error())
+	_, err = Execute(ctx, cb, failure)
+	assert.Equal(t, gobreaker.ErrOpenState, gobreaker.RejectionReason(err))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, []string{DecisionAllowed, "to open", DecisionRejectedOpen}, decisions(spans[0]))
+	ev := spans[0].Events()[0]
+	assert.Equal(t, EventDecision, ev.Name)
+	assert.Equal(t, "db", attr(ev, AttrName))
+	assert.Equal(t, "closed", attr(ev, AttrState))
+	assert.Equal(t, EventStateChange, spans[0].Events()[1].Name)
+	assert.Equal(t, "open", attr(spans[0].Events()[2], AttrState))
+}
+
+func TestAllow(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	tscb := gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	})
+
+	ctx, span := tracer.Start(context.Background(), "call")
+	done, err := Allow(ctx, tscb)
+	assert.Nil(t, err)
+	done(false)
+	_, err = Allow(ctx, tscb)
+	assert.NotNil(t, err)
+	span.End()
+
+	assert.Equal(t, []string{DecisionAllowed, "to open", DecisionRejectedOpen}, decisions(recorder.Ended()[0]))
+}
+
+func TestDecision(t *testing.T) {
+	assert.Equal(t, DecisionAllowed, Decision(nil))
+	assert.Equal(t, DecisionRejectedTooMany, Decision(gobreaker.ErrTooManyRequests))
+	assert.Equal(t, DecisionRejectedThrottle, Decision(gobreaker.ErrThrottled))
+	assert.Equal(t, DecisionRejectedBudget, Decision(gobreaker.ErrInsufficientBudget))
+}
@@ -0,0 +1,68 @@
+// Package gobreakerqueue provides a message handler wrapper of queue consumers guarded by gobreaker,
+// e.g. for Kafka or SQS.
+//
+// Failing every message while the CircuitBreaker is open makes the broker redeliver them
+// and causes a redelivery storm when the dependency recovers.
+// The wrapper instead pauses the consumption while the CircuitBreaker is open,
+// and handles the same message again after the pause.
+//
+// For example:
+//
+//	handle := gobreakerqueue.Wrap(process, cb, func(d time.Duration) {
+//		consumer.Pause(partitions)
+//		time.Sleep(d)
+//		consumer.Resume(partitions)
+//	})
+//	for msg := range messages {
+//		if err := handle(msg); err != nil {
+//			nack(msg)
+//		}
+//	}
+package gobreakerqueue
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Handler handles a message consumed from a queue.
+type Handler func(msg interface{}) error
+
+// MinPause is the shortest pause, used when the CircuitBreaker rejects a message without RetryAfter,
+// e.g. while the probes of the half-open state are in flight.
+var MinPause = time.Duration(100) * time.Millisecond
+
+// Wrap returns a Handler that runs handler with cb.Execute.
+// When cb rejects a message, the Handler calls pause with the time until cb may allow the message,
+// and then handles the same message again, so that the message is never failed by a rejection.
+// pause must block for the given duration, pausing the consumption in the meantime.
+// If pause is nil, the Handler just sleeps.
+// Otherwise, the Handler returns the error returned from handler.
+func Wrap(handler Handler, cb gobreaker.Breaker, pause func(d time.Duration)) Handler {
+	if pause == nil {
+		pause = time.Sleep
+	}
+
+	return func(msg interface{}) error {
+		for {
+			_, err := cb.Execute(func() (interface{}, error) { return nil, handler(msg) })
+
+			if !gobreaker.IsRejection(err) {
+				return err
+			}
+			pause(pauseFor(err))
+		}
+	}
+}
+
+// pauseFor returns the pause after the rejection err, by RetryAfter if err is a *gobreaker.RejectionError.
+// A custom error of Settings.RejectedError has no RetryAfter, so MinPause is used.
+func pauseFor(err error) time.Duration {
+	var re *gobreaker.RejectionError
+	if !errors.As(err, &re) || re.RetryAfter < MinPause {
+		return MinPause
+	}
+	return re.RetryAfter
+}
@@ -0,0 +1,76 @@
+package gobreakerqueue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{Timeout: time.Second})
+	errFail := errors.New("fail")
+
+	var handled []interface{}
+	handler := func(msg interface{}) error {
+		handled = append(handled, msg)
+		if msg == "bad" {
+			return errFail
+		}
+		return nil
+	}
+
+	var pauses []time.Duration
+	handle := Wrap(handler, cb, func(d time.Duration) {
+		pauses = append(pauses, d)
+		cb.Reset()
+	})
+
+	assert.Equal(t, errFail, handle("bad"))
+	assert.Nil(t, handle("good"))
+	assert.Nil(t, pauses)
+
+	cb.Trip()
+	assert.Nil(t, handle("held"))
+	assert.Equal(t, 1, len(pauses))
+	assert.True(t, pauses[0] > time.Duration(900)*time.Millisecond && pauses[0] <= time.Second)
+	assert.Equal(t, []interface{}{"bad", "good", "held"}, handled)
+}
+
+func TestWrapSleep(t *testing.T) {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{Timeout: time.Duration(50) * time.Millisecond})
+	handle := Wrap(func(msg interface{}) error { return nil }, cb, nil)
+
+	cb.Trip()
+	start := time.Now()
+	assert.Nil(t, handle("msg"))
+	assert.True(t, time.Since(start) >= time.Duration(50)*time.Millisecond)
+	assert.Equal(t, gobreaker.StateClosed, cb.State())
+}
+
+func TestWrapRejectedError(t *testing.T) {
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		RejectedError: func(name string, state gobreaker.State) error {
+			return fmt.Errorf("paused: %w", gobreaker.ErrOpenState)
+		},
+	})
+
+	var pauses []time.Duration
+	handle := Wrap(func(msg interface{}) error { return nil }, cb, func(d time.Duration) {
+		pauses = append(pauses, d)
+		cb.Reset()
+	})
+
+	cb.Trip()
+	assert.Nil(t, handle("held"))
+	assert.Equal(t, []time.Duration{MinPause}, pauses)
+}
+
+func TestPauseFor(t *testing.T) {
+	assert.Equal(t, MinPause, pauseFor(&gobreaker.RejectionError{}))
+	assert.Equal(t, MinPause, pauseFor(gobreaker.ErrOpenState))
+	assert.Equal(t, time.Second, pauseFor(&gobreaker.RejectionError{RetryAfter: time.Second}))
+}
@@ -0,0 +1,58 @@
+// Package gobreakerredis provides a gobreaker.ProbeElector holding the leases of the probes on Redis,
+// so that only one of the replicas of a service probes a recovering dependency.
+//
+// gobreakerredis is a separate module, so that gobreaker itself doesn't depend on go-redis.
+package gobreakerredis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sony/gobreaker"
+)
+
+// DefaultPrefix is the prefix of the keys of the leases by default.
+const DefaultPrefix = "gobreaker:probe:"
+
+// elect takes the lease KEYS[1] for the owner ARGV[1] for ARGV[2] milliseconds,
+// unless another owner holds it. The holder of the lease extends it.
+var elect = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// Elector is a gobreaker.ProbeElector holding the lease of each CircuitBreaker as the key of Redis
+// named Prefix and the name of the CircuitBreaker, whose value is Owner and which expires after the ttl of the lease.
+//
+// Owner identifies the replica, e.g. the hostname, and must be unique among the replicas.
+// If Prefix is empty, DefaultPrefix is used.
+type Elector struct {
+	Client redis.Scripter
+	Owner  string
+	Prefix string
+}
+
+var _ gobreaker.ProbeElector = Elector{}
+
+// Elect implements gobreaker.ProbeElector.
+func (e Elector) Elect(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		ms = 1
+	}
+	elected, err := elect.Run(ctx, e.Client, []string{prefix + name}, e.Owner, ms).Int()
+	if err != nil {
+		return false, err
+	}
+	return elected == 1, nil
+}
@@ -0,0 +1,68 @@
+package gobreakerredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElector(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	a := Elector{Client: client, Owner: "a"}
+	b := Elector{Client: client, Owner: "b"}
+
+	elected, err := a.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+	s.CheckGet(t, DefaultPrefix+"db", "a")
+
+	elected, err = b.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, elected)
+
+	elected, err = a.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+
+	elected, err = b.Elect(ctx, "cache", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+
+	s.FastForward(time.Minute)
+	elected, err = b.Elect(ctx, "db", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, elected)
+}
+
+func TestElectorCircuitBreaker(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	defer client.Close()
+
+	replica := func(owner string) *gobreaker.CircuitBreaker {
+		return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:         "db",
+			Timeout:      time.Millisecond,
+			ProbeElector: Elector{Client: client, Owner: owner, Prefix: "test:"},
+		})
+	}
+	a, b := replica("a"), replica("b")
+	a.Trip()
+	b.Trip()
+	time.Sleep(time.Duration(5) * time.Millisecond)
+
+	probe := func() (interface{}, error) { return nil, nil }
+	_, err := a.Execute(probe)
+	assert.Nil(t, err)
+	_, err = b.Execute(probe)
+	assert.Equal(t, gobreaker.ErrOpenState, gobreaker.RejectionReason(err))
+}
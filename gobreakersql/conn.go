@@ -0,0 +1,195 @@
+package gobreakersql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/sony/gobreaker"
+)
+
+var errNonDefaultTx = errors.New("gobreakersql: driver does not support non-default isolation level or read-only transactions")
+
+// conn is a driver.Conn guarded by a TwoStepCircuitBreaker.
+// The optional interfaces of the wrapped driver.Conn are delegated to it when it implements them.
+type conn struct {
+	driver.Conn
+	tscb *gobreaker.TwoStepCircuitBreaker
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	err := guard(ctx, c.tscb, func() error {
+		var err error
+		if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			s, err = pc.PrepareContext(ctx, query)
+		} else {
+			s, err = c.Conn.Prepare(query)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, tscb: c.tscb}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	err := guard(ctx, c.tscb, func() error {
+		var err error
+		if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+			tx, err = bc.BeginTx(ctx, opts)
+		} else if opts.Isolation != driver.IsolationLevel(0) || opts.ReadOnly {
+			err = errNonDefaultTx
+		} else {
+			tx, err = c.Conn.Begin()
+		}
+		return err
+	})
+	return tx, err
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	var res driver.Result
+	err := guard(ctx, c.tscb, func() error {
+		var err error
+		res, err = ec.ExecContext(ctx, query, args)
+		return err
+	})
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	var rows driver.Rows
+	err := guard(ctx, c.tscb, func() error {
+		var err error
+		rows, err = qc.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	p, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	return guard(ctx, c.tscb, func() error { return p.Ping(ctx) })
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt is a driver.Stmt guarded by a TwoStepCircuitBreaker.
+type stmt struct {
+	driver.Stmt
+	tscb *gobreaker.TwoStepCircuitBreaker
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	var res driver.Result
+	err := guard(context.Background(), s.tscb, func() error {
+		var err error
+		res, err = s.Stmt.Exec(args)
+		return err
+	})
+	return res, err
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	var rows driver.Rows
+	err := guard(context.Background(), s.tscb, func() error {
+		var err error
+		rows, err = s.Stmt.Query(args)
+		return err
+	})
+	return rows, err
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Exec(values)
+	}
+
+	var res driver.Result
+	err := guard(ctx, s.tscb, func() error {
+		var err error
+		res, err = ec.ExecContext(ctx, args)
+		return err
+	})
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Query(values)
+	}
+
+	var rows driver.Rows
+	err := guard(ctx, s.tscb, func() error {
+		var err error
+		rows, err = qc.QueryContext(ctx, args)
+		return err
+	})
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+var errNamedValue = errors.New("gobreakersql: driver does not support the use of Named Parameters")
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedValue
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,144 @@
+// Package gobreakersql provides a database/sql driver wrapper guarded by gobreaker.
+//
+// The wrapper puts a TwoStepCircuitBreaker around opening connections, beginning transactions,
+// preparing statements and executing queries, so that a database outage trips the CircuitBreaker
+// and the requests fail fast with a *gobreaker.RejectionError instead of exhausting the connection pool.
+// The outcomes are classified by IsSuccessful rather than Settings.IsSuccessful.
+//
+// For example:
+//
+//	tscb := gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{Name: "db"})
+//	connector, err := gobreakersql.WrapDriver(&pq.Driver{}, tscb).OpenConnector(dsn)
+//	if err != nil {
+//		return err
+//	}
+//	db := sql.OpenDB(connector)
+package gobreakersql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/sony/gobreaker"
+)
+
+// IsSuccessful reports whether err returned from a driver says the database is healthy.
+// driver.ErrBadConn, context.DeadlineExceeded and the timeouts of net.Error are failures.
+// The other errors, e.g. a syntax error or a constraint violation, are successes
+// because they are the errors of the application.
+func IsSuccessful(err error) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return false
+	}
+	return true
+}
+
+// guard runs f if tscb allows it and reports the outcome of f classified by IsSuccessful.
+// driver.ErrSkip, which asks database/sql to fall back to another method, releases the request without counting it,
+// and so does a deadline error after ctx has expired, because the caller gave up rather than the database.
+func guard(ctx context.Context, tscb *gobreaker.TwoStepCircuitBreaker, f func() error) error {
+	t, err := tscb.Acquire()
+	if err != nil {
+		return err
+	}
+
+	err = f()
+	if errors.Is(err, driver.ErrSkip) || errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+		t.Cancel()
+		return err
+	}
+	t.Done(IsSuccessful(err))
+	return err
+}
+
+// Driver is a driver.Driver guarded by a TwoStepCircuitBreaker.
+type Driver struct {
+	driver driver.Driver
+	tscb   *gobreaker.TwoStepCircuitBreaker
+}
+
+// WrapDriver returns a new Driver that wraps d with tscb.
+// Register it with sql.Register, or use OpenConnector with sql.OpenDB.
+func WrapDriver(d driver.Driver, tscb *gobreaker.TwoStepCircuitBreaker) *Driver {
+	return &Driver{driver: d, tscb: tscb}
+}
+
+// Open implements driver.Driver.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	var c driver.Conn
+	err := guard(context.Background(), d.tscb, func() error {
+		var err error
+		c, err = d.driver.Open(name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, tscb: d.tscb}, nil
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return WrapConnector(c, d.tscb), nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+// dsnConnector is the driver.Connector of a driver without driver.DriverContext.
+type dsnConnector struct {
+	name   string
+	driver *Driver
+}
+
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Connector is a driver.Connector guarded by a TwoStepCircuitBreaker.
+type Connector struct {
+	connector driver.Connector
+	tscb      *gobreaker.TwoStepCircuitBreaker
+}
+
+// WrapConnector returns a new Connector that wraps c with tscb, to be used with sql.OpenDB.
+func WrapConnector(c driver.Connector, tscb *gobreaker.TwoStepCircuitBreaker) *Connector {
+	return &Connector{connector: c, tscb: tscb}
+}
+
+// Connect implements driver.Connector.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var dc driver.Conn
+	err := guard(ctx, c.tscb, func() error {
+		var err error
+		dc, err = c.connector.Connect(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, tscb: c.tscb}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *Connector) Driver() driver.Driver {
+	return WrapDriver(c.connector.Driver(), c.tscb)
+}
@@ -0,0 +1,127 @@
+package gobreakersql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+)
+
+var errSyntax = errors.New("syntax error")
+
+// fakeDriver is a driver.Driver whose connections return err from every query.
+// If err is driver.ErrBadConn, the connections can't be opened either.
+type fakeDriver struct {
+	err   error
+	opens int
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.opens++
+	if d.err == driver.ErrBadConn {
+		return nil, d.err
+	}
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.driver.err != nil {
+		return nil, c.driver.err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.driver.err != nil {
+		return nil, c.driver.err
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (r *fakeRows) Columns() []string              { return []string{"n"} }
+func (r *fakeRows) Close() error                   { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestIsSuccessful(t *testing.T) {
+	assert.True(t, IsSuccessful(nil))
+	assert.True(t, IsSuccessful(errSyntax))
+	assert.False(t, IsSuccessful(driver.ErrBadConn))
+	assert.False(t, IsSuccessful(context.DeadlineExceeded))
+}
+
+func TestGuard(t *testing.T) {
+	tscb := gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{})
+
+	err := guard(context.Background(), tscb, func() error { return driver.ErrSkip })
+	assert.Equal(t, driver.ErrSkip, err)
+	assert.Equal(t, gobreaker.Counts{}, tscb.Counts())
+
+	// the caller's deadline has passed, so the database is not to blame
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	err = guard(ctx, tscb, func() error { return ctx.Err() })
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, gobreaker.Counts{}, tscb.Counts())
+
+	err = guard(context.Background(), tscb, func() error { return context.DeadlineExceeded })
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, uint32(1), tscb.Counts().TotalFailures)
+}
+
+func TestDriver(t *testing.T) {
+	fd := &fakeDriver{}
+	tscb := gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+		ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 3 },
+	})
+	connector, err := WrapDriver(fd, tscb).OpenConnector("fake")
+	assert.Nil(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	_, err = db.Exec("INSERT")
+	assert.Nil(t, err)
+	rows, err := db.Query("SELECT")
+	assert.Nil(t, err)
+	rows.Close()
+	assert.Equal(t, gobreaker.Counts{Requests: 3, TotalSuccesses: 3, ConsecutiveSuccesses: 3}, tscb.Counts())
+
+	fd.err = errSyntax
+	_, err = db.Exec("INSERT")
+	assert.Equal(t, errSyntax, err)
+	assert.Equal(t, uint32(0), tscb.Counts().TotalFailures)
+
+	// database/sql retries driver.ErrBadConn on new connections
+	fd.err = driver.ErrBadConn
+	_, err = db.Exec("INSERT")
+	assert.NotNil(t, err)
+	assert.Equal(t, gobreaker.StateOpen, tscb.State())
+
+	opens := fd.opens
+	_, err = db.Exec("INSERT")
+	assert.Equal(t, gobreaker.ErrOpenState, gobreaker.RejectionReason(err))
+	assert.Equal(t, opens, fd.opens)
+}
@@ -0,0 +1,145 @@
+package gobreaker
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+)
+
+// Group is a registry of CircuitBreakers identified by their names.
+// Group creates a CircuitBreaker on the first use of a name,
+// configured with the Settings of the Group and the name.
+type Group struct {
+	base     Settings
+	eviction GroupEviction
+	clock    Clock
+
+	applying sync.Mutex // serializes ApplyConfig
+
+	mutex     sync.Mutex
+	settings  Settings
+	overrides map[string]Settings
+	breakers  map[string]*CircuitBreaker
+	lru       *list.List
+	uses      map[string]*list.Element
+
+	dependencies map[string]map[string]DependencyPolicy
+	watches      map[string]func()
+}
+
+// NewGroup returns a new Group that creates CircuitBreakers with the given Settings.
+// Settings.Name is replaced with the name of each CircuitBreaker.
+func NewGroup(st Settings) *Group {
+	return &Group{
+		base:     st,
+		settings: st,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// Get returns the CircuitBreaker for the given name, creating it if it doesn't exist.
+func (g *Group) Get(name string) *CircuitBreaker {
+	g.mutex.Lock()
+	cb := g.get(name)
+	evicted := g.evict()
+	g.mutex.Unlock()
+
+	g.notifyEvicted(evicted)
+	return cb
+}
+
+func (g *Group) get(name string) *CircuitBreaker {
+	cb, ok := g.breakers[name]
+	if !ok {
+		cb = NewCircuitBreaker(g.settingsFor(name))
+		g.breakers[name] = cb
+		g.follow(name, cb)
+	}
+	g.touch(name)
+	return cb
+}
+
+// settingsFor returns the Settings of the CircuitBreaker for the given name.
+func (g *Group) settingsFor(name string) Settings {
+	st, ok := g.overrides[name]
+	if !ok {
+		st = g.settings
+	}
+	st.Name = name
+	return st
+}
+
+// Preload creates the CircuitBreakers for the given names in advance, e.g. the keys known at startup
+// from the configuration or service discovery, so that the first requests don't pay for the construction
+// and the CircuitBreakers are visible to Names and the exporters from the start.
+// The CircuitBreakers that already exist are kept.
+func (g *Group) Preload(names ...string) {
+	g.mutex.Lock()
+	for _, name := range names {
+		g.get(name)
+	}
+	evicted := g.evict()
+	g.mutex.Unlock()
+
+	g.notifyEvicted(evicted)
+}
+
+// Execute runs the given request with the CircuitBreaker for the given key, creating it if it doesn't exist,
+// e.g. to break per tenant or per host. See CircuitBreaker.Execute.
+func (g *Group) Execute(key string, req func() (interface{}, error)) (interface{}, error) {
+	return g.Get(key).Execute(req)
+}
+
+// Lookup returns the CircuitBreaker for the given name if it exists.
+func (g *Group) Lookup(name string) (*CircuitBreaker, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	cb, ok := g.breakers[name]
+	return cb, ok
+}
+
+// Add registers the given CircuitBreaker under its name, replacing an existing one,
+// which is closed by Close unless it is cb.
+func (g *Group) Add(cb *CircuitBreaker) {
+	g.mutex.Lock()
+	prev, ok := g.breakers[cb.Name()]
+	g.breakers[cb.Name()] = cb
+	g.unwatch(cb.Name())
+	g.follow(cb.Name(), cb)
+	g.touch(cb.Name())
+	evicted := g.evict()
+	g.mutex.Unlock()
+
+	if ok && prev != cb {
+		prev.Close()
+	}
+	g.notifyEvicted(evicted)
+}
+
+// Remove unregisters the CircuitBreaker for the given name and closes it by Close,
+// so that its background goroutines don't outlive it.
+func (g *Group) Remove(name string) {
+	g.mutex.Lock()
+	cb, ok := g.breakers[name]
+	delete(g.breakers, name)
+	g.forget(name)
+	g.mutex.Unlock()
+
+	if ok {
+		cb.Close()
+	}
+}
+
+// Names returns the sorted names of the registered CircuitBreakers.
+func (g *Group) Names() []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	names := make([]string, 0, len(g.breakers))
+	for name := range g.breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
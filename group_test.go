@@ -0,0 +1,58 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(Settings{Name: "ignored", MaxRequests: 3})
+
+	a := g.Get("a")
+	assert.Equal(t, "a", a.Name())
+	assert.Equal(t, uint32(3), a.maxRequests)
+	assert.True(t, a == g.Get("a"))
+
+	_, ok := g.Lookup("b")
+	assert.False(t, ok)
+
+	b := NewCircuitBreaker(Settings{Name: "b"})
+	g.Add(b)
+	cb, ok := g.Lookup("b")
+	assert.True(t, ok)
+	assert.True(t, b == cb)
+	assert.Equal(t, []string{"a", "b"}, g.Names())
+
+	g.Remove("a")
+	assert.Equal(t, []string{"b"}, g.Names())
+	assert.True(t, isClosed(a))
+	assert.False(t, isClosed(b))
+
+	// the replaced CircuitBreaker is closed, but not the one added again
+	g.Add(b)
+	assert.False(t, isClosed(b))
+	b2 := NewCircuitBreaker(Settings{Name: "b"})
+	g.Add(b2)
+	assert.True(t, isClosed(b))
+	assert.False(t, isClosed(b2))
+	b = b2
+
+	res, err := g.Execute("e", func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, "ok", res)
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, g.Get("e").Counts())
+	g.Remove("e")
+
+	g.Preload("b", "c", "d")
+	assert.Equal(t, []string{"b", "c", "d"}, g.Names())
+	assert.True(t, b == g.Get("b"))
+	assert.Equal(t, uint32(3), g.Get("c").maxRequests)
+}
+
+// isClosed reports whether Close has been called on cb.
+func isClosed(cb *CircuitBreaker) bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return cb.closed
+}
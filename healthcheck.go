@@ -0,0 +1,65 @@
+package gobreaker
+
+import "context"
+
+// healthChecking reports whether HealthCheck is set and not stopped by Close or the context of the CircuitBreaker.
+func (cb *CircuitBreaker) healthChecking() bool {
+	return cb.healthCheck != nil && cb.ctx.Err() == nil
+}
+
+// startHealthCheck runs runHealthCheck for the current generation in the background.
+// It must be called with the lock held, so that Close waits for it.
+func (cb *CircuitBreaker) startHealthCheck() {
+	generation := cb.generation
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+		cb.runHealthCheck(generation)
+	}()
+}
+
+// runHealthCheck probes the dependency with HealthCheck while the CircuitBreaker stays
+// in the open state of the given generation, and closes it after enough successful checks.
+func (cb *CircuitBreaker) runHealthCheck(generation uint64) {
+	cb.mutex.Lock()
+	interval := cb.healthCheckInterval
+	cb.mutex.Unlock()
+
+	var successes uint32
+	for {
+		if cb.sleepContext(cb.ctx, interval) != nil {
+			return
+		}
+
+		cb.mutex.Lock()
+		healthCheck, threshold := cb.healthCheck, cb.healthCheckSuccesses
+		current := cb.state == StateOpen && cb.generation == generation
+		cb.mutex.Unlock()
+		if !current || healthCheck == nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(cb.ctx, interval)
+		err := healthCheck(ctx)
+		cancel()
+		if err != nil {
+			successes = 0
+			continue
+		}
+
+		successes++
+		if successes >= threshold {
+			cb.mutex.Lock()
+			if cb.state == StateOpen && cb.generation == generation && cb.vetoed(StateClosed) {
+				cb.mutex.Unlock()
+				successes = 0
+				continue
+			}
+			if cb.state == StateOpen && cb.generation == generation {
+				cb.setState(StateClosed, cb.clock.Now(), CauseHealthCheck)
+			}
+			cb.mutex.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var healthy int32
+	var checks int32
+	cb := NewCircuitBreaker(Settings{
+		HealthCheck: func(ctx context.Context) error {
+			atomic.AddInt32(&checks, 1)
+			if atomic.LoadInt32(&healthy) == 0 {
+				return errors.New("unhealthy")
+			}
+			return nil
+		},
+		HealthCheckInterval:  time.Duration(10) * time.Millisecond,
+		HealthCheckSuccesses: 3,
+	})
+	assert.Equal(t, uint32(3), cb.healthCheckSuccesses)
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+
+	// the timeout doesn't make the CircuitBreaker half-open
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.True(t, atomic.LoadInt32(&checks) > 0)
+
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(time.Duration(100) * time.Millisecond)
+	assert.Equal(t, StateClosed, cb.State())
+
+	// the health check stops after closing
+	n := atomic.LoadInt32(&checks)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&checks))
+}
+
+func TestHealthCheckDefaults(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	assert.Nil(t, cb.healthCheck)
+	assert.Equal(t, defaultHealthCheckInterval, cb.healthCheckInterval)
+	assert.Equal(t, uint32(1), cb.healthCheckSuccesses)
+}
@@ -0,0 +1,84 @@
+package gobreaker
+
+import (
+	"context"
+	"time"
+)
+
+// ExecuteHedged runs the hedged requests reqs, which are alternative ways to get the same result,
+// e.g. the same call to different replicas, as a single request of the CircuitBreaker,
+// so that exactly one outcome is counted no matter how many of reqs are issued.
+// reqs[0] is issued at once, and each of the rest is issued after delay while no result has been returned yet,
+// or at once when the previous ones have all failed. The first result with a nil error wins,
+// and the context passed to the losers is canceled. If all of reqs fail, the error of the last one is returned.
+// Like ExecuteAsync, a panic in a request crashes the program. ExecuteHedged panics if reqs is empty.
+func (cb *CircuitBreaker) ExecuteHedged(ctx context.Context, delay time.Duration, reqs ...func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if len(reqs) == 0 {
+		panic("gobreaker: ExecuteHedged without requests")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	adm, err := cb.beforeRequest(callOptions{ctx: ctx})
+	if err != nil {
+		return nil, err
+	}
+	return cb.run(adm, func() (interface{}, error) { return cb.hedge(ctx, delay, reqs) })
+}
+
+// hedge issues reqs as described in ExecuteHedged and returns the winning result.
+func (cb *CircuitBreaker) hedge(ctx context.Context, delay time.Duration, reqs []func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan Result, len(reqs))
+	var (
+		issued  int
+		pending int
+		timer   Timer
+		wake    chan struct{}
+	)
+	issue := func() {
+		req := reqs[issued]
+		go func() {
+			value, err := req(ctx)
+			results <- Result{Value: value, Err: err}
+		}()
+		issued++
+		pending++
+
+		if timer != nil {
+			timer.Stop()
+		}
+		wake = nil
+		if issued < len(reqs) {
+			w := make(chan struct{})
+			timer = cb.clock.AfterFunc(delay, func() { close(w) })
+			wake = w
+		}
+	}
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	issue()
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.Err == nil {
+				return r.Value, nil
+			}
+			if issued < len(reqs) {
+				issue()
+			} else if pending == 0 {
+				return r.Value, r.Err
+			}
+		case <-wake:
+			issue()
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteHedged(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	errFail := errors.New("fail")
+
+	canceled := make(chan bool, 1)
+	slow := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		canceled <- true
+		return nil, ctx.Err()
+	}
+	fast := func(ctx context.Context) (interface{}, error) { return "hedge", nil }
+
+	value, err := cb.ExecuteHedged(context.Background(), time.Duration(10)*time.Millisecond, slow, fast)
+	assert.Equal(t, "hedge", value)
+	assert.Nil(t, err)
+	assert.True(t, <-canceled)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	// a failure issues the next request at once
+	failing := func(ctx context.Context) (interface{}, error) { return nil, errFail }
+	start := time.Now()
+	value, err = cb.ExecuteHedged(context.Background(), time.Duration(61)*time.Second, failing, fast)
+	assert.Equal(t, "hedge", value)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, Counts{2, 2, 0, 2, 0}, cb.Counts())
+
+	_, err = cb.ExecuteHedged(context.Background(), 0, failing, failing, failing)
+	assert.Equal(t, errFail, err)
+	assert.Equal(t, Counts{3, 2, 1, 0, 1}, cb.Counts())
+}
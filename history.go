@@ -0,0 +1,79 @@
+package gobreaker
+
+import "time"
+
+// GenerationRecord records a past generation of CircuitBreaker
+// with the state during the generation and the Counts at its end.
+type GenerationRecord struct {
+	Generation uint64
+	State      State
+	Start      time.Time
+	End        time.Time
+	Counts     Counts
+}
+
+// history is a ring buffer of GenerationRecord.
+type history struct {
+	records []GenerationRecord
+	next    int
+	full    bool
+}
+
+func (h *history) add(r GenerationRecord) {
+	if len(h.records) == 0 {
+		return
+	}
+	h.records[h.next] = r
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *history) list() []GenerationRecord {
+	if !h.full {
+		return append([]GenerationRecord(nil), h.records[:h.next]...)
+	}
+	return append(append([]GenerationRecord(nil), h.records[h.next:]...), h.records[:h.next]...)
+}
+
+// resize changes the capacity of the history, keeping the latest records.
+func (h *history) resize(size int) {
+	if size == len(h.records) {
+		return
+	}
+	records := h.list()
+	if len(records) > size {
+		records = records[len(records)-size:]
+	}
+	*h = history{records: make([]GenerationRecord, size)}
+	for _, r := range records {
+		h.add(r)
+	}
+}
+
+// endGeneration records the current generation, which ends at now.
+func (cb *CircuitBreaker) endGeneration(now time.Time) {
+	if cb.generation == 0 {
+		return
+	}
+	cb.history.add(GenerationRecord{
+		Generation: cb.generation,
+		State:      cb.generationState,
+		Start:      cb.generationStart,
+		End:        now,
+		Counts:     cb.counts.snapshot(),
+	})
+}
+
+// History returns the latest past generations of the CircuitBreaker, oldest first,
+// e.g. to reconstruct the failures that made the CircuitBreaker trip.
+// It is empty unless Settings.HistorySize is more than 0.
+func (cb *CircuitBreaker) History() []GenerationRecord {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+	return cb.history.list()
+}
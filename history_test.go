@@ -0,0 +1,37 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{HistorySize: 2})
+	assert.Equal(t, 0, len(cb.History()))
+
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+	cb.Trip()
+	h := cb.History()
+	assert.Equal(t, 1, len(h))
+	assert.Equal(t, uint64(1), h[0].Generation)
+	assert.Equal(t, StateClosed, h[0].State)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, h[0].Counts)
+	assert.False(t, h[0].End.Before(h[0].Start))
+
+	cb.Reset()
+	assert.Nil(t, fail(cb))
+	cb.Reset()
+	h = cb.History()
+	assert.Equal(t, 2, len(h))
+	assert.Equal(t, StateOpen, h[0].State)
+	assert.Equal(t, h[0].End, h[1].Start)
+	assert.Equal(t, StateClosed, h[1].State)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, h[1].Counts)
+
+	cb.UpdateSettings(Settings{HistorySize: 1})
+	h = cb.History()
+	assert.Equal(t, 1, len(h))
+	assert.Equal(t, uint64(3), h[0].Generation)
+}
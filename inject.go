@@ -0,0 +1,28 @@
+package gobreaker
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// ErrInjectedFailure is returned instead of running the request when Settings.InjectFailureRate injects a failure.
+var ErrInjectedFailure = errors.New("injected failure")
+
+// injectFaults returns req delayed by Settings.InjectLatency and failing at Settings.InjectFailureRate,
+// or req itself if no fault is injected.
+func (cb *CircuitBreaker) injectFaults(adm admission, req func() (interface{}, error)) func() (interface{}, error) {
+	if adm.injectFailureRate <= 0 && adm.injectLatency <= 0 {
+		return req
+	}
+
+	rate, latency := adm.injectFailureRate, adm.injectLatency
+	return func() (interface{}, error) {
+		if latency > 0 {
+			cb.sleep(latency)
+		}
+		if rate > 0 && rand.Float64() < rate {
+			return nil, ErrInjectedFailure
+		}
+		return req()
+	}
+}
@@ -0,0 +1,41 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectFailureRate(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{InjectFailureRate: 1})
+
+	var ran bool
+	_, err := cb.Execute(func() (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	assert.Equal(t, ErrInjectedFailure, err)
+	assert.False(t, ran)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, ErrInjectedFailure, succeed(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+
+	cb.UpdateSettings(Settings{})
+	cb.Reset()
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+}
+
+func TestInjectLatency(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{InjectLatency: time.Duration(20) * time.Millisecond})
+
+	start := time.Now()
+	value, err := cb.Execute(func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, "ok", value)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) >= time.Duration(20)*time.Millisecond)
+}
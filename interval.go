@@ -0,0 +1,71 @@
+package gobreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// IntervalPolicy is a type that represents the meaning of Settings.Interval less than or equal to 0.
+type IntervalPolicy int
+
+// These constants are policies of Settings.Interval less than or equal to 0.
+//
+// IntervalNever makes the CircuitBreaker never clear the internal Counts during the closed state,
+// so the failures accumulate until the CircuitBreaker trips.
+//
+// IntervalDefault makes the CircuitBreaker clear the internal Counts every DefaultInterval,
+// so the stale failures don't add up over days.
+const (
+	IntervalNever IntervalPolicy = iota
+	IntervalDefault
+)
+
+// DefaultInterval is the Interval used for Settings.Interval less than or equal to 0 with IntervalDefault.
+const DefaultInterval = time.Duration(60) * time.Second
+
+// String implements stringer interface.
+func (p IntervalPolicy) String() string {
+	switch p {
+	case IntervalNever:
+		return "never"
+	case IntervalDefault:
+		return "default"
+	default:
+		return fmt.Sprintf("unknown interval policy: %d", p)
+	}
+}
+
+// intervalFor returns the effective interval of the CircuitBreaker for Settings.Interval d.
+func (cb *CircuitBreaker) intervalFor(d time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	if cb.intervalPolicy == IntervalDefault {
+		return DefaultInterval
+	}
+	return defaultInterval
+}
+
+// Interval returns the effective Interval of the CircuitBreaker.
+// Interval returns 0 if the CircuitBreaker doesn't clear the internal Counts during the closed state.
+func (cb *CircuitBreaker) Interval() time.Duration {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.interval
+}
+
+// countResetReached reports whether the closed state has counted the outcomes of CountResetThreshold requests.
+func (cb *CircuitBreaker) countResetReached() bool {
+	if cb.countResetThreshold == 0 {
+		return false
+	}
+	counts := cb.counts.snapshot()
+	return counts.TotalSuccesses+counts.TotalFailures >= cb.countResetThreshold
+}
+
+// belowMinimumRequests reports whether the closed state has fewer requests than MinimumRequestThreshold.
+func (cb *CircuitBreaker) belowMinimumRequests() bool {
+	return cb.tripCounts().Requests < cb.minimumRequestThreshold
+}
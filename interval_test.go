@@ -0,0 +1,74 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntervalPolicy(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	assert.Equal(t, time.Duration(0), cb.Interval())
+	assert.True(t, cb.Expiry().IsZero())
+
+	cb = NewCircuitBreaker(Settings{IntervalPolicy: IntervalDefault})
+	assert.Equal(t, DefaultInterval, cb.Interval())
+	assert.False(t, cb.Expiry().IsZero())
+
+	cb.SetInterval(time.Duration(10) * time.Second)
+	assert.Equal(t, time.Duration(10)*time.Second, cb.Interval())
+	cb.SetInterval(0)
+	assert.Equal(t, DefaultInterval, cb.Interval())
+
+	cb.UpdateSettings(Settings{})
+	assert.Equal(t, time.Duration(0), cb.Interval())
+	assert.True(t, cb.Expiry().IsZero())
+
+	assert.Equal(t, "never", IntervalNever.String())
+	assert.Equal(t, "default", IntervalDefault.String())
+	assert.Equal(t, "unknown interval policy: 7", IntervalPolicy(7).String())
+}
+
+func TestCountResetThreshold(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{CountResetThreshold: 3})
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{3, 1, 2, 1, 0}, cb.Counts())
+
+	// the next request starts a new generation
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	// the failures are still seen by ReadyToTrip
+	cb = NewCircuitBreaker(Settings{
+		CountResetThreshold: 3,
+		ReadyToTrip:         func(counts Counts) bool { return counts.ConsecutiveFailures >= 3 },
+	})
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestMinimumRequestThreshold(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		MinimumRequestThreshold: 5,
+		ReadyToTrip:             RateHysteresis{TripFailureRatio: 0.5}.ReadyToTrip,
+	})
+
+	// the first failures don't trip the CircuitBreaker
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	cb.ReportExternalFailure("probe")
+	assert.Equal(t, StateOpen, cb.State())
+
+	// the threshold applies again after the Counts are cleared
+	cb.Reset()
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+}
@@ -0,0 +1,80 @@
+package gobreaker
+
+import "time"
+
+// Lifetime holds the cumulative counters of CircuitBreaker since it was created,
+// which are never reset by the generations, Reset or UpdateSettings, e.g. for SLO reporting.
+//
+// Trips is the number of the transitions to the open state, and Transitions is the number of all the transitions.
+// TimeClosed, TimeHalfOpen and TimeOpen are the total time spent in each state, including the current one.
+// The time spent in the custom states of a StateMachine is not included.
+// LastTransition is the time of the last transition, or the zero time if the state has never changed.
+type Lifetime struct {
+	Since          time.Time
+	Trips          uint64
+	Transitions    uint64
+	TimeClosed     time.Duration
+	TimeHalfOpen   time.Duration
+	TimeOpen       time.Duration
+	LastTransition time.Time
+}
+
+// lifetime accumulates Lifetime.
+type lifetime struct {
+	since     time.Time
+	enteredAt time.Time
+	inState   [StateOpen + 1]time.Duration
+	trips     uint64
+	changes   uint64
+	last      time.Time
+}
+
+func (l *lifetime) start(now time.Time) {
+	l.since = now
+	l.enteredAt = now
+}
+
+func (l *lifetime) onTransition(from, to State, now time.Time) {
+	if !isCustomState(from) {
+		l.inState[from] += now.Sub(l.enteredAt)
+	}
+	l.enteredAt = now
+	l.changes++
+	l.last = now
+	if to == StateOpen {
+		l.trips++
+	}
+}
+
+func (l *lifetime) snapshot(state State, now time.Time) Lifetime {
+	inState := l.inState
+	if !isCustomState(state) {
+		inState[state] += now.Sub(l.enteredAt)
+	}
+	return Lifetime{
+		Since:          l.since,
+		Trips:          l.trips,
+		Transitions:    l.changes,
+		TimeClosed:     inState[StateClosed],
+		TimeHalfOpen:   inState[StateHalfOpen],
+		TimeOpen:       inState[StateOpen],
+		LastTransition: l.last,
+	}
+}
+
+// Lifetime returns the cumulative counters of the CircuitBreaker since it was created.
+func (cb *CircuitBreaker) Lifetime() Lifetime {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	state, _ := cb.currentState(now)
+	return cb.lifetime.snapshot(state, now)
+}
+
+// Lifetime returns the cumulative counters of the TwoStepCircuitBreaker since it was created.
+// See CircuitBreaker.Lifetime.
+func (tscb *TwoStepCircuitBreaker) Lifetime() Lifetime {
+	return tscb.cb.Lifetime()
+}
@@ -0,0 +1,63 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stepClock is a Clock whose time moves only by advance.
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	return c.now
+}
+
+func (c *stepClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (c *stepClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestLifetime(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &stepClock{now: start}
+	cb := NewCircuitBreaker(Settings{Clock: clock, Timeout: time.Duration(30) * time.Second})
+
+	clock.advance(time.Duration(10) * time.Second)
+	assert.Equal(t, Lifetime{Since: start, TimeClosed: time.Duration(10) * time.Second}, cb.Lifetime())
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+
+	clock.advance(time.Duration(31) * time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	clock.advance(time.Duration(5) * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	clock.advance(time.Duration(4) * time.Second)
+	assert.Equal(t, Lifetime{
+		Since:          start,
+		Trips:          1,
+		Transitions:    3,
+		TimeClosed:     time.Duration(14) * time.Second,
+		TimeHalfOpen:   time.Duration(5) * time.Second,
+		TimeOpen:       time.Duration(31) * time.Second,
+		LastTransition: start.Add(time.Duration(46) * time.Second),
+	}, cb.Lifetime())
+
+	// Lifetime is not reset by Reset
+	cb.Trip()
+	cb.Reset()
+	lt := cb.Lifetime()
+	assert.Equal(t, uint64(2), lt.Trips)
+	assert.Equal(t, uint64(5), lt.Transitions)
+}
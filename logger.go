@@ -0,0 +1,50 @@
+package gobreaker
+
+import "fmt"
+
+// Logger is the logger of CircuitBreaker set by Settings.Logger.
+// *zap.SugaredLogger implements Logger, and the package gobreakerlog adapts log/slog to Logger.
+//
+// CircuitBreaker logs the state changes with Infof, or Warnf for the transitions to the open state,
+// the results of the probes in the half-open state with Debugf,
+// and the changes of the settings at runtime with Infof.
+// Every line is a message followed by the fields as key=value pairs, starting with the name of the CircuitBreaker.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+func (cb *CircuitBreaker) logStateChange(from, to State, cause TransitionCause, counts Counts) {
+	if cb.logger == nil {
+		return
+	}
+
+	logf := cb.logger.Infof
+	if to == StateOpen {
+		logf = cb.logger.Warnf
+	}
+	logf("circuit breaker state changed name=%q from=%s to=%s cause=%s generation=%d %s",
+		cb.name, from, to, cause, cb.generation, countsFields(counts))
+}
+
+func (cb *CircuitBreaker) logProbeResult(success bool) {
+	if cb.logger == nil {
+		return
+	}
+	cb.logger.Debugf("circuit breaker probe completed name=%q success=%t %s", cb.name, success, countsFields(cb.counts.snapshot()))
+}
+
+// logSettings logs the settings of the CircuitBreaker that can be changed individually at runtime.
+func (cb *CircuitBreaker) logSettings() {
+	if cb.logger == nil {
+		return
+	}
+	cb.logger.Infof("circuit breaker settings updated name=%q max_requests=%d interval=%s timeout=%s mode=%s",
+		cb.name, cb.maxRequests, cb.interval, cb.timeout, cb.mode)
+}
+
+func countsFields(c Counts) string {
+	return fmt.Sprintf("requests=%d successes=%d failures=%d consecutive_successes=%d consecutive_failures=%d",
+		c.Requests, c.TotalSuccesses, c.TotalFailures, c.ConsecutiveSuccesses, c.ConsecutiveFailures)
+}
@@ -0,0 +1,54 @@
+package gobreaker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.lines = append(l.lines, "DEBUG "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Infof(format string, args ...interface{}) {
+	l.lines = append(l.lines, "INFO "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.lines = append(l.lines, "WARN "+fmt.Sprintf(format, args...))
+}
+
+func TestLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	cb := NewCircuitBreaker(Settings{Name: "log", Logger: logger})
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, []string{
+		`WARN circuit breaker state changed name="log" from=closed to=open cause=ready-to-trip generation=2 ` +
+			`requests=6 successes=0 failures=6 consecutive_successes=0 consecutive_failures=6`,
+	}, logger.lines)
+
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, 4, len(logger.lines))
+	assert.True(t, strings.HasPrefix(logger.lines[1], `INFO circuit breaker state changed name="log" from=open to=half-open`))
+	assert.True(t, strings.HasPrefix(logger.lines[2], `DEBUG circuit breaker probe completed name="log" success=true`))
+	assert.True(t, strings.HasPrefix(logger.lines[3], `INFO circuit breaker state changed name="log" from=half-open to=closed`))
+
+	cb.SetTimeout(time.Duration(10) * time.Second)
+	assert.Equal(t, `INFO circuit breaker settings updated name="log" max_requests=1 interval=0s timeout=10s mode=standard`,
+		logger.lines[4])
+
+	cb.SetReadyToTrip(nil)
+	assert.Equal(t, 6, len(logger.lines))
+	assert.True(t, strings.HasPrefix(logger.lines[5], `INFO circuit breaker settings updated name="log"`))
+}
@@ -0,0 +1,44 @@
+package gobreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenancePolicy is a type that represents how CircuitBreaker behaves during a planned maintenance
+// reported by Settings.IsMaintenanceWindow.
+type MaintenancePolicy int
+
+// These constants are policies of the maintenance windows.
+//
+// MaintenanceNeverTrip makes the CircuitBreaker ignore the failures during the maintenance,
+// so that the expected errors of the maintenance neither trip the CircuitBreaker nor remain in Counts.
+//
+// MaintenanceTripImmediately makes the CircuitBreaker trip on the first failure during the maintenance,
+// so that the requests fail fast until the dependency is back.
+const (
+	MaintenanceNeverTrip MaintenancePolicy = iota
+	MaintenanceTripImmediately
+)
+
+// String implements stringer interface.
+func (p MaintenancePolicy) String() string {
+	switch p {
+	case MaintenanceNeverTrip:
+		return "never-trip"
+	case MaintenanceTripImmediately:
+		return "trip-immediately"
+	default:
+		return fmt.Sprintf("unknown maintenance policy: %d", p)
+	}
+}
+
+// inMaintenance reports whether now is in a maintenance window.
+func (cb *CircuitBreaker) inMaintenance(now time.Time) bool {
+	return cb.isMaintenanceWindow != nil && cb.isMaintenanceWindow(now)
+}
+
+// ignoresFailure reports whether a failure at now is ignored by MaintenanceNeverTrip or DependencyIgnoreFailures.
+func (cb *CircuitBreaker) ignoresFailure(now time.Time) bool {
+	return len(cb.excusedBy) > 0 || cb.maintenancePolicy == MaintenanceNeverTrip && cb.inMaintenance(now)
+}
@@ -0,0 +1,53 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceNeverTrip(t *testing.T) {
+	maintenance := true
+	cb := NewCircuitBreaker(Settings{IsMaintenanceWindow: func(now time.Time) bool { return maintenance }})
+
+	assert.Nil(t, succeed(cb))
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	maintenance = false
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestMaintenanceTripImmediately(t *testing.T) {
+	maintenance := false
+	cb := NewCircuitBreaker(Settings{
+		IsMaintenanceWindow: func(now time.Time) bool { return maintenance },
+		MaintenancePolicy:   MaintenanceTripImmediately,
+	})
+
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	maintenance = true
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestMaintenancePolicy(t *testing.T) {
+	assert.Equal(t, "never-trip", MaintenanceNeverTrip.String())
+	assert.Equal(t, "trip-immediately", MaintenanceTripImmediately.String())
+	assert.Equal(t, "unknown maintenance policy: 9", MaintenancePolicy(9).String())
+
+	err := Settings{MaintenancePolicy: MaintenancePolicy(9)}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
@@ -0,0 +1,154 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStateNotSettable is wrapped by the error returned from SetState when the state can't be set.
+var ErrStateNotSettable = errors.New("state not settable")
+
+// AuditEntry records a manual operation on CircuitBreaker
+// with the state and the Counts just before the operation.
+type AuditEntry struct {
+	Time       time.Time
+	Action     string
+	State      State
+	Counts     Counts
+	Generation uint64
+}
+
+func (cb *CircuitBreaker) audit(action string, now time.Time) {
+	cb.foldShards()
+	cb.auditLog.add(AuditEntry{
+		Time:       now,
+		Action:     action,
+		State:      cb.state,
+		Counts:     cb.counts.snapshot(),
+		Generation: cb.generation,
+	})
+}
+
+// AuditLog returns the latest manual operations on the CircuitBreaker, oldest first.
+// It is empty unless Settings.AuditLogSize is more than 0.
+func (cb *CircuitBreaker) AuditLog() []AuditEntry {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	var entries []AuditEntry
+	for _, e := range cb.auditLog.list() {
+		entries = append(entries, e.(AuditEntry))
+	}
+	return entries
+}
+
+// Trip places the CircuitBreaker into the open state regardless of Counts,
+// e.g. for an operator to stop the traffic to a dependency.
+// The CircuitBreaker becomes half-open after Timeout as usual.
+// Trip has no effect while the CircuitBreaker is disabled by Disable.
+func (cb *CircuitBreaker) Trip() {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.disabled {
+		return
+	}
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+	cb.audit("trip", now)
+	cb.setState(StateOpen, now, CauseManual)
+}
+
+// StateVersion returns the current state and generation of the CircuitBreaker as a consistent pair,
+// e.g. for an external controller to decide on a transition and apply it with CompareAndTrip.
+func (cb *CircuitBreaker) StateVersion() (State, uint64) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.currentState(cb.clock.Now())
+}
+
+// CompareAndTrip trips the CircuitBreaker like Trip only if its generation is still expectedGeneration,
+// so that a decision made on StateVersion is not applied after a concurrent transition.
+// CompareAndTrip returns whether the CircuitBreaker has tripped,
+// which is false if the generation has changed, the CircuitBreaker is already open, or it is disabled.
+func (cb *CircuitBreaker) CompareAndTrip(expectedGeneration uint64) bool {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	state, generation := cb.currentState(now)
+	if cb.disabled || generation != expectedGeneration || state == StateOpen {
+		return false
+	}
+
+	cb.audit("trip", now)
+	cb.setState(StateOpen, now, CauseManual)
+	return true
+}
+
+// Reset places the CircuitBreaker into the closed state and clears Counts.
+// If Settings.AuditLogSize is more than 0, the Counts before the reset are archived in AuditLog.
+func (cb *CircuitBreaker) Reset() {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+	cb.audit("reset", now)
+	if cb.state == StateClosed {
+		cb.toNewGeneration(now)
+		cb.previousCounts = Counts{}
+	} else {
+		cb.setState(StateClosed, now, CauseManual)
+	}
+}
+
+// SetState places the CircuitBreaker into state regardless of Counts, overriding the automatic transitions,
+// e.g. for tests and the operational tooling that would otherwise reach into the internals.
+// A new generation starts even if the CircuitBreaker is already in state,
+// and the transition is notified to OnStateChange and the other hooks with CauseManual.
+// The operation is recorded in AuditLog with reason.
+// SetState returns an error wrapping ErrStateNotSettable if state is unknown,
+// a custom state without Settings.StateMachine, or the CircuitBreaker is disabled by Disable.
+func (cb *CircuitBreaker) SetState(state State, reason string) error {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch {
+	case state < StateClosed || (isCustomState(state) && cb.stateMachine == nil):
+		return fmt.Errorf("%w: %s", ErrStateNotSettable, state)
+	case cb.disabled:
+		return fmt.Errorf("%w: %s is disabled", ErrStateNotSettable, cb.name)
+	}
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+	cb.audit(fmt.Sprintf("set %s: %s", state, reason), now)
+	if cb.state == state {
+		cb.toNewGeneration(now)
+	} else {
+		cb.setState(state, now, CauseManual)
+	}
+	return nil
+}
+
+// Expiry returns the time when the current generation of the CircuitBreaker expires:
+// the end of the current interval in the closed state, or the end of the timeout in the open state.
+// Expiry returns the zero time if the generation doesn't expire.
+func (cb *CircuitBreaker) Expiry() time.Time {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+	return cb.expiry
+}
@@ -0,0 +1,112 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTripAndReset(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Interval: time.Duration(10) * time.Second})
+	assert.Nil(t, fail(cb))
+
+	cb.Trip()
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	assert.True(t, cb.Expiry().After(time.Now().Add(time.Duration(59)*time.Second)))
+
+	cb.Reset()
+	assert.Equal(t, StateClosed, cb.State())
+	assert.False(t, cb.Expiry().IsZero())
+
+	assert.Nil(t, fail(cb))
+	generation := cb.generation
+	cb.Reset()
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	assert.Equal(t, generation+1, cb.generation)
+}
+
+func TestAuditLog(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{AuditLogSize: 2})
+	assert.Equal(t, 0, len(cb.AuditLog()))
+
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+	cb.Reset()
+	log := cb.AuditLog()
+	assert.Equal(t, 1, len(log))
+	assert.Equal(t, "reset", log[0].Action)
+	assert.Equal(t, StateClosed, log[0].State)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, log[0].Counts)
+	assert.Equal(t, uint64(1), log[0].Generation)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+	cb.Trip()
+	cb.Reset()
+	log = cb.AuditLog()
+	assert.Equal(t, 2, len(log))
+	assert.Equal(t, "trip", log[0].Action)
+	assert.Equal(t, "reset", log[1].Action)
+	assert.Equal(t, StateOpen, log[1].State)
+
+	cb.UpdateSettings(Settings{AuditLogSize: 1})
+	log = cb.AuditLog()
+	assert.Equal(t, 1, len(log))
+	assert.Equal(t, "reset", log[0].Action)
+
+	cb.UpdateSettings(Settings{})
+	cb.Reset()
+	assert.Equal(t, 0, len(cb.AuditLog()))
+}
+
+func TestCompareAndTrip(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	state, generation := cb.StateVersion()
+	assert.Equal(t, StateClosed, state)
+	assert.Equal(t, uint64(1), generation)
+
+	cb.Reset()
+	assert.False(t, cb.CompareAndTrip(generation))
+	assert.Equal(t, StateClosed, cb.State())
+
+	_, generation = cb.StateVersion()
+	assert.Equal(t, uint64(2), generation)
+	assert.True(t, cb.CompareAndTrip(generation))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, CauseManual, cb.LastTransition().Cause)
+
+	_, generation = cb.StateVersion()
+	assert.False(t, cb.CompareAndTrip(generation))
+
+	cb.Disable()
+	_, generation = cb.StateVersion()
+	assert.False(t, cb.CompareAndTrip(generation))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestSetState(t *testing.T) {
+	var changes []string
+	cb := NewCircuitBreaker(Settings{
+		AuditLogSize:  4,
+		OnStateChange: func(name string, from, to State) { changes = append(changes, from.String()+"->"+to.String()) },
+	})
+
+	assert.Nil(t, cb.SetState(StateHalfOpen, "test probes"))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	generation := cb.generation
+	assert.Nil(t, cb.SetState(StateHalfOpen, "again"))
+	assert.Equal(t, generation+1, cb.generation)
+	assert.Nil(t, cb.SetState(StateOpen, "incident"))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, []string{"closed->half-open", "half-open->open"}, changes)
+	assert.Equal(t, "set open: incident", cb.AuditLog()[2].Action)
+
+	assert.True(t, errors.Is(cb.SetState(State(5), "custom"), ErrStateNotSettable))
+	assert.True(t, errors.Is(cb.SetState(State(-1), "negative"), ErrStateNotSettable))
+	cb.Disable()
+	assert.True(t, errors.Is(cb.SetState(StateOpen, "disabled"), ErrStateNotSettable))
+	assert.Equal(t, StateClosed, cb.State())
+}
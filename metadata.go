@@ -0,0 +1,68 @@
+package gobreaker
+
+import "time"
+
+// Metadata describes a request, e.g. the name of the method or the key it accesses.
+type Metadata map[string]string
+
+// classify returns the Verdict of err for the request admitted with adm that took d, which is never Undecided.
+func (adm admission) classify(d time.Duration, err error) Verdict {
+	if adm.state == StateHalfOpen && adm.halfOpenIsSuccessful != nil {
+		if adm.halfOpenIsSuccessful(d, err) {
+			return Success
+		}
+		return Failure
+	}
+
+	if adm.classifier != nil {
+		if v := adm.classifier(err); v != Undecided {
+			return v
+		}
+		if err == nil {
+			return Success
+		}
+		return Failure
+	}
+
+	var success bool
+	if adm.isSuccessfulResult != nil {
+		success = adm.isSuccessfulResult(adm.result, err)
+	} else if adm.isSuccessfulWithMD != nil {
+		success = adm.isSuccessfulWithMD(adm.metadata, err)
+	} else {
+		success = adm.isSuccessful(err)
+	}
+	if success {
+		return Success
+	}
+	return Failure
+}
+
+// tripReady reports whether the CircuitBreaker should trip on the failure of a request with md.
+func (cb *CircuitBreaker) tripReady(md Metadata) bool {
+	if cb.inMaintenance(cb.clock.Now()) {
+		return cb.maintenancePolicy == MaintenanceTripImmediately
+	}
+	if cb.burst != nil && cb.burst.exceeded(cb.clock.Now()) {
+		return true
+	}
+	if cb.belowMinimumRequests() {
+		return false
+	}
+	if cb.burnRate != nil {
+		return cb.burnRate.exceeded(cb.clock.Now())
+	}
+	if cb.readyToTripWithMetadata != nil {
+		return cb.readyToTripWithMetadata(md, cb.tripCounts())
+	}
+	if cb.readyToTripWithStats != nil {
+		return cb.readyToTripWithStats(cb.tripCounts(), cb.snapshotStats())
+	}
+	if cb.readyToTripWithWindow != nil {
+		return cb.readyToTripWithWindow(cb.window(cb.clock.Now()))
+	}
+	if cb.readyToTripByCategory != nil {
+		return cb.readyToTripByCategory(cb.tripCounts(), cb.categories)
+	}
+	return cb.readyToTrip(cb.tripCounts())
+}
@@ -0,0 +1,77 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestMetadata(t *testing.T) {
+	var trippedBy Metadata
+	var events []CallCompleteEvent
+	cb := NewCircuitBreaker(Settings{
+		IsSuccessfulWithMetadata: func(md Metadata, err error) bool {
+			return err == nil || (md["method"] == "Get" && err == errNotFound)
+		},
+		ReadyToTripWithMetadata: func(md Metadata, counts Counts) bool {
+			trippedBy = md
+			return counts.ConsecutiveFailures >= 2
+		},
+		OnCallCompleteDetailed: func(ev CallCompleteEvent) { events = append(events, ev) },
+	})
+
+	notFound := func() (interface{}, error) { return nil, errNotFound }
+	get := Metadata{"method": "Get"}
+	put := Metadata{"method": "Put"}
+
+	_, err := cb.ExecuteWithOptions(notFound, WithMetadata(get))
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	_, err = cb.ExecuteWithOptions(notFound, WithMetadata(put))
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, cb.Counts())
+	assert.Equal(t, put, trippedBy)
+	assert.Equal(t, StateClosed, cb.State())
+
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+	done, err := tscb.AllowWithOptions(WithMetadata(Metadata{"method": "Delete"}))
+	assert.Nil(t, err)
+	done(false)
+	assert.Equal(t, Metadata{"method": "Delete"}, trippedBy)
+	assert.Equal(t, StateOpen, cb.State())
+
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, get, events[0].Metadata)
+	assert.Equal(t, put, events[1].Metadata)
+}
+
+func TestIsSuccessfulResult(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		IsSuccessfulResult: func(result interface{}, err error) bool {
+			status, _ := result.(int)
+			return err == nil && status < 500
+		},
+	})
+
+	status := func(code int) func() (interface{}, error) {
+		return func() (interface{}, error) { return code, nil }
+	}
+
+	value, err := cb.Execute(status(200))
+	assert.Equal(t, 200, value)
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	value, err = cb.Execute(status(500))
+	assert.Equal(t, 500, value)
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, cb.Counts())
+
+	_, err = cb.Execute(func() (interface{}, error) { return nil, errNotFound })
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, Counts{3, 1, 2, 0, 2}, cb.Counts())
+}
@@ -0,0 +1,140 @@
+package gobreaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MetricsCollector observes the latencies of CircuitBreaker set by Settings.MetricsCollector.
+//
+// ObserveDecision is called with the time the CircuitBreaker took to allow or reject a request,
+// i.e. the overhead of the CircuitBreaker including the wait of HalfOpenWait, and whether the request was allowed.
+// ObserveCall is called with the duration of every completed request, like OnCallComplete,
+// and the state of the CircuitBreaker when the request was allowed.
+// The methods are called on the goroutines of the requests, so they must be safe for concurrent use.
+type MetricsCollector interface {
+	ObserveDecision(name string, d time.Duration, allowed bool)
+	ObserveCall(name string, d time.Duration, state State)
+}
+
+// DefaultLatencyBuckets are the upper bounds of the buckets of HistogramCollector by default,
+// from a microsecond for the decisions up to 10 seconds for the requests.
+var DefaultLatencyBuckets = []time.Duration{
+	time.Microsecond,
+	time.Duration(5) * time.Microsecond,
+	time.Duration(25) * time.Microsecond,
+	time.Duration(100) * time.Microsecond,
+	time.Duration(500) * time.Microsecond,
+	time.Millisecond,
+	time.Duration(5) * time.Millisecond,
+	time.Duration(25) * time.Millisecond,
+	time.Duration(100) * time.Millisecond,
+	time.Duration(500) * time.Millisecond,
+	time.Second,
+	time.Duration(2500) * time.Millisecond,
+	time.Duration(10) * time.Second,
+}
+
+// Histogram is a Prometheus-style histogram of durations.
+// The Count of every Bucket is cumulative, i.e. the number of the observations less than or equal to its UpperBound,
+// and the observations greater than the last UpperBound are only counted in Count.
+type Histogram struct {
+	Buckets []Bucket
+	Count   uint64
+	Sum     time.Duration
+}
+
+// Bucket is a bucket of Histogram.
+type Bucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    time.Duration
+}
+
+// HistogramCollector is a MetricsCollector that keeps the histograms of the decisions and the requests
+// per name of CircuitBreaker. A HistogramCollector may be shared by the CircuitBreakers of a Group.
+type HistogramCollector struct {
+	buckets []time.Duration
+
+	mutex     sync.Mutex
+	decisions map[string]*histogram
+	calls     map[string]*histogram
+}
+
+// NewHistogramCollector returns a new HistogramCollector with the given upper bounds of the buckets.
+// If no bucket is given, DefaultLatencyBuckets are used.
+func NewHistogramCollector(buckets ...time.Duration) *HistogramCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := make([]time.Duration, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &HistogramCollector{
+		buckets:   sorted,
+		decisions: make(map[string]*histogram),
+		calls:     make(map[string]*histogram),
+	}
+}
+
+// ObserveDecision implements MetricsCollector.
+func (hc *HistogramCollector) ObserveDecision(name string, d time.Duration, allowed bool) {
+	hc.observe(hc.decisions, name, d)
+}
+
+// ObserveCall implements MetricsCollector.
+func (hc *HistogramCollector) ObserveCall(name string, d time.Duration, state State) {
+	hc.observe(hc.calls, name, d)
+}
+
+func (hc *HistogramCollector) observe(histograms map[string]*histogram, name string, d time.Duration) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	h, ok := histograms[name]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(hc.buckets))}
+		histograms[name] = h
+	}
+
+	i := sort.Search(len(hc.buckets), func(i int) bool { return d <= hc.buckets[i] })
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += d
+}
+
+// Decisions returns the histograms of the decisions by name of CircuitBreaker.
+func (hc *HistogramCollector) Decisions() map[string]Histogram {
+	return hc.snapshot(hc.decisions)
+}
+
+// Calls returns the histograms of the requests by name of CircuitBreaker.
+func (hc *HistogramCollector) Calls() map[string]Histogram {
+	return hc.snapshot(hc.calls)
+}
+
+func (hc *HistogramCollector) snapshot(histograms map[string]*histogram) map[string]Histogram {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	snapshot := make(map[string]Histogram, len(histograms))
+	for name, h := range histograms {
+		buckets := make([]Bucket, len(hc.buckets))
+		var cumulative uint64
+		for i, upper := range hc.buckets {
+			cumulative += h.counts[i]
+			buckets[i] = Bucket{UpperBound: upper, Count: cumulative}
+		}
+		snapshot[name] = Histogram{Buckets: buckets, Count: h.count, Sum: h.sum}
+	}
+	return snapshot
+}
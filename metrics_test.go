@@ -0,0 +1,45 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogramCollector(t *testing.T) {
+	hc := NewHistogramCollector(time.Duration(10)*time.Millisecond, time.Millisecond)
+	cb := NewCircuitBreaker(Settings{Name: "db", MetricsCollector: hc})
+
+	assert.Nil(t, succeed(cb))
+	_, err := cb.Execute(func() (interface{}, error) {
+		time.Sleep(time.Duration(5) * time.Millisecond)
+		return nil, nil
+	})
+	assert.Nil(t, err)
+	cb.Trip()
+	assert.True(t, errors.Is(succeed(cb), ErrOpenState))
+
+	decisions := hc.Decisions()["db"]
+	assert.Equal(t, uint64(3), decisions.Count)
+	assert.Equal(t, time.Millisecond, decisions.Buckets[0].UpperBound)
+
+	calls := hc.Calls()["db"]
+	assert.Equal(t, uint64(2), calls.Count)
+	assert.Equal(t, []Bucket{{time.Millisecond, 1}, {time.Duration(10) * time.Millisecond, 2}}, calls.Buckets)
+	assert.True(t, calls.Sum >= time.Duration(5)*time.Millisecond)
+}
+
+func TestHistogramCollectorOverflow(t *testing.T) {
+	hc := NewHistogramCollector(time.Millisecond)
+	hc.ObserveCall("db", time.Second, StateClosed)
+	hc.ObserveCall("db", time.Microsecond, StateClosed)
+
+	assert.Equal(t, Histogram{
+		Buckets: []Bucket{{time.Millisecond, 1}},
+		Count:   2,
+		Sum:     time.Second + time.Microsecond,
+	}, hc.Calls()["db"])
+	assert.Equal(t, 0, len(hc.Decisions()))
+}
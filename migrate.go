@@ -0,0 +1,32 @@
+package gobreaker
+
+// MigrateCircuitBreaker returns a new CircuitBreaker configured with st that carries over the state, the Counts,
+// the generation and the expiry of old, so that rolling out new Settings doesn't close every CircuitBreaker
+// and send a thundering herd onto the dependencies that are still broken.
+// An open CircuitBreaker stays open for the rest of its Timeout rather than the new one.
+// The name of the CircuitBreaker is the name of old, so st.Name is ignored.
+// The cumulative Lifetime and downtime of old are carried over as well.
+//
+// The old CircuitBreaker is left as it is, and the outcomes of the requests it has allowed are counted only by it,
+// so it should be closed with Close once those requests have completed.
+func MigrateCircuitBreaker(old *CircuitBreaker, st Settings) *CircuitBreaker {
+	old.lazyInit()
+	old.mutex.Lock()
+	s := old.snapshotLocked()
+	lifetime := old.lifetime
+	periods := append([]openPeriod(nil), old.downtime.periods...)
+	openedAt := old.downtime.openedAt
+	old.mutex.Unlock()
+
+	cb := RestoreCircuitBreaker(s, st)
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.lifetime = lifetime
+	cb.downtime.periods = periods
+	if cb.state == StateOpen {
+		cb.downtime.openedAt = openedAt
+	}
+	return cb
+}
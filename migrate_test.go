@@ -0,0 +1,53 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateCircuitBreaker(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	old := NewCircuitBreaker(Settings{Name: "db", Clock: clock})
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(old))
+	}
+	assert.Equal(t, StateOpen, old.State())
+	clock.advance(time.Duration(20) * time.Second)
+
+	cb := MigrateCircuitBreaker(old, Settings{
+		Name:        "ignored",
+		Clock:       clock,
+		MaxRequests: 2,
+		Timeout:     time.Duration(10) * time.Second,
+	})
+	assert.Equal(t, "db", cb.Name())
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, old.Expiry(), cb.Expiry())
+	assert.Equal(t, uint64(1), cb.Lifetime().Trips)
+	assert.Equal(t, time.Duration(20)*time.Second, cb.Lifetime().TimeOpen)
+
+	clock.advance(time.Duration(39) * time.Second)
+	assert.Equal(t, StateOpen, cb.State())
+	clock.advance(time.Duration(2) * time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestMigrateCircuitBreakerClosed(t *testing.T) {
+	old := NewCircuitBreaker(Settings{Name: "db"})
+	assert.Nil(t, succeed(old))
+	assert.Nil(t, fail(old))
+
+	cb := MigrateCircuitBreaker(old, Settings{ReadyToTrip: func(counts Counts) bool { return counts.TotalFailures >= 2 }})
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, cb.Counts())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, StateClosed, old.State())
+}
@@ -0,0 +1,179 @@
+package gobreaker
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// CallOption configures a single request run by ExecuteWithOptions or AllowWithOptions.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	idempotent   bool
+	metadata     Metadata
+	ctx          context.Context
+	deadline     time.Time
+	isSuccessful func(err error) bool
+	weight       uint32
+	bypass       bool
+	ticket       <-chan struct{}
+	priority     Priority
+
+	enforcedTimeout time.Duration
+}
+
+// WithIdempotent declares that the request is idempotent, i.e. safe to retry.
+// Idempotent requests are preferred as probes in the half-open state; see Settings.IdempotentProbes.
+func WithIdempotent() CallOption {
+	return func(o *callOptions) {
+		o.idempotent = true
+	}
+}
+
+// WithMetadata attaches md to the request.
+// md is passed to Settings.IsSuccessfulWithMetadata, Settings.ReadyToTripWithMetadata
+// and Settings.OnCallCompleteDetailed.
+func WithMetadata(md Metadata) CallOption {
+	return func(o *callOptions) {
+		o.metadata = md
+	}
+}
+
+// WithDeadline declares the deadline of the request, which is checked against Settings.MinRemainingBudget.
+// For ExecuteContext, the deadline of the context is used as well, and the earlier one is effective.
+func WithDeadline(deadline time.Time) CallOption {
+	return func(o *callOptions) {
+		o.deadline = deadline
+	}
+}
+
+// WithIsSuccessful classifies the error returned from the request with isSuccessful
+// instead of Settings.IsSuccessful, IsSuccessfulWithMetadata, Classifier and HalfOpenIsSuccessful,
+// e.g. for a call type whose errors of the application are expected.
+func WithIsSuccessful(isSuccessful func(err error) bool) CallOption {
+	return func(o *callOptions) {
+		o.isSuccessful = isSuccessful
+	}
+}
+
+// WithWeight counts the request and its outcome as n requests and outcomes, e.g. for a batch call
+// that stands for n calls to the dependency. The additional outcomes are counted like Report.
+// If n is 0, the request is counted once.
+func WithWeight(n uint32) CallOption {
+	return func(o *callOptions) {
+		o.weight = n
+	}
+}
+
+// WithBypass runs the request without the CircuitBreaker, i.e. the request is never rejected and never counted,
+// e.g. for a health check of the dependency itself.
+func WithBypass() CallOption {
+	return func(o *callOptions) {
+		o.bypass = true
+	}
+}
+
+func newCallOptions(opts []CallOption) callOptions {
+	// The CallOptions take a pointer, which makes o escape to the heap, so the common case of none doesn't take it.
+	if len(opts) == 0 {
+		return callOptions{}
+	}
+
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ExecuteWithOptions is like Execute but configures the request with the given CallOptions.
+func (cb *CircuitBreaker) ExecuteWithOptions(req func() (interface{}, error), opts ...CallOption) (interface{}, error) {
+	o := newCallOptions(opts)
+	if o.bypass {
+		return req()
+	}
+	return cb.execute(req, o)
+}
+
+// AllowWithOptions is like Allow but configures the request with the given CallOptions.
+func (tscb *TwoStepCircuitBreaker) AllowWithOptions(opts ...CallOption) (done func(success bool), err error) {
+	o := newCallOptions(opts)
+	if o.bypass {
+		return func(bool) {}, nil
+	}
+	return tscb.allow(o)
+}
+
+// configure applies the per-call overrides of o to adm.
+func (o callOptions) configure(adm *admission) {
+	adm.metadata = o.metadata
+	if o.isSuccessful != nil {
+		adm.isSuccessful = o.isSuccessful
+		adm.isSuccessfulWithMD = nil
+		adm.classifier = nil
+		adm.halfOpenIsSuccessful = nil
+	}
+	if o.enforcedTimeout > 0 {
+		adm.enforcedTimeout = o.enforcedTimeout
+	}
+	if o.weight > 1 {
+		// The additional outcomes are counted under the lock.
+		adm.weight = o.weight
+		adm.shards = nil
+	}
+}
+
+// probeLimit returns the number of requests allowed in the half-open state for a request with opts.
+func (cb *CircuitBreaker) probeLimit(opts callOptions) uint32 {
+	if cb.trialing() {
+		// The trial period of HalfOpenDuration limits only the probes in flight.
+		return math.MaxUint32
+	}
+
+	total := cb.probeTotal() + cb.slowProbes
+	if opts.idempotent {
+		return total
+	}
+	if cb.idempotentProbes >= total {
+		return 0
+	}
+	return total - cb.idempotentProbes
+}
+
+// probeTotal returns the number of the probes allowed and evaluated in a generation of the half-open state.
+func (cb *CircuitBreaker) probeTotal() uint32 {
+	if cb.halfOpenMaxTotal > 0 {
+		return cb.halfOpenMaxTotal
+	}
+	return cb.maxRequests
+}
+
+// probesSaturated reports whether HalfOpenMaxConcurrent probes are in flight,
+// or MaxRequests probes in the trial period of HalfOpenDuration without HalfOpenMaxConcurrent.
+func (cb *CircuitBreaker) probesSaturated() bool {
+	limit := cb.halfOpenMaxConcurrent
+	if limit == 0 && cb.trialing() {
+		limit = cb.maxRequests
+	}
+	if limit == 0 {
+		return false
+	}
+	counts := cb.counts.snapshot()
+	return counts.Requests-counts.TotalSuccesses-counts.TotalFailures >= limit
+}
+
+// lacksBudget reports whether the request with o has a deadline less than min after now.
+func (o callOptions) lacksBudget(min time.Duration, now time.Time) bool {
+	deadline := o.deadline
+	if o.ctx != nil {
+		if d, ok := o.ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+			deadline = d
+		}
+	}
+	return !deadline.IsZero() && deadline.Sub(now) < min
+}
+
+func (cb *CircuitBreaker) insufficientBudget(opts callOptions, now time.Time) bool {
+	return cb.minRemainingBudget > 0 && opts.lacksBudget(cb.minRemainingBudget, now)
+}
@@ -0,0 +1,174 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func succeedIdempotent(cb *CircuitBreaker) error {
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, nil }, WithIdempotent())
+	return err
+}
+
+func TestIdempotentProbes(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{MaxRequests: 3, IdempotentProbes: 2})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+	done, err := tscb.Allow() // the only slot for non-idempotent requests
+	assert.Nil(t, err)
+	_, err = tscb.Allow()
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+
+	done2, err := tscb.AllowWithOptions(WithIdempotent())
+	assert.Nil(t, err)
+	assert.Nil(t, succeedIdempotent(cb))
+	assert.True(t, errors.Is(succeedIdempotent(cb), ErrTooManyRequests))
+
+	done(true)
+	done2(true)
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestIdempotentProbesOnly(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{MaxRequests: 1, IdempotentProbes: 5})
+	assert.Equal(t, uint32(0), cb.probeLimit(callOptions{}))
+	assert.Equal(t, uint32(1), cb.probeLimit(callOptions{idempotent: true}))
+
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.True(t, errors.Is(succeed(cb), ErrTooManyRequests))
+	assert.Nil(t, succeedIdempotent(cb))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func succeedWithDeadline(cb *CircuitBreaker, deadline time.Time) error {
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, nil }, WithDeadline(deadline))
+	return err
+}
+
+func TestMinRemainingBudget(t *testing.T) {
+	for _, shards := range []int{0, 4} {
+		cb := NewCircuitBreaker(Settings{MinRemainingBudget: time.Duration(100) * time.Millisecond, CounterShards: shards})
+
+		err := succeedWithDeadline(cb, time.Now().Add(time.Duration(10)*time.Millisecond))
+		assert.True(t, errors.Is(err, ErrInsufficientBudget))
+		assert.True(t, IsRejection(err))
+		assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+		assert.Nil(t, succeedWithDeadline(cb, time.Now().Add(time.Second)))
+		assert.Nil(t, succeed(cb))
+		assert.Equal(t, Counts{2, 2, 0, 2, 0}, cb.Counts())
+	}
+}
+
+func TestMinRemainingBudgetContext(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{MinRemainingBudget: time.Duration(100) * time.Millisecond})
+	req := func(ctx context.Context) (interface{}, error) { return nil, nil }
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+	defer cancel()
+	_, err := cb.ExecuteContext(ctx, req)
+	assert.True(t, errors.Is(err, ErrInsufficientBudget))
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = cb.ExecuteContext(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	cb = NewCircuitBreaker(Settings{})
+	assert.Nil(t, succeedWithDeadline(cb, time.Now()))
+}
+
+func TestHalfOpenMaxConcurrent(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{HalfOpenMaxConcurrent: 2, HalfOpenMaxTotal: 4})
+	tscb.cb.Trip()
+	pseudoSleep(tscb.cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, tscb.State())
+
+	done1, err := tscb.Allow()
+	assert.Nil(t, err)
+	done2, err := tscb.Allow()
+	assert.Nil(t, err)
+	_, err = tscb.Allow()
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(err))
+
+	done1(true)
+	done3, err := tscb.Allow()
+	assert.Nil(t, err)
+	done2(true)
+	done3(true)
+	assert.Equal(t, StateHalfOpen, tscb.State())
+
+	done4, err := tscb.Allow()
+	assert.Nil(t, err)
+	_, err = tscb.Allow()
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(err))
+	done4(true)
+	assert.Equal(t, StateClosed, tscb.State())
+}
+
+func TestHalfOpenMaxTotal(t *testing.T) {
+	h := RateHysteresis{CloseSuccessRatio: 0.6, MinRequests: 3}
+	cb := NewCircuitBreaker(Settings{HalfOpenMaxTotal: 3, ReadyToClose: h.ReadyToClose, ReadyToReopen: h.ReadyToReopen})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestWithIsSuccessful(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, errNotFound },
+		WithIsSuccessful(func(err error) bool { return err == nil || errors.Is(err, errNotFound) }))
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	_, err = cb.ExecuteWithOptions(func() (interface{}, error) { return nil, errNotFound })
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, cb.Counts())
+}
+
+func TestWithWeight(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{CounterShards: 4})
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, nil }, WithWeight(3))
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{3, 3, 0, 3, 0}, cb.Counts())
+
+	_, err = cb.ExecuteWithOptions(func() (interface{}, error) { return nil, errors.New("fail") }, WithWeight(5))
+	assert.NotNil(t, err)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{8, 3, 5, 0, 5}, cb.Counts())
+
+	_, err = cb.ExecuteWithOptions(func() (interface{}, error) { return nil, errors.New("fail") }, WithWeight(2))
+	assert.NotNil(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestWithBypass(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	cb.Trip()
+
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, errors.New("fail") }, WithBypass())
+	assert.EqualError(t, err, "fail")
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Counts{}, cb.Counts())
+
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+	tscb.cb.Trip()
+	done, err := tscb.AllowWithOptions(WithBypass())
+	assert.Nil(t, err)
+	done(false)
+	assert.Equal(t, Counts{}, tscb.Counts())
+}
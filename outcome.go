@@ -0,0 +1,51 @@
+package gobreaker
+
+import "fmt"
+
+// Outcome is the outcome of a request allowed by AllowOutcome.
+type Outcome int
+
+// These constants are outcomes of a request.
+//
+// OutcomeCanceled means the caller aborted before actually issuing the request,
+// so the request is forgotten by the TwoStepCircuitBreaker without counting a success or a failure,
+// and frees its slot among the probes of the half-open state.
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeFailure
+	OutcomeCanceled
+)
+
+// String implements stringer interface.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeCanceled:
+		return "canceled"
+	default:
+		return fmt.Sprintf("unknown outcome: %d", o)
+	}
+}
+
+// AllowOutcome is like Allow but returns a callback that takes the Outcome of the request,
+// so that the caller can also cancel the request with OutcomeCanceled.
+func (tscb *TwoStepCircuitBreaker) AllowOutcome(opts ...CallOption) (done func(outcome Outcome), err error) {
+	adm, err := tscb.cb.beforeRequest(newCallOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(outcome Outcome) {
+		switch outcome {
+		case OutcomeSuccess:
+			tscb.cb.afterRequest(adm, true)
+		case OutcomeFailure:
+			tscb.cb.afterRequest(adm, false)
+		default: // OutcomeCanceled
+			tscb.cb.releaseRequest(adm)
+		}
+	}, nil
+}
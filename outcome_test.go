@@ -0,0 +1,48 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, "success", OutcomeSuccess.String())
+	assert.Equal(t, "failure", OutcomeFailure.String())
+	assert.Equal(t, "canceled", OutcomeCanceled.String())
+	assert.Equal(t, "unknown outcome: 100", Outcome(100).String())
+}
+
+func TestAllowOutcome(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+
+	done, err := tscb.AllowOutcome()
+	assert.Nil(t, err)
+	done(OutcomeSuccess)
+	done, err = tscb.AllowOutcome()
+	assert.Nil(t, err)
+	done(OutcomeFailure)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, tscb.Counts())
+
+	done, err = tscb.AllowOutcome()
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{3, 1, 1, 0, 1}, tscb.Counts())
+	done(OutcomeCanceled)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, tscb.Counts())
+
+	// a canceled probe frees its slot in the half-open state
+	tscb.cb.Trip()
+	pseudoSleep(tscb.cb, time.Duration(60)*time.Second)
+	done, err = tscb.AllowOutcome()
+	assert.Nil(t, err)
+	assert.Equal(t, StateHalfOpen, tscb.State())
+	_, err = tscb.AllowOutcome()
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	done(OutcomeCanceled)
+	done, err = tscb.AllowOutcome()
+	assert.Nil(t, err)
+	done(OutcomeSuccess)
+	assert.Equal(t, StateClosed, tscb.State())
+}
@@ -0,0 +1,151 @@
+package gobreaker
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// OutlierDetection configures the outlier detection of a Group, like the outlier detection of Envoy.
+// A CircuitBreaker in the Group is an outlier if its failure rate is a statistical outlier versus its siblings,
+// and an outlier is tripped alone while the siblings keep serving.
+//
+// MinRequests is the minimum number of the completed requests in the current generation of a CircuitBreaker
+// for the CircuitBreaker to be analyzed. If MinRequests is 0, MinRequests is set to 100.
+//
+// MinBreakers is the minimum number of the analyzed CircuitBreakers for the analysis to run.
+// If MinBreakers is 0, MinBreakers is set to 5.
+//
+// StdevFactor decides the outliers: a CircuitBreaker whose failure rate is more than
+// the mean plus StdevFactor times the standard deviation of the failure rates is an outlier.
+// If StdevFactor is less than or equal to 0, StdevFactor is set to 1.9.
+//
+// GroupBreaker, if not nil, is tripped instead of the outliers when most of the dependency is failing,
+// i.e. when FailingRatio or more of the analyzed CircuitBreakers have the failure rate of FailureRate or more.
+// If FailureRate or FailingRatio is less than or equal to 0, it is set to 0.5.
+//
+// Interval is the period of the analysis run by RunOutlierDetection.
+// If Interval is less than or equal to 0, Interval is set to 10 seconds.
+type OutlierDetection struct {
+	MinRequests  uint32
+	MinBreakers  int
+	StdevFactor  float64
+	GroupBreaker *CircuitBreaker
+	FailureRate  float64
+	FailingRatio float64
+	Interval     time.Duration
+}
+
+const (
+	defaultOutlierMinRequests  = 100
+	defaultOutlierMinBreakers  = 5
+	defaultOutlierStdevFactor  = 1.9
+	defaultOutlierFailureRate  = 0.5
+	defaultOutlierFailingRatio = 0.5
+	defaultOutlierInterval     = time.Duration(10) * time.Second
+)
+
+func (od OutlierDetection) withDefaults() OutlierDetection {
+	if od.MinRequests == 0 {
+		od.MinRequests = defaultOutlierMinRequests
+	}
+	if od.MinBreakers == 0 {
+		od.MinBreakers = defaultOutlierMinBreakers
+	}
+	if od.StdevFactor <= 0 {
+		od.StdevFactor = defaultOutlierStdevFactor
+	}
+	if od.FailureRate <= 0 {
+		od.FailureRate = defaultOutlierFailureRate
+	}
+	if od.FailingRatio <= 0 {
+		od.FailingRatio = defaultOutlierFailingRatio
+	}
+	if od.Interval <= 0 {
+		od.Interval = defaultOutlierInterval
+	}
+	return od
+}
+
+// DetectOutliers analyzes the failure rates of the closed CircuitBreakers in the Group once,
+// trips the outliers, and returns the sorted names of the tripped CircuitBreakers.
+// If od.GroupBreaker is tripped because most of the dependency is failing, no outlier is tripped.
+func (g *Group) DetectOutliers(od OutlierDetection) []string {
+	od = od.withDefaults()
+
+	names := g.Names()
+	breakers := make([]*CircuitBreaker, 0, len(names))
+	rates := make([]float64, 0, len(names))
+	for _, name := range names {
+		cb, ok := g.Lookup(name)
+		if !ok || cb.State() != StateClosed {
+			continue
+		}
+		counts := cb.Counts()
+		completed := counts.TotalSuccesses + counts.TotalFailures
+		if completed == 0 || completed < od.MinRequests {
+			continue
+		}
+		breakers = append(breakers, cb)
+		rates = append(rates, float64(counts.TotalFailures)/float64(completed))
+	}
+	if len(breakers) == 0 || len(breakers) < od.MinBreakers {
+		return nil
+	}
+
+	if od.GroupBreaker != nil {
+		var failing int
+		for _, rate := range rates {
+			if rate >= od.FailureRate {
+				failing++
+			}
+		}
+		if float64(failing)/float64(len(rates)) >= od.FailingRatio {
+			od.GroupBreaker.Trip()
+			return nil
+		}
+	}
+
+	mean, stdev := meanStdev(rates)
+	threshold := mean + od.StdevFactor*stdev
+
+	var tripped []string
+	for i, cb := range breakers {
+		if rates[i] > threshold {
+			cb.Trip()
+			tripped = append(tripped, cb.Name())
+		}
+	}
+	return tripped
+}
+
+func meanStdev(xs []float64) (mean, stdev float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+
+	var variance float64
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= float64(len(xs))
+	return mean, math.Sqrt(variance)
+}
+
+// RunOutlierDetection runs DetectOutliers every od.Interval until ctx is done, and returns the error of ctx.
+func (g *Group) RunOutlierDetection(ctx context.Context, od OutlierDetection) error {
+	od = od.withDefaults()
+
+	ticker := time.NewTicker(od.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.DetectOutliers(od)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
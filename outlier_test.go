@@ -0,0 +1,63 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newOutlierGroup(failures ...int) *Group {
+	g := NewGroup(Settings{ReadyToTrip: func(counts Counts) bool { return false }})
+	for i, n := range failures {
+		cb := g.Get(fmt.Sprintf("host-%d", i))
+		for j := 0; j < 10; j++ {
+			if j < n {
+				cb.Execute(func() (interface{}, error) { return nil, errors.New("fail") })
+			} else {
+				cb.Execute(func() (interface{}, error) { return nil, nil })
+			}
+		}
+	}
+	return g
+}
+
+func TestDetectOutliers(t *testing.T) {
+	od := OutlierDetection{MinRequests: 10}
+
+	g := newOutlierGroup(0, 1, 0, 9, 1, 0)
+	assert.Equal(t, []string{"host-3"}, g.DetectOutliers(od))
+	assert.Equal(t, StateOpen, g.Get("host-3").State())
+	assert.Equal(t, StateClosed, g.Get("host-1").State())
+
+	// no outliers among similar failure rates
+	g = newOutlierGroup(3, 4, 3, 4, 3)
+	assert.Nil(t, g.DetectOutliers(od))
+
+	// too few CircuitBreakers to analyze
+	g = newOutlierGroup(0, 0, 0, 9)
+	assert.Nil(t, g.DetectOutliers(od))
+
+	// too few requests to analyze
+	g = newOutlierGroup(0, 1, 0, 9, 1, 0)
+	od.MinRequests = 11
+	assert.Nil(t, g.DetectOutliers(od))
+}
+
+func TestDetectOutliersGroupBreaker(t *testing.T) {
+	parent := NewCircuitBreaker(Settings{})
+	od := OutlierDetection{MinRequests: 10, GroupBreaker: parent}
+
+	g := newOutlierGroup(9, 8, 0, 9, 7, 0)
+	assert.Nil(t, g.DetectOutliers(od))
+	assert.Equal(t, StateOpen, parent.State())
+	for _, name := range g.Names() {
+		assert.Equal(t, StateClosed, g.Get(name).State())
+	}
+
+	parent.Reset()
+	g = newOutlierGroup(0, 1, 0, 9, 1, 0)
+	assert.Equal(t, []string{"host-3"}, g.DetectOutliers(od))
+	assert.Equal(t, StateClosed, parent.State())
+}
@@ -0,0 +1,69 @@
+package gobreaker
+
+import "sort"
+
+// Overview is the aggregated health of the CircuitBreakers in a Group, e.g. for a health summary endpoint.
+// Closed, HalfOpen and Open are the numbers of the CircuitBreakers in each state,
+// and Custom is the number of the CircuitBreakers in the custom states of a StateMachine.
+// Top is the CircuitBreakers with the highest failure rates of their current generations, in descending order.
+type Overview struct {
+	Closed   int
+	HalfOpen int
+	Open     int
+	Custom   int
+	Top      []BreakerHealth
+}
+
+// BreakerHealth is the health of a CircuitBreaker in Overview.
+// FailureRate is the ratio of the failures to the completed requests of the current generation.
+type BreakerHealth struct {
+	Name        string
+	State       State
+	Counts      Counts
+	FailureRate float64
+}
+
+// Walk calls fn with every registered CircuitBreaker in the order of the names.
+// fn is called without the lock of the Group, so fn may use the Group.
+func (g *Group) Walk(fn func(name string, cb *CircuitBreaker)) {
+	for _, name := range g.Names() {
+		if cb, ok := g.Lookup(name); ok {
+			fn(name, cb)
+		}
+	}
+}
+
+// Overview returns the aggregated health of the registered CircuitBreakers
+// with up to n CircuitBreakers in Overview.Top. The CircuitBreakers without completed requests are not in Top.
+func (g *Group) Overview(n int) Overview {
+	var ov Overview
+	var top []BreakerHealth
+	g.Walk(func(name string, cb *CircuitBreaker) {
+		h := BreakerHealth{Name: name, State: cb.State(), Counts: cb.Counts()}
+		switch h.State {
+		case StateClosed:
+			ov.Closed++
+		case StateHalfOpen:
+			ov.HalfOpen++
+		case StateOpen:
+			ov.Open++
+		default:
+			ov.Custom++
+		}
+
+		completed := h.Counts.TotalSuccesses + h.Counts.TotalFailures
+		if completed > 0 {
+			h.FailureRate = float64(h.Counts.TotalFailures) / float64(completed)
+			top = append(top, h)
+		}
+	})
+
+	sort.SliceStable(top, func(i, j int) bool { return top[i].FailureRate > top[j].FailureRate })
+	if len(top) > n {
+		top = top[:n]
+	}
+	if len(top) > 0 {
+		ov.Top = top
+	}
+	return ov
+}
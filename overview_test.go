@@ -0,0 +1,37 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverview(t *testing.T) {
+	g := NewGroup(Settings{})
+	g.Preload("a", "b", "c", "d")
+
+	assert.Nil(t, succeed(g.Get("a")))
+	assert.Nil(t, fail(g.Get("a")))
+	assert.Nil(t, fail(g.Get("b")))
+	assert.Nil(t, succeed(g.Get("c")))
+	g.Get("d").Trip()
+
+	ov := g.Overview(2)
+	assert.Equal(t, 3, ov.Closed)
+	assert.Equal(t, 1, ov.Open)
+	assert.Equal(t, 0, ov.HalfOpen)
+	assert.Equal(t, []BreakerHealth{
+		{Name: "b", State: StateClosed, Counts: Counts{1, 0, 1, 0, 1}, FailureRate: 1},
+		{Name: "a", State: StateClosed, Counts: Counts{2, 1, 1, 0, 1}, FailureRate: 0.5},
+	}, ov.Top)
+	assert.Len(t, g.Overview(10).Top, 3)
+	assert.Nil(t, NewGroup(Settings{}).Overview(10).Top)
+
+	var names []string
+	g.Walk(func(name string, cb *CircuitBreaker) {
+		names = append(names, name)
+		g.Remove(name)
+	})
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names)
+	assert.Empty(t, g.Names())
+}
@@ -0,0 +1,53 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParent(t *testing.T) {
+	host := NewCircuitBreaker(Settings{Name: "host"})
+	users := NewCircuitBreaker(Settings{Name: "users", Parent: host})
+	orders := NewCircuitBreaker(Settings{Name: "orders", Parent: host})
+
+	// child statistics roll up to the parent
+	assert.Nil(t, succeed(users))
+	assert.Nil(t, fail(orders))
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, users.Counts())
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, orders.Counts())
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, host.Counts())
+
+	// tripping the parent rejects the requests of the children
+	host.Trip()
+	err := succeed(users)
+	assert.True(t, errors.Is(err, ErrOpenState))
+	var re *RejectionError
+	assert.True(t, errors.As(err, &re))
+	assert.Equal(t, "host", re.Name)
+	assert.Equal(t, StateClosed, users.State())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, users.Counts())
+
+	// a rejection by the child doesn't consume the parent's requests
+	host.Reset()
+	users.Trip()
+	assert.Error(t, succeed(users))
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, host.Counts())
+
+	host.Trip()
+	pseudoSleep(host, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, host.State())
+	assert.Error(t, succeed(users))
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, host.Counts())
+	assert.Nil(t, succeed(orders))
+	assert.Equal(t, StateClosed, host.State())
+}
+
+func TestParentTwoStep(t *testing.T) {
+	host := NewCircuitBreaker(Settings{Name: "host"})
+	child := NewTwoStepCircuitBreaker(Settings{Name: "child", Parent: host})
+	assert.Nil(t, fail2Step(child))
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, host.Counts())
+}
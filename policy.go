@@ -0,0 +1,45 @@
+package gobreaker
+
+// RateHysteresis is a rate-based policy with separate thresholds for opening and closing
+// the CircuitBreaker, which avoids rapid oscillation around a single threshold.
+// Set its methods to Settings.ReadyToTrip, Settings.ReadyToClose and Settings.ReadyToReopen.
+//
+// TripFailureRatio is the failure ratio in the closed state at or above which the CircuitBreaker trips.
+//
+// CloseSuccessRatio is the success ratio of the completed requests in the half-open state
+// at or above which the CircuitBreaker closes.
+//
+// MinRequests is the minimum number of requests in the closed state, and the minimum number of
+// completed requests in the half-open state, before the ratios are evaluated.
+// MinRequests should not be more than Settings.MaxRequests.
+type RateHysteresis struct {
+	TripFailureRatio  float64
+	CloseSuccessRatio float64
+	MinRequests       uint32
+}
+
+// ReadyToTrip returns true when the failure ratio reaches TripFailureRatio.
+func (h RateHysteresis) ReadyToTrip(counts Counts) bool {
+	if counts.Requests == 0 || counts.Requests < h.MinRequests {
+		return false
+	}
+	return float64(counts.TotalFailures)/float64(counts.Requests) >= h.TripFailureRatio
+}
+
+// ReadyToClose returns true when the success ratio of the completed requests reaches CloseSuccessRatio.
+func (h RateHysteresis) ReadyToClose(counts Counts) bool {
+	completed := counts.TotalSuccesses + counts.TotalFailures
+	if completed == 0 || completed < h.MinRequests {
+		return false
+	}
+	return float64(counts.TotalSuccesses)/float64(completed) >= h.CloseSuccessRatio
+}
+
+// ReadyToReopen returns true when the success ratio of the completed requests is below CloseSuccessRatio.
+func (h RateHysteresis) ReadyToReopen(counts Counts) bool {
+	completed := counts.TotalSuccesses + counts.TotalFailures
+	if completed == 0 || completed < h.MinRequests {
+		return false
+	}
+	return float64(counts.TotalSuccesses)/float64(completed) < h.CloseSuccessRatio
+}
@@ -0,0 +1,66 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateHysteresis(t *testing.T) {
+	h := RateHysteresis{TripFailureRatio: 0.5, CloseSuccessRatio: 0.9, MinRequests: 10}
+	cb := NewCircuitBreaker(Settings{
+		MaxRequests:   10,
+		ReadyToTrip:   h.ReadyToTrip,
+		ReadyToClose:  h.ReadyToClose,
+		ReadyToReopen: h.ReadyToReopen,
+	})
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State()) // under MinRequests
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb)) // failure ratio: 6/10 >= 0.5
+	assert.Equal(t, StateOpen, cb.State())
+
+	// a failure in the half-open state doesn't reopen before MinRequests
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, fail(cb))
+	for i := 0; i < 8; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb)) // success ratio: 9/10 >= 0.9
+	assert.Equal(t, StateClosed, cb.State())
+
+	// reopen when the success ratio is below CloseSuccessRatio
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	for i := 0; i < 8; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb)) // success ratio: 8/10 < 0.9
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestReadyToCloseProbesCompleted(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		MaxRequests:  2,
+		ReadyToClose: func(counts Counts) bool { return false },
+	})
+	cb.setState(StateHalfOpen, time.Now(), CauseManual)
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb)) // all probes completed without closing
+	assert.Equal(t, StateOpen, cb.State())
+}
@@ -0,0 +1,170 @@
+package gobreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned for the requests submitted to a Pool after Close.
+var ErrPoolClosed = errors.New("pool is closed")
+
+// PoolPolicy decides what a Pool does with a request submitted while its CircuitBreaker rejects requests.
+type PoolPolicy int
+
+const (
+	// PoolReject rejects the request at Submit, so that the caller can fail fast or fall back.
+	PoolReject PoolPolicy = iota
+	// PoolQueue keeps the request in the queue until the CircuitBreaker allows it.
+	PoolQueue
+)
+
+// minPoolPause is the shortest wait of a queued request rejected without RetryAfter,
+// e.g. while the probes of the half-open state are in flight.
+const minPoolPause = time.Duration(100) * time.Millisecond
+
+// Pool runs the requests on a fixed number of workers guarded by a CircuitBreaker.
+// Every request is allowed by the CircuitBreaker exactly once and its outcome is counted like Execute,
+// so the accounting is the same as running the requests with Execute one by one.
+type Pool struct {
+	cb     *CircuitBreaker
+	policy PoolPolicy
+	tasks  chan poolTask
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	mutex    sync.RWMutex
+	closed   bool
+	stopOnce sync.Once
+}
+
+type poolTask struct {
+	req      func() (interface{}, error)
+	adm      admission
+	admitted bool
+	result   chan Result
+}
+
+// NewPool returns a new Pool of the given number of workers and queue size, guarded by cb.
+// If workers is less than or equal to 0, workers is set to 1.
+// If queueSize is less than 0, queueSize is set to 0, i.e. Submit waits for an idle worker.
+func NewPool(cb *CircuitBreaker, workers, queueSize int, policy PoolPolicy) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool{
+		cb:     cb,
+		policy: policy,
+		tasks:  make(chan poolTask, queueSize),
+		stop:   make(chan struct{}),
+	}
+	for w := 0; w < workers; w++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the given request and returns a channel that receives the Result of the request and is closed.
+// Submit blocks while the queue is full.
+// With PoolReject, the request is allowed or rejected by the CircuitBreaker at Submit,
+// and the channel receives the rejection at once.
+// With PoolQueue, the request is allowed when a worker picks it up,
+// and the worker waits until the CircuitBreaker allows it, holding up the requests queued after it.
+// A panic in the request is counted like Execute and then crashes the program, like ExecuteAsync.
+func (p *Pool) Submit(req func() (interface{}, error)) <-chan Result {
+	task := poolTask{req: req, result: make(chan Result, 1)}
+
+	if p.policy == PoolReject {
+		adm, err := p.cb.beforeRequest(callOptions{})
+		if err != nil {
+			task.finish(Result{Err: err})
+			return task.result
+		}
+		task.adm, task.admitted = adm, true
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.closed {
+		if task.admitted {
+			p.cb.releaseRequest(task.adm)
+		}
+		task.finish(Result{Err: ErrPoolClosed})
+		return task.result
+	}
+	p.tasks <- task
+	return task.result
+}
+
+// Close stops accepting requests and waits for the queued requests to complete.
+// The queued requests waiting for the CircuitBreaker with PoolQueue get the last rejection instead.
+func (p *Pool) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	p.mutex.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mutex.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for task := range p.tasks {
+		task.finish(p.run(task))
+	}
+}
+
+func (p *Pool) run(task poolTask) Result {
+	if task.admitted {
+		value, err := p.cb.run(task.adm, task.req)
+		return Result{Value: value, Err: err}
+	}
+
+	for {
+		adm, err := p.cb.beforeRequest(callOptions{})
+		if err == nil {
+			value, err := p.cb.run(adm, task.req)
+			return Result{Value: value, Err: err}
+		}
+		if !p.pause(err) {
+			return Result{Err: err}
+		}
+	}
+}
+
+// pause waits until the CircuitBreaker may allow the request rejected with err,
+// and reports false if the Pool is closed first.
+func (p *Pool) pause(err error) bool {
+	d := minPoolPause
+	var re *RejectionError
+	if errors.As(err, &re) && re.RetryAfter > d {
+		d = re.RetryAfter
+	}
+
+	wake := make(chan struct{})
+	timer := p.cb.clock.AfterFunc(d, func() { close(wake) })
+	defer timer.Stop()
+
+	select {
+	case <-wake:
+		return true
+	case <-p.stop:
+		return false
+	}
+}
+
+func (t poolTask) finish(r Result) {
+	t.result <- r
+	close(t.result)
+}
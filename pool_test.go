@@ -0,0 +1,62 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolReject(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Timeout: time.Duration(61) * time.Second})
+	p := NewPool(cb, 2, 4, PoolReject)
+	errFail := errors.New("fail")
+
+	r := <-p.Submit(func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, Result{Value: "ok"}, r)
+	r = <-p.Submit(func() (interface{}, error) { return nil, errFail })
+	assert.Equal(t, errFail, r.Err)
+	assert.Equal(t, Counts{Requests: 2, TotalSuccesses: 1, TotalFailures: 1, ConsecutiveFailures: 1}, cb.Counts())
+
+	cb.Trip()
+	var ran bool
+	r = <-p.Submit(func() (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	assert.True(t, errors.Is(r.Err, ErrOpenState))
+	assert.False(t, ran)
+
+	p.Close()
+	cb.Reset()
+	r = <-p.Submit(func() (interface{}, error) { return nil, nil })
+	assert.Equal(t, ErrPoolClosed, r.Err)
+	assert.Equal(t, Counts{}, cb.Counts())
+}
+
+func TestPoolQueue(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Timeout: time.Duration(200) * time.Millisecond})
+	p := NewPool(cb, 1, 4, PoolQueue)
+	defer p.Close()
+
+	cb.Trip()
+	start := time.Now()
+	r := <-p.Submit(func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, Result{Value: "ok"}, r)
+	assert.True(t, time.Since(start) >= time.Duration(200)*time.Millisecond)
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestPoolCloseWhileQueued(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Timeout: time.Duration(61) * time.Second})
+	p := NewPool(cb, 1, 4, PoolQueue)
+
+	cb.Trip()
+	ch := p.Submit(func() (interface{}, error) { return nil, nil })
+	p.Close()
+
+	r := <-ch
+	assert.True(t, errors.Is(r.Err, ErrOpenState))
+	assert.Equal(t, StateOpen, cb.State())
+}
@@ -0,0 +1,61 @@
+package gobreaker
+
+import "time"
+
+// PresetAggressive returns the Settings named name that trip quickly and probe again soon,
+// for a dependency whose failures are expensive to wait for and cheap to retry later.
+// The CircuitBreaker trips when at least a quarter of at least 10 requests fail within 10 seconds,
+// stays open for 5 seconds, and then closes after a single successful probe.
+func PresetAggressive(name string) Settings {
+	return Settings{
+		Name:          name,
+		MaxRequests:   1,
+		Interval:      time.Duration(10) * time.Second,
+		Timeout:       time.Duration(5) * time.Second,
+		ReadyToTrip:   RateHysteresis{TripFailureRatio: 0.25, MinRequests: 10}.ReadyToTrip,
+		TimeoutJitter: time.Second,
+	}
+}
+
+// PresetConservative returns the Settings named name that trip only on a sustained outage
+// and recover slowly, for a dependency that is occasionally flaky but rarely down.
+// The CircuitBreaker trips when at least three quarters of at least 50 requests fail within 2 minutes,
+// stays open for a minute, closes after 5 successful probes, and then ramps the traffic up over a minute.
+func PresetConservative(name string) Settings {
+	return Settings{
+		Name:          name,
+		MaxRequests:   5,
+		Interval:      time.Duration(2) * time.Minute,
+		Timeout:       time.Minute,
+		ReadyToTrip:   RateHysteresis{TripFailureRatio: 0.75, MinRequests: 50}.ReadyToTrip,
+		RecoveryRamp:  &RecoveryRamp{Duration: time.Minute},
+		TimeoutJitter: time.Duration(10) * time.Second,
+	}
+}
+
+// PresetLatencySensitive returns the Settings named name that treat slowness as an outage,
+// for a dependency on a latency-critical path whose 99th percentile duration should stay within p99.
+// The CircuitBreaker trips when at least half of at least 20 requests fail within 30 seconds
+// or their estimated 99th percentile duration exceeds p99, stays open for 15 seconds,
+// and then closes after 3 probes that succeed within p99, ramping the traffic up over 30 seconds.
+// ExecuteContext cancels the requests after twice p99.
+func PresetLatencySensitive(name string, p99 time.Duration) Settings {
+	return Settings{
+		Name:        name,
+		MaxRequests: 3,
+		Interval:    time.Duration(30) * time.Second,
+		Timeout:     time.Duration(15) * time.Second,
+		ReadyToTripWithStats: func(counts Counts, stats Stats) bool {
+			if counts.Requests < 20 {
+				return false
+			}
+			return float64(counts.TotalFailures)/float64(counts.Requests) >= 0.5 || stats.P99 > p99
+		},
+		HalfOpenIsSuccessful: func(d time.Duration, err error) bool {
+			return err == nil && d <= p99
+		},
+		RecoveryRamp:  &RecoveryRamp{Duration: time.Duration(30) * time.Second},
+		CallTimeout:   2 * p99,
+		TimeoutJitter: time.Duration(3) * time.Second,
+	}
+}
@@ -0,0 +1,52 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresets(t *testing.T) {
+	for _, st := range []Settings{
+		PresetAggressive("db"),
+		PresetConservative("db"),
+		PresetLatencySensitive("db", time.Duration(100)*time.Millisecond),
+	} {
+		assert.Equal(t, "db", st.Name)
+		assert.Nil(t, st.Validate())
+	}
+}
+
+func TestPresetAggressive(t *testing.T) {
+	cb := NewCircuitBreaker(PresetAggressive("db"))
+	for i := 0; i < 7; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestPresetLatencySensitive(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	st := PresetLatencySensitive("db", time.Duration(100)*time.Millisecond)
+	st.Clock = clock
+	cb := NewCircuitBreaker(st)
+
+	slow := func() (interface{}, error) {
+		clock.advance(time.Second)
+		return nil, nil
+	}
+	for i := 0; i < 19; i++ {
+		_, err := cb.Execute(slow)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	_, err := cb.Execute(slow)
+	assert.Nil(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+}
@@ -0,0 +1,86 @@
+package gobreaker
+
+import "fmt"
+
+// Priority is a type that represents the priority of a request, set by WithPriority or ExecuteWithPriority.
+// Settings.ShedThresholds decides how long the requests of each Priority keep access to the dependency.
+type Priority int
+
+// These constants are the common priorities of the requests. PriorityNormal is the priority of a request by default.
+// Any other value of Priority can be used as well.
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// String implements stringer interface.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("priority %d", p)
+	}
+}
+
+// WithPriority sets the priority of the request to p. See Settings.ShedThresholds.
+func WithPriority(p Priority) CallOption {
+	return func(o *callOptions) {
+		o.priority = p
+	}
+}
+
+// ExecuteWithPriority is like Execute but runs the request with the priority p. See Settings.ShedThresholds.
+func (cb *CircuitBreaker) ExecuteWithPriority(p Priority, req func() (interface{}, error)) (interface{}, error) {
+	return cb.execute(req, callOptions{priority: p})
+}
+
+// setShedThresholds copies thresholds, so that the map can be read by admitFast without the lock.
+func (cb *CircuitBreaker) setShedThresholds(thresholds map[Priority]float64) {
+	if len(thresholds) == 0 {
+		cb.shedThresholds = nil
+		return
+	}
+
+	cb.shedThresholds = make(map[Priority]float64, len(thresholds))
+	for p, threshold := range thresholds {
+		cb.shedThresholds[p] = threshold
+	}
+}
+
+// shedsPriority reports whether a request of the priority p is shed in state by Settings.ShedThresholds.
+func (cb *CircuitBreaker) shedsPriority(state State, p Priority) bool {
+	threshold, ok := cb.shedThresholds[p]
+	if !ok {
+		return false
+	}
+
+	switch state {
+	case StateHalfOpen:
+		return true
+	case StateClosed:
+		cb.foldShards()
+		counts := cb.counts.snapshot()
+		completed := counts.TotalSuccesses + counts.TotalFailures
+		if completed == 0 || completed < cb.minimumRequestThreshold {
+			return false
+		}
+		return float64(counts.TotalFailures)/float64(completed) >= threshold
+	default:
+		return false
+	}
+}
+
+func validShedThresholds(thresholds map[Priority]float64) bool {
+	for _, threshold := range thresholds {
+		if threshold < 0 || threshold > 1 {
+			return false
+		}
+	}
+	return true
+}
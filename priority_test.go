@@ -0,0 +1,47 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShedThresholds(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		CounterShards:  4,
+		ShedThresholds: map[Priority]float64{PriorityLow: 0.2, PriorityNormal: 0.5},
+	})
+	execute := func(p Priority, err error) error {
+		_, e := cb.ExecuteWithPriority(p, func() (interface{}, error) { return nil, err })
+		return e
+	}
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, execute(PriorityLow, nil))
+	}
+	assert.Equal(t, errNotFound, execute(PriorityHigh, errNotFound))
+	assert.True(t, errors.Is(execute(PriorityLow, nil), ErrThrottled))
+	assert.Nil(t, execute(PriorityNormal, nil))
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, errNotFound, execute(PriorityHigh, errNotFound))
+	}
+	assert.True(t, errors.Is(execute(PriorityNormal, nil), ErrThrottled))
+	assert.Nil(t, execute(PriorityHigh, nil))
+	assert.Equal(t, Counts{11, 6, 5, 1, 0}, cb.Counts())
+
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.True(t, errors.Is(execute(PriorityLow, nil), ErrThrottled))
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) { return nil, nil }, WithPriority(PriorityNormal))
+	assert.True(t, errors.Is(err, ErrThrottled))
+	assert.Nil(t, execute(PriorityHigh, nil))
+	assert.Equal(t, StateClosed, cb.State())
+
+	assert.Equal(t, "low", PriorityLow.String())
+	assert.Equal(t, "priority 5", Priority(5).String())
+	err = Settings{ShedThresholds: map[Priority]float64{PriorityLow: 1.5}}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
@@ -0,0 +1,177 @@
+package gobreaker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sink receives the snapshots of the CircuitBreakers pushed by a Reporter,
+// e.g. to statsd, to an HTTP endpoint or to a log, for the teams without a pull-based monitoring such as Prometheus.
+// Push is called with the labels of the Reporter, such as the service and the region, and must not keep them.
+type Sink interface {
+	Push(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error
+}
+
+// SinkFunc is a Sink of a function.
+type SinkFunc func(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error
+
+// Push calls f(ctx, snapshots, labels).
+func (f SinkFunc) Push(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error {
+	return f(ctx, snapshots, labels)
+}
+
+// ReporterConfig configures a Reporter.
+//
+// Interval is the period of the pushes. If Interval is less than or equal to 0, Interval is set to 10 seconds.
+// Labels are pushed with every snapshot.
+// OnError, if not nil, is called with the error of every failed push. The failed pushes are not retried.
+type ReporterConfig struct {
+	Interval time.Duration
+	Labels   map[string]string
+	OnError  func(err error)
+}
+
+const defaultReporterInterval = time.Duration(10) * time.Second
+
+// Reporter pushes the snapshots of CircuitBreakers to a Sink periodically.
+type Reporter struct {
+	sink     Sink
+	cfg      ReporterConfig
+	breakers func() []*CircuitBreaker
+}
+
+// NewReporter returns a new Reporter that pushes the snapshots of the given CircuitBreakers to sink.
+func NewReporter(sink Sink, cfg ReporterConfig, cbs ...*CircuitBreaker) *Reporter {
+	cbs = append([]*CircuitBreaker(nil), cbs...)
+	return newReporter(sink, cfg, func() []*CircuitBreaker { return cbs })
+}
+
+// NewGroupReporter returns a new Reporter that pushes the snapshots of all the CircuitBreakers in g to sink,
+// sorted by name, including the CircuitBreakers created after NewGroupReporter.
+func NewGroupReporter(g *Group, sink Sink, cfg ReporterConfig) *Reporter {
+	return newReporter(sink, cfg, func() []*CircuitBreaker {
+		names := g.Names()
+		cbs := make([]*CircuitBreaker, 0, len(names))
+		for _, name := range names {
+			if cb, ok := g.Lookup(name); ok {
+				cbs = append(cbs, cb)
+			}
+		}
+		return cbs
+	})
+}
+
+func newReporter(sink Sink, cfg ReporterConfig, breakers func() []*CircuitBreaker) *Reporter {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultReporterInterval
+	}
+	return &Reporter{sink: sink, cfg: cfg, breakers: breakers}
+}
+
+// Push pushes the current snapshots to the Sink once, and returns the error of the Sink.
+func (r *Reporter) Push(ctx context.Context) error {
+	cbs := r.breakers()
+	snapshots := make([]BreakerSnapshot, len(cbs))
+	for i, cb := range cbs {
+		snapshots[i] = cb.Snapshot()
+	}
+	return r.sink.Push(ctx, snapshots, r.cfg.Labels)
+}
+
+// Run pushes the snapshots every Interval until ctx is done, and returns the error of ctx.
+func (r *Reporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Push(ctx); err != nil && r.cfg.OnError != nil {
+				r.cfg.OnError(err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// LogSink returns a Sink that logs every snapshot with Infof of logger as a line of key=value pairs,
+// like the other lines logged by CircuitBreaker, followed by the labels sorted by key.
+func LogSink(logger Logger) Sink {
+	return SinkFunc(func(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error {
+		fields := labelFields(labels, "%s=%q")
+		for _, s := range snapshots {
+			logger.Infof("circuit breaker snapshot name=%q state=%s generation=%d disabled=%t %s%s",
+				s.Name, s.State, s.Generation, s.Disabled, countsFields(s.Counts), fields)
+		}
+		return nil
+	})
+}
+
+// StatsdSink is a Sink of the gauges of statsd sent over UDP to Addr, e.g. "127.0.0.1:8125".
+// Every snapshot is sent as the gauges named Prefix, the name of the CircuitBreaker and the field, joined by dots,
+// e.g. "gobreaker.db.state" for the state code, 0 for closed, 1 for half-open and 2 for open,
+// and "gobreaker.db.requests" for Counts.Requests.
+// If Prefix is empty, Prefix is set to "gobreaker". The labels are sent as the tags of DogStatsD.
+type StatsdSink struct {
+	Addr   string
+	Prefix string
+}
+
+// Push implements Sink.
+func (s StatsdSink) Push(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "gobreaker"
+	}
+	tags := labelFields(labels, "%s:%s")
+	if tags != "" {
+		tags = "|#" + strings.Replace(tags[1:], " ", ",", -1)
+	}
+
+	for _, snap := range snapshots {
+		var b strings.Builder
+		gauge := func(field string, value uint64) {
+			fmt.Fprintf(&b, "%s.%s.%s:%d|g%s\n", prefix, snap.Name, field, value, tags)
+		}
+		gauge("state", uint64(snap.State))
+		gauge("requests", uint64(snap.Counts.Requests))
+		gauge("total_successes", uint64(snap.Counts.TotalSuccesses))
+		gauge("total_failures", uint64(snap.Counts.TotalFailures))
+		gauge("consecutive_successes", uint64(snap.Counts.ConsecutiveSuccesses))
+		gauge("consecutive_failures", uint64(snap.Counts.ConsecutiveFailures))
+
+		// One datagram per CircuitBreaker keeps every packet well within the MTU.
+		if _, err := conn.Write([]byte(b.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelFields formats the labels sorted by key with format, each preceded by a space.
+func labelFields(labels map[string]string, format string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteByte(' ')
+		fmt.Fprintf(&b, format, k, labels[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporter(t *testing.T) {
+	pushed := make(chan []BreakerSnapshot, 10)
+	sink := SinkFunc(func(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error {
+		assert.Equal(t, map[string]string{"service": "api"}, labels)
+		pushed <- snapshots
+		return nil
+	})
+
+	db := NewCircuitBreaker(Settings{Name: "db"})
+	assert.Nil(t, fail(db))
+	r := NewReporter(sink, ReporterConfig{Labels: map[string]string{"service": "api"}}, db)
+	assert.Nil(t, r.Push(context.Background()))
+	snapshots := <-pushed
+	assert.Equal(t, 1, len(snapshots))
+	assert.Equal(t, "db", snapshots[0].Name)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, snapshots[0].Counts)
+
+	g := NewGroup(Settings{})
+	g.Get("b")
+	g.Get("a")
+	r = NewGroupReporter(g, sink, ReporterConfig{
+		Interval: time.Duration(10) * time.Millisecond,
+		Labels:   map[string]string{"service": "api"},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+
+	snapshots = <-pushed
+	assert.Equal(t, "a", snapshots[0].Name)
+	assert.Equal(t, "b", snapshots[1].Name)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func TestReporterOnError(t *testing.T) {
+	errPush := errors.New("push failed")
+	failed := make(chan error, 10)
+	sink := SinkFunc(func(ctx context.Context, snapshots []BreakerSnapshot, labels map[string]string) error {
+		return errPush
+	})
+	r := NewReporter(sink, ReporterConfig{
+		Interval: time.Duration(10) * time.Millisecond,
+		OnError:  func(err error) { failed <- err },
+	}, NewCircuitBreaker(Settings{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+	assert.Equal(t, errPush, <-failed)
+}
+
+func TestLogSink(t *testing.T) {
+	logger := &recordingLogger{}
+	cb := NewCircuitBreaker(Settings{Name: "db"})
+	assert.Nil(t, succeed(cb))
+
+	r := NewReporter(LogSink(logger), ReporterConfig{Labels: map[string]string{"zone": "b", "region": "a"}}, cb)
+	assert.Nil(t, r.Push(context.Background()))
+	assert.Equal(t, []string{
+		`INFO circuit breaker snapshot name="db" state=closed generation=1 disabled=false ` +
+			`requests=1 successes=1 failures=0 consecutive_successes=1 consecutive_failures=0 region="a" zone="b"`,
+	}, logger.lines)
+}
+
+func TestStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	cb := NewCircuitBreaker(Settings{Name: "db"})
+	cb.Trip()
+	sink := StatsdSink{Addr: conn.LocalAddr().String()}
+	assert.Nil(t, sink.Push(context.Background(), []BreakerSnapshot{cb.Snapshot()}, map[string]string{"env": "prod", "app": "api"}))
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buf[:n])), "\n")
+	assert.Equal(t, 6, len(lines))
+	assert.Equal(t, "gobreaker.db.state:2|g|#app:api,env:prod", lines[0])
+	assert.Equal(t, "gobreaker.db.requests:0|g|#app:api,env:prod", lines[1])
+}
@@ -0,0 +1,138 @@
+package gobreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// admit allows or rejects a request.
+// With Settings.HalfOpenWait, a request arriving while all the probes of the half-open state are in flight
+// waits for a probe to complete, until HalfOpenWait passes or the context of the request is done.
+func (cb *CircuitBreaker) admit(opts callOptions) (admission, error) {
+	cb.electProbe(opts)
+	adm, wait, err := cb.tryAdmit(opts, true)
+	if wait == nil {
+		return adm, err
+	}
+
+	timeout := make(chan struct{})
+	timer := cb.clock.AfterFunc(cb.halfOpenWaitFor(), func() { close(timeout) })
+	defer timer.Stop()
+
+	var done <-chan struct{}
+	if opts.ctx != nil {
+		done = opts.ctx.Done()
+	}
+
+	for wait != nil {
+		opts.ticket = wait
+		select {
+		case <-wait:
+			adm, wait, err = cb.tryAdmit(opts, true)
+		case <-timeout:
+			adm, wait, err = cb.tryAdmit(opts, false)
+		case <-done:
+			adm, wait, err = cb.tryAdmit(opts, false)
+		}
+	}
+	return adm, err
+}
+
+func (cb *CircuitBreaker) halfOpenWaitFor() time.Duration {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.halfOpenWait
+}
+
+// HalfOpenAdmission is a type that represents how the requests waiting by Settings.HalfOpenWait take the probes.
+type HalfOpenAdmission int
+
+// These constants are the strategies of HalfOpenAdmission.
+//
+// AdmissionFirstCome wakes up all the waiting requests when a probe completes,
+// and the first one to take the lock is allowed, so a request may starve under contention.
+// AdmissionFIFO gives the probes to the waiting requests in the order of their arrival,
+// and a new request doesn't overtake the waiting ones.
+const (
+	AdmissionFirstCome HalfOpenAdmission = iota
+	AdmissionFIFO
+)
+
+// String implements stringer interface.
+func (a HalfOpenAdmission) String() string {
+	switch a {
+	case AdmissionFirstCome:
+		return "first-come"
+	case AdmissionFIFO:
+		return "fifo"
+	default:
+		return fmt.Sprintf("unknown admission: %d", a)
+	}
+}
+
+// probeTicket is a request waiting in the queue of AdmissionFIFO.
+type probeTicket struct {
+	wait  chan struct{}
+	woken bool
+}
+
+// probeSignal returns the channel closed when the request holding ticket may try again:
+// when the next probe of the half-open state completes or the generation changes.
+// With AdmissionFIFO, a request without a ticket is queued, and only the head of the queue is woken up.
+func (cb *CircuitBreaker) probeSignal(ticket <-chan struct{}) <-chan struct{} {
+	if cb.halfOpenAdmission != AdmissionFIFO {
+		if cb.probeWait == nil {
+			cb.probeWait = make(chan struct{})
+		}
+		return cb.probeWait
+	}
+
+	wait := make(chan struct{})
+	for i := range cb.probeQueue {
+		if (<-chan struct{})(cb.probeQueue[i].wait) == ticket {
+			cb.probeQueue[i] = probeTicket{wait: wait}
+			return wait
+		}
+	}
+	cb.probeQueue = append(cb.probeQueue, probeTicket{wait: wait})
+	return wait
+}
+
+// queuedAhead reports whether another request is waiting ahead of the request holding ticket with AdmissionFIFO.
+func (cb *CircuitBreaker) queuedAhead(ticket <-chan struct{}) bool {
+	return len(cb.probeQueue) > 0 && (<-chan struct{})(cb.probeQueue[0].wait) != ticket
+}
+
+// dequeueProbe removes the request holding ticket from the queue of AdmissionFIFO after it is allowed or rejected.
+func (cb *CircuitBreaker) dequeueProbe(ticket <-chan struct{}) {
+	if ticket == nil {
+		return
+	}
+	for i := range cb.probeQueue {
+		if (<-chan struct{})(cb.probeQueue[i].wait) == ticket {
+			cb.probeQueue = append(cb.probeQueue[:i], cb.probeQueue[i+1:]...)
+			if i == 0 {
+				cb.wakeProbeQueue()
+			}
+			return
+		}
+	}
+}
+
+// wakeProbeQueue wakes up the head of the queue of AdmissionFIFO.
+func (cb *CircuitBreaker) wakeProbeQueue() {
+	if len(cb.probeQueue) > 0 && !cb.probeQueue[0].woken {
+		close(cb.probeQueue[0].wait)
+		cb.probeQueue[0].woken = true
+	}
+}
+
+// signalProbe wakes up the requests waiting for a probe.
+func (cb *CircuitBreaker) signalProbe() {
+	if cb.probeWait != nil {
+		close(cb.probeWait)
+		cb.probeWait = nil
+	}
+	cb.wakeProbeQueue()
+}
@@ -0,0 +1,86 @@
+package gobreaker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfOpenWait(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{HalfOpenWait: time.Second})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	probe := succeedLater(cb, time.Duration(50)*time.Millisecond)
+	time.Sleep(time.Duration(10) * time.Millisecond)
+
+	start := time.Now()
+	assert.Nil(t, succeed(cb))
+	assert.True(t, time.Since(start) >= time.Duration(30)*time.Millisecond)
+	assert.Nil(t, <-probe)
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestHalfOpenWaitTimeout(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{HalfOpenWait: time.Duration(10) * time.Millisecond})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+
+	probe := succeedLater(cb, time.Duration(100)*time.Millisecond)
+	time.Sleep(time.Duration(10) * time.Millisecond)
+
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(succeed(cb)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+	defer cancel()
+	cb.UpdateSettings(Settings{HalfOpenWait: time.Minute})
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) { return nil, nil })
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(err))
+	assert.Nil(t, <-probe)
+}
+
+func TestAdmissionFIFO(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		MaxRequests:           10,
+		HalfOpenMaxConcurrent: 1,
+		HalfOpenWait:          time.Second,
+		HalfOpenAdmission:     AdmissionFIFO,
+	})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	probe := succeedLater(cb, time.Duration(50)*time.Millisecond)
+	time.Sleep(time.Duration(10) * time.Millisecond)
+
+	var mutex sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cb.Execute(func() (interface{}, error) {
+				mutex.Lock()
+				order = append(order, i)
+				mutex.Unlock()
+				time.Sleep(time.Duration(5) * time.Millisecond)
+				return nil, nil
+			})
+			assert.Nil(t, err)
+		}()
+		time.Sleep(time.Duration(5) * time.Millisecond)
+	}
+
+	assert.Nil(t, <-probe)
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Empty(t, cb.probeQueue)
+	assert.Equal(t, "fifo", AdmissionFIFO.String())
+}
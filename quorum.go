@@ -0,0 +1,86 @@
+package gobreaker
+
+import "sync"
+
+// Quorum trips a parent CircuitBreaker for a replicated dependency
+// only when a quorum of the CircuitBreakers of its replicas are open,
+// so that a single unhealthy replica doesn't shut down the whole dependency.
+// The parent is reset when fewer than the quorum of the replicas are open again.
+//
+// Quorum watches the Events of the replicas on a goroutine per replica until Close is called.
+// A typical parent is the Settings.Parent of the replicas or a CircuitBreaker guarding the dependency as a whole.
+type Quorum struct {
+	parent   *CircuitBreaker
+	replicas []*CircuitBreaker
+	quorum   int
+	subs     []<-chan Event
+
+	mutex   sync.Mutex
+	tripped bool
+}
+
+// NewQuorum returns a new Quorum that trips parent when quorum or more of the replicas are open.
+// If quorum is less than or equal to 0, a majority of the replicas is the quorum.
+func NewQuorum(parent *CircuitBreaker, quorum int, replicas ...*CircuitBreaker) *Quorum {
+	if quorum <= 0 {
+		quorum = len(replicas)/2 + 1
+	}
+
+	q := &Quorum{
+		parent:   parent,
+		replicas: replicas,
+		quorum:   quorum,
+	}
+	for _, cb := range replicas {
+		ch := cb.Subscribe()
+		q.subs = append(q.subs, ch)
+		go q.watch(ch)
+	}
+	q.evaluate()
+	return q
+}
+
+func (q *Quorum) watch(ch <-chan Event) {
+	for ev := range ch {
+		if ev.Type == EventStateChange {
+			q.evaluate()
+		}
+	}
+}
+
+// evaluate trips or resets the parent according to the number of the open replicas.
+func (q *Quorum) evaluate() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	open := q.open()
+	if open >= q.quorum && !q.tripped {
+		q.tripped = true
+		q.parent.Trip()
+	} else if open < q.quorum && q.tripped {
+		q.tripped = false
+		q.parent.Reset()
+	}
+}
+
+func (q *Quorum) open() int {
+	var n int
+	for _, cb := range q.replicas {
+		if cb.State() == StateOpen {
+			n++
+		}
+	}
+	return n
+}
+
+// Open returns the number of the replicas whose CircuitBreakers are open.
+func (q *Quorum) Open() int {
+	return q.open()
+}
+
+// Close stops watching the replicas. The state of the parent is left as it is.
+func (q *Quorum) Close() {
+	for i, cb := range q.replicas {
+		cb.Unsubscribe(q.subs[i])
+	}
+}
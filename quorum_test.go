@@ -0,0 +1,35 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuorum(t *testing.T) {
+	parent := NewCircuitBreaker(Settings{Name: "dependency"})
+	replicas := []*CircuitBreaker{
+		NewCircuitBreaker(Settings{Name: "a"}),
+		NewCircuitBreaker(Settings{Name: "b"}),
+		NewCircuitBreaker(Settings{Name: "c"}),
+	}
+	q := NewQuorum(parent, 0, replicas...)
+	defer q.Close()
+	assert.Equal(t, 2, q.quorum)
+
+	replicas[0].Trip()
+	time.Sleep(time.Duration(10) * time.Millisecond)
+	assert.Equal(t, 1, q.Open())
+	assert.Equal(t, StateClosed, parent.State())
+
+	replicas[1].Trip()
+	time.Sleep(time.Duration(10) * time.Millisecond)
+	assert.Equal(t, 2, q.Open())
+	assert.Equal(t, StateOpen, parent.State())
+
+	replicas[0].Reset()
+	time.Sleep(time.Duration(10) * time.Millisecond)
+	assert.Equal(t, 1, q.Open())
+	assert.Equal(t, StateClosed, parent.State())
+}
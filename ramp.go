@@ -0,0 +1,94 @@
+package gobreaker
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RecoveryRamp ramps the traffic up gradually after the CircuitBreaker closes from the half-open state,
+// so that a barely recovered dependency is not hit by the full load at once. Set it to Settings.RecoveryRamp.
+//
+// Duration is the time of the ramp, divided evenly among the Steps.
+// If Duration is less than or equal to 0, Duration is set to 30 seconds.
+//
+// Steps are the fractions of the requests allowed in each step of the ramp, e.g. 0.1, 0.25, 0.5 and 1.
+// The other requests are rejected with ErrThrottled.
+// If Steps is empty, Steps is set to 0.1, 0.25, 0.5 and 1.
+type RecoveryRamp struct {
+	Duration time.Duration
+	Steps    []float64
+}
+
+const defaultRampDuration = time.Duration(30) * time.Second
+
+var defaultRampSteps = []float64{0.1, 0.25, 0.5, 1}
+
+func (r RecoveryRamp) withDefaults() RecoveryRamp {
+	if r.Duration <= 0 {
+		r.Duration = defaultRampDuration
+	}
+	if len(r.Steps) == 0 {
+		r.Steps = defaultRampSteps
+	} else {
+		r.Steps = append([]float64(nil), r.Steps...)
+	}
+	return r
+}
+
+func validRampSteps(steps []float64) bool {
+	for _, s := range steps {
+		if s <= 0 || s > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (cb *CircuitBreaker) setRecoveryRamp(r *RecoveryRamp) {
+	if r == nil {
+		cb.recoveryRamp = nil
+		cb.rampStart = time.Time{}
+		return
+	}
+	ramp := r.withDefaults()
+	cb.recoveryRamp = &ramp
+}
+
+// startRamp starts the RecoveryRamp on the transition from the state from to the state to.
+func (cb *CircuitBreaker) startRamp(from, to State, now time.Time) {
+	if cb.recoveryRamp != nil && from == StateHalfOpen && to == StateClosed {
+		cb.rampStart = now
+	} else {
+		cb.rampStart = time.Time{}
+	}
+}
+
+// rampThrottles reports whether a request in the closed state is rejected by the RecoveryRamp.
+// When the ramp is over, rampThrottles ends it and publishes the fastPath.
+func (cb *CircuitBreaker) rampThrottles(now time.Time) bool {
+	if cb.rampStart.IsZero() {
+		return false
+	}
+
+	ramp := cb.recoveryRamp
+	elapsed := now.Sub(cb.rampStart)
+	if elapsed >= ramp.Duration {
+		cb.rampStart = time.Time{}
+		cb.publishFastPath()
+		return false
+	}
+
+	step := int(elapsed * time.Duration(len(ramp.Steps)) / ramp.Duration)
+	return rand.Float64() >= ramp.Steps[step]
+}
+
+// Ramping reports whether the CircuitBreaker is ramping the traffic up by Settings.RecoveryRamp.
+func (cb *CircuitBreaker) Ramping() bool {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	state, _ := cb.currentState(now)
+	return state == StateClosed && !cb.rampStart.IsZero() && now.Sub(cb.rampStart) < cb.recoveryRamp.Duration
+}
@@ -0,0 +1,56 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryRamp(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	cb := NewCircuitBreaker(Settings{
+		Clock:         clock,
+		CounterShards: 4,
+		RecoveryRamp:  &RecoveryRamp{Duration: time.Duration(40) * time.Second},
+	})
+	allowed := func() int {
+		var n int
+		for i := 0; i < 1000; i++ {
+			if succeed(cb) == nil {
+				n++
+			}
+		}
+		return n
+	}
+
+	// no ramp without a recovery
+	assert.Equal(t, 1000, allowed())
+
+	cb.Trip()
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.True(t, cb.Ramping())
+	assert.Nil(t, cb.fast.Load().(*fastPath))
+
+	n := allowed()
+	assert.True(t, n > 50 && n < 150, n)
+	clock.advance(time.Duration(10) * time.Second)
+	n = allowed()
+	assert.True(t, n > 180 && n < 320, n)
+	clock.advance(time.Duration(10) * time.Second)
+	n = allowed()
+	assert.True(t, n > 400 && n < 600, n)
+	clock.advance(time.Duration(10) * time.Second)
+	assert.Equal(t, 1000, allowed())
+
+	clock.advance(time.Duration(10) * time.Second)
+	assert.Equal(t, 1000, allowed())
+	assert.False(t, cb.Ramping())
+	assert.NotNil(t, cb.fast.Load().(*fastPath))
+
+	err := Settings{RecoveryRamp: &RecoveryRamp{Steps: []float64{0, 1}}}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
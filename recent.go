@@ -0,0 +1,53 @@
+package gobreaker
+
+import "time"
+
+// ErrorRecord records an error returned from a request run by CircuitBreaker.
+// State is the state of the CircuitBreaker when the request was allowed.
+type ErrorRecord struct {
+	Time  time.Time
+	Err   error
+	State State
+}
+
+// recordError records the error returned from the request admitted with adm, if any.
+// recordError must be called with cb.mutex held.
+func (cb *CircuitBreaker) recordError(adm admission, now time.Time) {
+	if adm.err == nil || !adm.recordErrors {
+		return
+	}
+	cb.recentErrs.add(ErrorRecord{Time: now, Err: adm.err, State: adm.state})
+}
+
+// recordShadowedError records the error returned from the request shadowed by ShadowMode, if any.
+func (cb *CircuitBreaker) recordShadowedError(adm admission) {
+	if adm.err == nil || !adm.recordErrors {
+		return
+	}
+	cb.mutex.Lock()
+	cb.recordError(adm, cb.clock.Now())
+	cb.mutex.Unlock()
+}
+
+func (cb *CircuitBreaker) errorRecords() []ErrorRecord {
+	var records []ErrorRecord
+	for _, r := range cb.recentErrs.list() {
+		records = append(records, r.(ErrorRecord))
+	}
+	return records
+}
+
+// RecentErrors returns the latest errors returned from the requests, oldest first.
+// RecentErrors returns nil if Settings.RecentErrorsSize is 0.
+func (cb *CircuitBreaker) RecentErrors() []ErrorRecord {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.errorRecords()
+}
+
+// RecentErrors returns the latest errors of the TwoStepCircuitBreaker. See CircuitBreaker.RecentErrors.
+func (tscb *TwoStepCircuitBreaker) RecentErrors() []ErrorRecord {
+	return tscb.cb.RecentErrors()
+}
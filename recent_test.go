@@ -0,0 +1,70 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentErrors(t *testing.T) {
+	var tripped StateChangeEvent
+	cb := NewCircuitBreaker(Settings{
+		RecentErrorsSize: 3,
+		OnStateChangeDetailed: func(ev StateChangeEvent) {
+			if ev.To == StateOpen {
+				tripped = ev
+			}
+		},
+	})
+	assert.Equal(t, 0, len(cb.RecentErrors()))
+
+	assert.Nil(t, succeed(cb))
+	for i := 0; i < 4; i++ {
+		_, err := cb.Execute(func() (interface{}, error) { return nil, errors.New(string(rune('a' + i))) })
+		assert.NotNil(t, err)
+	}
+	assert.Nil(t, succeed(cb))
+
+	recent := cb.RecentErrors()
+	assert.Equal(t, 3, len(recent))
+	for i, r := range recent {
+		assert.Equal(t, string(rune('b'+i)), r.Err.Error())
+		assert.Equal(t, StateClosed, r.State)
+		assert.False(t, r.Time.IsZero())
+	}
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, 3, len(tripped.RecentErrors))
+	for _, r := range tripped.RecentErrors {
+		assert.Equal(t, "fail", r.Err.Error())
+	}
+
+	cb.UpdateSettings(Settings{RecentErrorsSize: 1})
+	recent = cb.RecentErrors()
+	assert.Equal(t, 1, len(recent))
+	assert.Equal(t, "fail", recent[0].Err.Error())
+
+	assert.Nil(t, NewCircuitBreaker(Settings{}).RecentErrors())
+}
+
+func TestRecentErrorsHighThroughput(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		Mode:             ModeHighThroughput,
+		RecentErrorsSize: 2,
+		IsSuccessful:     func(err error) bool { return err == errNotFound },
+	})
+
+	_, err := cb.Execute(func() (interface{}, error) { return nil, errNotFound })
+	assert.Equal(t, errNotFound, err)
+	assert.Nil(t, fail(cb))
+
+	recent := cb.RecentErrors()
+	assert.Equal(t, 2, len(recent))
+	assert.Equal(t, errNotFound, recent[0].Err)
+	assert.Equal(t, "fail", recent[1].Err.Error())
+	assert.Equal(t, uint32(2), cb.Counts().Requests)
+}
@@ -0,0 +1,177 @@
+package gobreaker
+
+import "time"
+
+// UpdateSettings reconfigures the CircuitBreaker with the given Settings at runtime
+// without losing its state, Counts and generation.
+// Settings.Name is ignored because the name of the CircuitBreaker never changes.
+//
+// The expiry of the current generation is recalculated from the start of the generation,
+// so a new Interval or Timeout also applies to the current closed or open period.
+// Settings.Schedule is evaluated at the start of the current generation as it is for every generation,
+// so the Thresholds it returns change only at the generation boundaries.
+func (cb *CircuitBreaker) UpdateSettings(st Settings) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	cb.currentState(now)
+
+	interval, timeout := cb.interval, cb.timeout
+	checking := cb.healthChecking()
+	cb.applySettings(st)
+	cb.reapplySchedule()
+	cb.foldShards()
+	cb.resetShards()
+	cb.rescheduleExpiry(interval, timeout)
+	cb.publishFastPath()
+	cb.logSettings()
+
+	if cb.state == StateOpen && !checking && cb.healthChecking() {
+		cb.startHealthCheck()
+	}
+}
+
+// Settings returns a copy of the effective Settings of the CircuitBreaker, e.g. for an admin endpoint,
+// reflecting UpdateSettings and the setters such as SetTimeout.
+// The fields defaulted by the CircuitBreaker, such as MaxRequests, Timeout and ReadyToTrip, hold the defaults,
+// and MaxRequests and ReadyToTrip are those of the current generation if Settings.Schedule overrides them.
+// Changing the returned Settings has no effect on the CircuitBreaker; use UpdateSettings for that.
+func (cb *CircuitBreaker) Settings() Settings {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+
+	st := cb.settings
+	st.Name = cb.name
+	st.Clock = cb.clock
+	st.Parent = cb.parent
+	st.MaxRequests = cb.maxRequests
+	st.Interval = cb.interval
+	st.Timeout = cb.timeout
+	st.ReadyToTrip = cb.readyToTrip
+	st.IsSuccessful = cb.isSuccessful
+	st.CounterShards = cb.counterShards
+	if st.Mode == ModeAdaptive {
+		st.AdaptiveK = cb.adaptiveK
+		st.AdaptiveWindow = cb.adaptive.size
+	}
+	if st.HealthCheck != nil {
+		st.HealthCheckInterval = cb.healthCheckInterval
+		st.HealthCheckSuccesses = cb.healthCheckSuccesses
+	}
+	st.CacheSize = cb.cacheSize
+	return st
+}
+
+// Settings returns a copy of the effective Settings of the TwoStepCircuitBreaker. See CircuitBreaker.Settings.
+func (tscb *TwoStepCircuitBreaker) Settings() Settings {
+	return tscb.cb.Settings()
+}
+
+// SetMaxRequests changes MaxRequests of the CircuitBreaker at runtime.
+// If n is 0, the CircuitBreaker allows only 1 request.
+func (cb *CircuitBreaker) SetMaxRequests(n uint32) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+
+	if n == 0 {
+		n = 1
+	}
+	cb.unscheduled.MaxRequests = n
+	cb.maxRequests = n
+	cb.reapplySchedule()
+	cb.logSettings()
+}
+
+// SetInterval changes Interval of the CircuitBreaker at runtime.
+// If d is less than or equal to 0, Settings.IntervalPolicy decides whether the CircuitBreaker clears internal Counts.
+func (cb *CircuitBreaker) SetInterval(d time.Duration) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+
+	interval := cb.interval
+	cb.interval = cb.intervalFor(d)
+	cb.rescheduleExpiry(interval, cb.timeout)
+	cb.publishFastPath()
+	cb.logSettings()
+}
+
+// SetTimeout changes Timeout of the CircuitBreaker at runtime.
+// If d is less than or equal to 0, the timeout value of the CircuitBreaker is set to 60 seconds.
+func (cb *CircuitBreaker) SetTimeout(d time.Duration) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+
+	timeout := cb.timeout
+	if d <= 0 {
+		cb.timeout = defaultTimeout
+	} else {
+		cb.timeout = d
+	}
+	cb.rescheduleExpiry(cb.interval, timeout)
+	cb.logSettings()
+}
+
+// SetReadyToTrip changes ReadyToTrip of the CircuitBreaker at runtime.
+// If readyToTrip is nil, default ReadyToTrip is used.
+func (cb *CircuitBreaker) SetReadyToTrip(readyToTrip func(counts Counts) bool) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+
+	if readyToTrip == nil {
+		readyToTrip = defaultReadyToTrip
+	}
+	cb.unscheduled.ReadyToTrip = readyToTrip
+	cb.readyToTrip = readyToTrip
+	cb.reapplySchedule()
+	cb.logSettings()
+}
+
+// reapplySchedule applies Settings.Schedule to the current generation after a reconfiguration,
+// evaluating it at the start of the generation as toNewGeneration does.
+func (cb *CircuitBreaker) reapplySchedule() {
+	cb.applySchedule(cb.generationStart)
+}
+
+// rescheduleExpiry moves the expiry of the current generation
+// from the previous interval or timeout to the current one.
+func (cb *CircuitBreaker) rescheduleExpiry(interval, timeout time.Duration) {
+	var zero time.Time
+	switch cb.state {
+	case StateClosed:
+		if cb.interval == interval {
+			return
+		}
+		if cb.interval == 0 {
+			cb.expiry = zero
+		} else if interval == 0 {
+			cb.expiry = cb.clock.Now().Add(cb.interval)
+		} else {
+			cb.expiry = cb.expiry.Add(cb.interval - interval)
+		}
+	case StateOpen:
+		cb.expiry = cb.expiry.Add(cb.timeout - timeout)
+	}
+}
+
+// UpdateSettings reconfigures the TwoStepCircuitBreaker with the given Settings at runtime.
+// See CircuitBreaker.UpdateSettings.
+func (tscb *TwoStepCircuitBreaker) UpdateSettings(st Settings) {
+	tscb.cb.UpdateSettings(st)
+}
@@ -0,0 +1,96 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSettings(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "update"})
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	generation := cb.generation
+
+	cb.UpdateSettings(Settings{
+		Name:        "ignored",
+		MaxRequests: 3,
+		Interval:    time.Duration(30) * time.Second,
+		Timeout:     time.Duration(10) * time.Second,
+		ReadyToTrip: func(counts Counts) bool { return counts.ConsecutiveFailures >= 6 },
+	})
+	assert.Equal(t, "update", cb.Name())
+	assert.Equal(t, uint32(3), cb.maxRequests)
+	assert.Equal(t, time.Duration(30)*time.Second, cb.interval)
+	assert.Equal(t, time.Duration(10)*time.Second, cb.timeout)
+	assert.Equal(t, generation, cb.generation)
+	assert.Equal(t, Counts{5, 0, 5, 0, 5}, cb.counts.snapshot())
+	assert.False(t, cb.expiry.IsZero())
+
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	// the new timeout applies to the current open period
+	cb.SetTimeout(time.Duration(20) * time.Second)
+	pseudoSleep(cb, time.Duration(19)*time.Second)
+	assert.Equal(t, StateOpen, cb.State())
+	pseudoSleep(cb, time.Duration(1)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	cb.SetMaxRequests(0)
+	assert.Equal(t, uint32(1), cb.maxRequests)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	cb.SetInterval(0)
+	assert.True(t, cb.expiry.IsZero())
+	cb.SetInterval(time.Duration(5) * time.Second)
+	assert.False(t, cb.expiry.IsZero())
+
+	cb.SetReadyToTrip(func(counts Counts) bool { return true })
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	cb.SetReadyToTrip(nil)
+	assert.NotNil(t, cb.readyToTrip)
+}
+
+func TestUpdateSettingsTwoStep(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+	tscb.UpdateSettings(Settings{MaxRequests: 4})
+	assert.Equal(t, uint32(4), tscb.cb.maxRequests)
+}
+
+func TestSettings(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "settings", CallTimeout: time.Second})
+
+	st := cb.Settings()
+	assert.Equal(t, "settings", st.Name)
+	assert.Equal(t, uint32(1), st.MaxRequests)
+	assert.Equal(t, defaultTimeout, st.Timeout)
+	assert.Equal(t, time.Second, st.CallTimeout)
+	assert.Equal(t, defaultCacheSize, st.CacheSize)
+	assert.NotNil(t, st.Clock)
+	assert.False(t, st.ReadyToTrip(Counts{ConsecutiveFailures: 5}))
+	assert.True(t, st.ReadyToTrip(Counts{ConsecutiveFailures: 6}))
+	assert.False(t, st.IsSuccessful(errNotFound))
+	assert.Nil(t, st.Validate())
+
+	cb.UpdateSettings(Settings{Name: "ignored", MaxRequests: 3})
+	cb.SetTimeout(time.Duration(20) * time.Second)
+	st = cb.Settings()
+	assert.Equal(t, "settings", st.Name)
+	assert.Equal(t, uint32(3), st.MaxRequests)
+	assert.Equal(t, time.Duration(20)*time.Second, st.Timeout)
+	assert.Equal(t, time.Duration(0), st.CallTimeout)
+
+	st.MaxRequests = 5
+	assert.Equal(t, uint32(3), cb.ReadOnly().Settings().MaxRequests)
+
+	st = NewCircuitBreaker(Settings{Mode: ModeAdaptive}).Settings()
+	assert.Equal(t, defaultAdaptiveK, st.AdaptiveK)
+	assert.Equal(t, defaultAdaptiveWindow, st.AdaptiveWindow)
+	assert.Nil(t, st.Validate())
+}
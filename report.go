@@ -0,0 +1,77 @@
+package gobreaker
+
+import "time"
+
+// Report records the outcomes of the requests aggregated by the caller, e.g. a batch consumer
+// that has processed 10k messages, under the lock only once instead of once per request.
+// The requests are counted as if they had been allowed by the CircuitBreaker.
+//
+// In the closed state, the successes are counted before the failures, so that a batch with failures
+// is never less likely to trip, and ReadyToTrip is evaluated once for the whole batch.
+// In the half-open state, the outcomes are counted one by one as probes until the state changes.
+// In the open state, the outcomes are ignored.
+func (cb *CircuitBreaker) Report(successes, failures uint32) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.report(successes, failures, cb.clock.Now())
+}
+
+func (cb *CircuitBreaker) report(successes, failures uint32, now time.Time) {
+	state, _ := cb.currentState(now)
+
+	if cb.disabled {
+		if cb.countWhileDisabled {
+			cb.counts.add(successes+failures, successes, failures)
+		}
+		return
+	}
+
+	switch state {
+	case StateClosed:
+		cb.counts.add(successes+failures, successes, failures)
+		if cb.burnRate != nil {
+			cb.burnRate.add(now, successes, failures)
+		}
+		cb.countDecayed(now, successes, failures)
+		if cb.adaptive != nil {
+			cb.adaptive.add(now, successes+failures, successes, 0)
+		}
+		if failures > 0 && cb.mode != ModeAdaptive && cb.tripReady(nil) {
+			cb.transition(StateOpen, now, CauseReadyToTrip)
+		}
+		cb.nextState(state, failures == 0, now)
+	case StateOpen:
+	default:
+		cb.reportEach(state, successes, true, now)
+		cb.reportEach(state, failures, false, now)
+	}
+}
+
+// recordWeight counts n more outcomes of a request with WithWeight.
+func (cb *CircuitBreaker) recordWeight(n uint32, success bool, now time.Time) {
+	if success {
+		cb.report(n, 0, now)
+	} else {
+		cb.report(0, n, now)
+	}
+}
+
+// reportEach counts n outcomes one by one while the CircuitBreaker stays in state.
+func (cb *CircuitBreaker) reportEach(state State, n uint32, success bool, now time.Time) {
+	for i := uint32(0); i < n && cb.state == state; i++ {
+		cb.counts.onRequest()
+		if success {
+			cb.onSuccess(state, now)
+		} else {
+			cb.onFailure(state, now, nil)
+		}
+	}
+}
+
+// Report records the outcomes of the requests aggregated by the caller.
+// See CircuitBreaker.Report.
+func (tscb *TwoStepCircuitBreaker) Report(successes, failures uint32) {
+	tscb.cb.Report(successes, failures)
+}
@@ -0,0 +1,60 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	cb.Report(10000, 0)
+	assert.Equal(t, Counts{10000, 10000, 0, 10000, 0}, cb.Counts())
+
+	cb.Report(100, 5)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{10105, 10100, 5, 0, 5}, cb.Counts())
+
+	cb.Report(0, 1)
+	assert.Equal(t, StateOpen, cb.State())
+
+	cb.Report(100, 0)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+}
+
+func TestReportHalfOpen(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{MaxRequests: 3})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	cb.Report(2, 0)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Equal(t, Counts{2, 2, 0, 2, 0}, cb.Counts())
+
+	cb.Report(5, 0)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	cb.Report(1, 1)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestReportTwoStep(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+	tscb.Report(3, 6)
+	assert.Equal(t, StateOpen, tscb.State())
+}
+
+func BenchmarkReport(b *testing.B) {
+	cb := NewCircuitBreaker(Settings{ReadyToTrip: func(counts Counts) bool { return false }})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cb.Report(10000, 0)
+		cb.Report(0, 1)
+	}
+}
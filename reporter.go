@@ -0,0 +1,84 @@
+package gobreaker
+
+// HealthReporter is notified of the health decisions of CircuitBreaker,
+// e.g. to mark a backend down in a service mesh or a load balancer API such as Consul or xDS.
+//
+// MarkDown is called when the CircuitBreaker trips into the open state,
+// and MarkUp is called when it recovers into the closed state after MarkDown.
+// Transitions between the open and the half-open states are not reported,
+// because the dependency is still considered down.
+//
+// The methods are called one at a time in the order of the transitions on a goroutine
+// dedicated to the CircuitBreaker, so they may block without delaying the requests
+// unless more than 16 notifications are pending.
+type HealthReporter interface {
+	MarkDown(ev StateChangeEvent)
+	MarkUp(ev StateChangeEvent)
+}
+
+// healthReporterQueueSize is the number of notifications that can be pending for a HealthReporter.
+const healthReporterQueueSize = 16
+
+type healthReporter struct {
+	reporter HealthReporter
+	queue    chan func()
+	down     bool
+}
+
+// setHealthReporter sets the HealthReporter of Settings.
+// An existing healthReporter is kept with its goroutine and its pending notifications, and only its HealthReporter is swapped,
+// so that UpdateSettings doesn't start a goroutine every time.
+func (cb *CircuitBreaker) setHealthReporter(reporter HealthReporter) {
+	if reporter == nil {
+		if cb.healthReporter != nil {
+			close(cb.healthReporter.queue)
+			cb.healthReporter = nil
+		}
+		return
+	}
+	if cb.healthReporter != nil {
+		cb.healthReporter.reporter = reporter
+		return
+	}
+	if cb.closed {
+		return
+	}
+
+	hr := &healthReporter{
+		reporter: reporter,
+		queue:    make(chan func(), healthReporterQueueSize),
+		down:     cb.state != StateClosed,
+	}
+	cb.startHealthReporter(hr)
+	cb.healthReporter = hr
+}
+
+// startHealthReporter runs hr in the background until its queue is closed.
+// It must be called with the lock held, so that Close waits for it.
+func (cb *CircuitBreaker) startHealthReporter(hr *healthReporter) {
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+		hr.run()
+	}()
+}
+
+func (hr *healthReporter) run() {
+	for notify := range hr.queue {
+		notify()
+	}
+}
+
+// onStateChange queues the notification of ev. It must be called with the lock held.
+// The HealthReporter is read at once, because setHealthReporter may swap it before the notification is delivered.
+func (hr *healthReporter) onStateChange(ev StateChangeEvent) {
+	reporter := hr.reporter
+	switch {
+	case ev.To == StateOpen && !hr.down:
+		hr.down = true
+		hr.queue <- func() { reporter.MarkDown(ev) }
+	case ev.To == StateClosed && hr.down:
+		hr.down = false
+		hr.queue <- func() { reporter.MarkUp(ev) }
+	}
+}
@@ -0,0 +1,62 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reporterFunc func(up bool, ev StateChangeEvent)
+
+func (f reporterFunc) MarkDown(ev StateChangeEvent) { f(false, ev) }
+func (f reporterFunc) MarkUp(ev StateChangeEvent)   { f(true, ev) }
+
+func TestHealthReporter(t *testing.T) {
+	type report struct {
+		up   bool
+		from State
+	}
+	ch := make(chan report, 10)
+	cb := NewCircuitBreaker(Settings{
+		HealthReporter: reporterFunc(func(up bool, ev StateChangeEvent) {
+			ch <- report{up, ev.From}
+		}),
+	})
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, report{false, StateClosed}, <-ch)
+
+	// open to half-open and back is not reported
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, report{true, StateHalfOpen}, <-ch)
+
+	cb.Trip()
+	assert.Equal(t, report{false, StateClosed}, <-ch)
+	cb.Reset()
+	assert.Equal(t, report{true, StateOpen}, <-ch)
+	assert.Equal(t, 0, len(ch))
+
+	cb.Trip()
+	assert.Equal(t, report{false, StateClosed}, <-ch)
+	hr := cb.healthReporter
+	swapped := make(chan report, 10)
+	cb.UpdateSettings(Settings{HealthReporter: reporterFunc(func(up bool, ev StateChangeEvent) {
+		swapped <- report{up, ev.From}
+	})})
+	assert.True(t, hr == cb.healthReporter) // the goroutine is kept
+	assert.True(t, cb.healthReporter.down)
+	cb.Reset()
+	assert.Equal(t, report{true, StateOpen}, <-swapped)
+	assert.Equal(t, 0, len(ch))
+
+	cb.UpdateSettings(Settings{})
+	assert.Nil(t, cb.healthReporter)
+}
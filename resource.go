@@ -0,0 +1,97 @@
+package gobreaker
+
+// Resource is a resource tied to the health of the dependency of a CircuitBreaker, such as a connection pool
+// of pgx or go-redis, attached to the CircuitBreaker by Attach.
+//
+// OnOpen is called when the CircuitBreaker trips into the open state,
+// e.g. to make the pool stop dialing and drop the idle connections to the dead backend,
+// and OnClose is called when the CircuitBreaker recovers into the closed state after OnOpen, e.g. to re-warm the pool.
+// Like the methods of HealthReporter, the transitions between the open and the half-open states are not notified,
+// and the methods are called one at a time in the order of the transitions on a goroutine dedicated to the Resource.
+type Resource interface {
+	OnOpen(ev StateChangeEvent)
+	OnClose(ev StateChangeEvent)
+}
+
+// ResourceFuncs is a Resource of the functions Open and Close. A nil function is not called.
+type ResourceFuncs struct {
+	Open  func(ev StateChangeEvent)
+	Close func(ev StateChangeEvent)
+}
+
+// OnOpen calls f.Open.
+func (f ResourceFuncs) OnOpen(ev StateChangeEvent) {
+	if f.Open != nil {
+		f.Open(ev)
+	}
+}
+
+// OnClose calls f.Close.
+func (f ResourceFuncs) OnClose(ev StateChangeEvent) {
+	if f.Close != nil {
+		f.Close(ev)
+	}
+}
+
+// resourceReporter notifies a Resource as a HealthReporter.
+type resourceReporter struct {
+	resource Resource
+}
+
+func (r resourceReporter) MarkDown(ev StateChangeEvent) { r.resource.OnOpen(ev) }
+func (r resourceReporter) MarkUp(ev StateChangeEvent)   { r.resource.OnClose(ev) }
+
+// Attach attaches r to the CircuitBreaker, and returns the function to detach it.
+// If the CircuitBreaker is not closed, r.OnOpen is called with the last transition at once,
+// so that r follows the CircuitBreaker from the start.
+// After Close, r is not attached and detach does nothing.
+func (cb *CircuitBreaker) Attach(r Resource) (detach func()) {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.closed {
+		return func() {}
+	}
+
+	hr := &healthReporter{
+		reporter: resourceReporter{resource: r},
+		queue:    make(chan func(), healthReporterQueueSize),
+	}
+	cb.startHealthReporter(hr)
+	cb.resources = append(cb.resources, hr)
+
+	state, _ := cb.currentState(cb.clock.Now())
+	if state != StateClosed {
+		t := cb.lastTransition
+		hr.onStateChange(StateChangeEvent{
+			Name:       cb.name,
+			From:       t.From,
+			To:         StateOpen,
+			Time:       t.Time,
+			Counts:     t.Counts,
+			Generation: cb.generation,
+			Cause:      t.Cause,
+		})
+	}
+
+	return func() { cb.detach(hr) }
+}
+
+func (cb *CircuitBreaker) detach(hr *healthReporter) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	for i, attached := range cb.resources {
+		if attached == hr {
+			cb.resources = append(cb.resources[:i], cb.resources[i+1:]...)
+			close(hr.queue)
+			return
+		}
+	}
+}
+
+// Attach attaches r to the TwoStepCircuitBreaker. See CircuitBreaker.Attach.
+func (tscb *TwoStepCircuitBreaker) Attach(r Resource) (detach func()) {
+	return tscb.cb.Attach(r)
+}
@@ -0,0 +1,40 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttach(t *testing.T) {
+	ch := make(chan string, 10)
+	pool := ResourceFuncs{
+		Open:  func(ev StateChangeEvent) { ch <- "open from " + ev.From.String() },
+		Close: func(ev StateChangeEvent) { ch <- "close from " + ev.From.String() },
+	}
+	cb := NewCircuitBreaker(Settings{})
+	detach := cb.Attach(pool)
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, "open from closed", <-ch)
+
+	late := cb.Attach(ResourceFuncs{Open: func(ev StateChangeEvent) { ch <- "late open from " + ev.From.String() }})
+	assert.Equal(t, "late open from closed", <-ch)
+	late()
+
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Nil(t, fail(cb))
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, "close from half-open", <-ch)
+
+	detach()
+	detach()
+	cb.Trip()
+	assert.Len(t, ch, 0)
+	assert.Empty(t, cb.resources)
+}
@@ -0,0 +1,78 @@
+package gobreaker
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy configures ExecuteWithRetry.
+//
+// MaxAttempts is the maximum number of the attempts including the first one.
+// If MaxAttempts is less than or equal to 0, MaxAttempts is set to 3.
+//
+// Backoff returns the delay before the n-th retry, where n starts at 1.
+// If Backoff is nil, ExponentialBackoff(100ms, 10s) is used.
+//
+// Retryable is called with the error of a failed attempt and decides whether the request is retried.
+// If Retryable is nil, every error is retried.
+// A request rejected by the CircuitBreaker or whose context is done is never retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     func(n int) time.Duration
+	Retryable   func(err error) bool
+}
+
+const defaultMaxAttempts = 3
+
+// ExponentialBackoff returns a Backoff of RetryPolicy that doubles the delay from base for every retry,
+// up to max.
+func ExponentialBackoff(base, max time.Duration) func(n int) time.Duration {
+	return func(n int) time.Duration {
+		d := base
+		for i := 1; i < n && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+var defaultBackoff = ExponentialBackoff(time.Duration(100)*time.Millisecond, time.Duration(10)*time.Second)
+
+// ExecuteWithRetry runs req with ExecuteContext and retries it as configured by policy.
+// Every attempt is allowed and counted by the CircuitBreaker separately,
+// so a failure hidden by a successful retry is still seen by ReadyToTrip.
+// ExecuteWithRetry stops retrying as soon as the CircuitBreaker rejects an attempt,
+// and returns the *RejectionError in that case.
+// Otherwise, ExecuteWithRetry returns the result of the last attempt,
+// or the error of ctx if ctx is done while waiting for the next attempt.
+func (cb *CircuitBreaker) ExecuteWithRetry(ctx context.Context, policy RetryPolicy, req func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+
+	for n := 1; ; n++ {
+		result, err := cb.ExecuteContext(ctx, req)
+		if err == nil || n >= maxAttempts || !cb.retryable(ctx, policy, err) {
+			return result, err
+		}
+
+		if err := cb.sleepContext(ctx, backoff(n)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (cb *CircuitBreaker) retryable(ctx context.Context, policy RetryPolicy, err error) bool {
+	if IsRejection(err) || ctx.Err() != nil {
+		return false
+	}
+	return policy.Retryable == nil || policy.Retryable(err)
+}
@@ -0,0 +1,113 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Duration(100)*time.Millisecond, time.Second)
+	assert.Equal(t, time.Duration(100)*time.Millisecond, backoff(1))
+	assert.Equal(t, time.Duration(200)*time.Millisecond, backoff(2))
+	assert.Equal(t, time.Duration(800)*time.Millisecond, backoff(4))
+	assert.Equal(t, time.Second, backoff(5))
+	assert.Equal(t, time.Second, backoff(100))
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		Backoff:     func(n int) time.Duration { return time.Millisecond },
+	}
+
+	// every attempt is counted
+	attempts := 0
+	result, err := cb.ExecuteWithRetry(context.Background(), policy, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("fail")
+		}
+		return "ok", nil
+	})
+	assert.Equal(t, "ok", result)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, Counts{3, 1, 2, 1, 0}, cb.Counts())
+
+	// no more than MaxAttempts
+	attempts = 0
+	_, err = cb.ExecuteWithRetry(context.Background(), policy, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		return nil, errors.New("fail")
+	})
+	assert.Equal(t, "fail", err.Error())
+	assert.Equal(t, 4, attempts)
+
+	// the errors not retryable are returned at once
+	attempts = 0
+	permanent := errors.New("permanent")
+	policy.Retryable = func(err error) bool { return err != permanent }
+	_, err = cb.ExecuteWithRetry(context.Background(), policy, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		return nil, permanent
+	})
+	assert.Equal(t, permanent, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, Counts{8, 1, 7, 0, 5}, cb.Counts())
+
+	// no retry after a rejection
+	cb.Trip()
+	attempts = 0
+	_, err = cb.ExecuteWithRetry(context.Background(), policy, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		return nil, nil
+	})
+	assert.True(t, errors.Is(err, ErrOpenState))
+	assert.Equal(t, 0, attempts)
+}
+
+func TestExecuteWithRetryRejectedError(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		Name: "custom",
+		RejectedError: func(name string, state State) error {
+			return fmt.Errorf("%s is unavailable: %w", name, ErrOpenState)
+		},
+	})
+	cb.Trip()
+
+	var backoffs int
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		Backoff: func(n int) time.Duration {
+			backoffs++
+			return time.Millisecond
+		},
+	}
+	_, err := cb.ExecuteWithRetry(context.Background(), policy, func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Equal(t, "custom is unavailable: circuit breaker is open", err.Error())
+	assert.True(t, IsRejection(err))
+	assert.Equal(t, 0, backoffs)
+}
+
+func TestExecuteWithRetryContext(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	policy := RetryPolicy{Backoff: func(n int) time.Duration { return time.Hour }}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	_, err := cb.ExecuteWithRetry(ctx, policy, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		return nil, errors.New("fail")
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, attempts)
+}
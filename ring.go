@@ -0,0 +1,47 @@
+package gobreaker
+
+// ring is a ring buffer keeping the latest items added to it.
+// A ring of the capacity 0 keeps nothing.
+type ring struct {
+	items []interface{}
+	next  int
+	full  bool
+}
+
+func (r *ring) cap() int {
+	return len(r.items)
+}
+
+func (r *ring) add(item interface{}) {
+	if len(r.items) == 0 {
+		return
+	}
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns the items, oldest first, or nil if the ring is empty.
+func (r *ring) list() []interface{} {
+	if !r.full {
+		return append([]interface{}(nil), r.items[:r.next]...)
+	}
+	return append(append([]interface{}(nil), r.items[r.next:]...), r.items[:r.next]...)
+}
+
+// resize changes the capacity of the ring, keeping the latest items.
+func (r *ring) resize(size int) {
+	if size == len(r.items) {
+		return
+	}
+	items := r.list()
+	if len(items) > size {
+		items = items[len(items)-size:]
+	}
+	*r = ring{items: make([]interface{}, size)}
+	for _, item := range items {
+		r.add(item)
+	}
+}
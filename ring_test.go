@@ -0,0 +1,30 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	var r ring
+	r.add(1)
+	assert.Nil(t, r.list())
+
+	r.resize(3)
+	assert.Equal(t, 3, r.cap())
+	assert.Nil(t, r.list())
+	for i := 1; i <= 4; i++ {
+		r.add(i)
+	}
+	assert.Equal(t, []interface{}{2, 3, 4}, r.list())
+
+	r.resize(2)
+	assert.Equal(t, []interface{}{3, 4}, r.list())
+	r.resize(4)
+	r.add(5)
+	assert.Equal(t, []interface{}{3, 4, 5}, r.list())
+
+	r.resize(0)
+	assert.Nil(t, r.list())
+}
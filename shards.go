@@ -0,0 +1,159 @@
+package gobreaker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// cacheLineSize is the assumed size of a CPU cache line, used to keep shards from false sharing.
+const cacheLineSize = 64
+
+type counterShard struct {
+	requests  uint32
+	successes uint32
+	failures  uint32
+	_         [cacheLineSize - 12]byte
+}
+
+// shardSet counts the requests and successes of a closed-state generation without the lock of CircuitBreaker.
+// A request adds its success to the shardSet of the generation it was allowed in,
+// so a new generation starts with a new shardSet and the late successes are dropped with the old one.
+// In ModeHighThroughput, the failures are counted as well, and dirty is set until they are folded.
+type shardSet struct {
+	shards []counterShard
+	dirty  uint32
+}
+
+func newShardSet(n int) *shardSet {
+	return &shardSet{shards: make([]counterShard, n)}
+}
+
+var (
+	shardIndexes   uint32
+	shardIndexPool = sync.Pool{
+		New: func() interface{} {
+			i := atomic.AddUint32(&shardIndexes, 1)
+			return &i
+		},
+	}
+)
+
+// shard picks a shard for the calling goroutine.
+// sync.Pool keeps its items per P, so goroutines running on different Ps mostly pick different shards.
+func (s *shardSet) shard() *counterShard {
+	i := shardIndexPool.Get().(*uint32)
+	shard := &s.shards[*i%uint32(len(s.shards))]
+	shardIndexPool.Put(i)
+	return shard
+}
+
+func (s *shardSet) onRequest() {
+	atomic.AddUint32(&s.shard().requests, 1)
+}
+
+func (s *shardSet) onSuccess() {
+	atomic.AddUint32(&s.shard().successes, 1)
+}
+
+func (s *shardSet) onFailure() {
+	atomic.AddUint32(&s.shard().failures, 1)
+	if atomic.LoadUint32(&s.dirty) == 0 {
+		atomic.StoreUint32(&s.dirty, 1)
+	}
+}
+
+// isDirty reports whether the shards have failures to be folded.
+func (s *shardSet) isDirty() bool {
+	return atomic.LoadUint32(&s.dirty) != 0
+}
+
+// drain returns and clears the requests, successes and failures counted by the shards.
+func (s *shardSet) drain() (requests uint32, successes uint32, failures uint32) {
+	atomic.StoreUint32(&s.dirty, 0)
+	for i := range s.shards {
+		requests += atomic.SwapUint32(&s.shards[i].requests, 0)
+		successes += atomic.SwapUint32(&s.shards[i].successes, 0)
+		failures += atomic.SwapUint32(&s.shards[i].failures, 0)
+	}
+	return requests, successes, failures
+}
+
+// foldShards adds the requests and outcomes counted by the shards to the internal Counts,
+// and returns the number of the failures folded.
+// The successes are assumed to have happened after all the outcomes already in Counts,
+// and the failures after the successes, so that a batch with failures is never less likely to trip.
+func (cb *CircuitBreaker) foldShards() uint32 {
+	if cb.shards == nil {
+		return 0
+	}
+
+	requests, successes, failures := cb.shards.drain()
+	if requests == 0 && successes == 0 && failures == 0 {
+		return 0
+	}
+
+	cb.counts.add(requests, successes, failures)
+	return failures
+}
+
+// resetShards replaces the shardSet for the current generation.
+func (cb *CircuitBreaker) resetShards() {
+	sharded := cb.mode == ModeStandard || cb.mode == ModeHighThroughput
+	if cb.state == StateClosed && cb.counterShards > 0 && sharded && !cb.disabled && cb.burnRate == nil && cb.decay == nil && cb.burst == nil {
+		cb.shards = newShardSet(cb.counterShards)
+	} else {
+		cb.shards = nil
+	}
+}
+
+// fastPath is published by CircuitBreaker while it is closed and sharded,
+// so that requests are allowed with an atomic load instead of the lock
+// until the generation expires or the CircuitBreaker has to evaluate its state.
+// A fastPath is never modified after it is published.
+type fastPath struct {
+	expiry             time.Time
+	minRemainingBudget time.Duration
+	shedThresholds     map[Priority]float64
+	adm                admission
+}
+
+// publishFastPath publishes the fastPath for the current generation and settings, if it can be used.
+func (cb *CircuitBreaker) publishFastPath() {
+	var fp *fastPath
+	if cb.shards != nil && cb.rampStart.IsZero() && cb.admissionPolicy == nil {
+		fp = &fastPath{
+			expiry:             cb.expiry,
+			minRemainingBudget: cb.minRemainingBudget,
+			shedThresholds:     cb.shedThresholds,
+			adm:                cb.newAdmission(cb.state, cb.generation),
+		}
+	}
+	cb.fast.Store(fp)
+}
+
+// admitFast allows a request without the lock if the fastPath is available and not expired.
+// A request after a failure counted without the lock takes the lock, so that ReadyToTrip is evaluated,
+// and so does a request without enough budget or of a priority in Settings.ShedThresholds, so that it may be rejected.
+func (cb *CircuitBreaker) admitFast(opts callOptions) (admission, bool) {
+	fp, _ := cb.fast.Load().(*fastPath)
+	if fp == nil || fp.adm.shards.isDirty() {
+		return admission{}, false
+	}
+
+	now := cb.clock.Now()
+	if !fp.expiry.IsZero() && fp.expiry.Before(now) {
+		return admission{}, false
+	}
+	if fp.minRemainingBudget > 0 && opts.lacksBudget(fp.minRemainingBudget, now) {
+		return admission{}, false
+	}
+	if _, ok := fp.shedThresholds[opts.priority]; ok {
+		return admission{}, false
+	}
+
+	fp.adm.shards.onRequest()
+	adm := fp.adm
+	adm.start = now
+	return adm, true
+}
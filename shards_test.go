@@ -0,0 +1,139 @@
+package gobreaker
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterShards(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{CounterShards: 4, Interval: time.Duration(10) * time.Second})
+	assert.NotNil(t, cb.shards)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				succeed(cb)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, Counts{8000, 8000, 0, 8000, 0}, cb.Counts())
+
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{8001, 8000, 1, 0, 1}, cb.Counts())
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{8002, 8001, 1, 1, 0}, cb.Counts())
+
+	// the successes from the previous generation are dropped
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+	done, err := tscb.Allow()
+	assert.Nil(t, err)
+	pseudoSleep(cb, time.Duration(10)*time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	done(true)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+	// the shards are used only in the closed state
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Nil(t, cb.shards)
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.NotNil(t, cb.shards)
+
+	cb.UpdateSettings(Settings{})
+	assert.Nil(t, cb.shards)
+}
+
+func BenchmarkExecuteParallel(b *testing.B) {
+	benchmarkExecuteParallel(b, Settings{})
+}
+
+func BenchmarkExecuteParallelSharded(b *testing.B) {
+	benchmarkExecuteParallel(b, Settings{CounterShards: 16})
+}
+
+func BenchmarkExecuteParallelHighThroughput(b *testing.B) {
+	benchmarkExecuteParallel(b, Settings{Mode: ModeHighThroughput})
+}
+
+func benchmarkExecuteParallel(b *testing.B, st Settings) {
+	cb := NewCircuitBreaker(st)
+	req := func() (interface{}, error) { return nil, nil }
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cb.Execute(req)
+		}
+	})
+}
+
+func TestFastPath(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{CounterShards: 2})
+
+	// successful requests in the closed state don't need the lock
+	cb.mutex.Lock()
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, succeed(cb))
+	cb.mutex.Unlock()
+	assert.Equal(t, Counts{2, 2, 0, 2, 0}, cb.Counts())
+
+	cb.Trip()
+	fp, _ := cb.fast.Load().(*fastPath)
+	assert.Nil(t, fp)
+	assert.Error(t, succeed(cb))
+
+	cb.Reset()
+	fp, _ = cb.fast.Load().(*fastPath)
+	assert.NotNil(t, fp)
+	assert.Equal(t, cb.generation, fp.adm.generation)
+
+	// the fast path is not used after the interval expires
+	cb.SetInterval(time.Duration(10) * time.Second)
+	fp, _ = cb.fast.Load().(*fastPath)
+	assert.Equal(t, cb.expiry, fp.expiry)
+	cb.fast.Store(&fastPath{expiry: time.Now().Add(-time.Second), adm: fp.adm})
+	_, ok := cb.admitFast(callOptions{})
+	assert.False(t, ok)
+}
+
+func TestHighThroughput(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Mode: ModeHighThroughput})
+	assert.Equal(t, runtime.GOMAXPROCS(0), cb.counterShards)
+	assert.NotNil(t, cb.shards)
+
+	// the failures in the closed state don't need the lock
+	cb.mutex.Lock()
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+	cb.mutex.Unlock()
+	assert.True(t, cb.shards.isDirty())
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, cb.Counts())
+	assert.False(t, cb.shards.isDirty())
+
+	// the next request after a failure takes the lock and trips the CircuitBreaker
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	_, ok := cb.admitFast(callOptions{})
+	assert.False(t, ok)
+	assert.Error(t, succeed(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Nil(t, cb.shards)
+
+	// the failures in the half-open state are counted under the lock
+	pseudoSleep(cb, time.Duration(60)*time.Second)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
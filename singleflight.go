@@ -0,0 +1,65 @@
+package gobreaker
+
+import "sync/atomic"
+
+// probeCall is a probe of the half-open state shared by the concurrent requests with the same key.
+// ok is false if the request panicked, in which case the waiting requests run by themselves.
+type probeCall struct {
+	key     string
+	done    chan struct{}
+	result  interface{}
+	verdict Verdict
+	err     error
+	ok      bool
+}
+
+// joinProbe returns the probeCall for the request with opts if Settings.ProbeKey deduplicates it,
+// and whether the request leads the probeCall, i.e. runs it for the other requests.
+func (cb *CircuitBreaker) joinProbe(opts callOptions) (*probeCall, bool) {
+	cb.lazyInit()
+	if atomic.LoadUint32(&cb.hasProbeKey) == 0 {
+		return nil, false
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	state, _ := cb.currentState(cb.clock.Now())
+	if state != StateHalfOpen || cb.probeKey == nil {
+		return nil, false
+	}
+	key := cb.probeKey(opts.metadata)
+	if key == "" {
+		return nil, false
+	}
+
+	if call, ok := cb.probeCalls[key]; ok {
+		return call, false
+	}
+	if cb.probeCalls == nil {
+		cb.probeCalls = make(map[string]*probeCall)
+	}
+	call := &probeCall{key: key, done: make(chan struct{})}
+	cb.probeCalls[key] = call
+	return call, true
+}
+
+// leadProbe runs req for all the requests sharing call.
+func (cb *CircuitBreaker) leadProbe(call *probeCall, req func() (interface{}, error), opts callOptions) (interface{}, Verdict, error) {
+	defer func() {
+		cb.mutex.Lock()
+		delete(cb.probeCalls, call.key)
+		cb.mutex.Unlock()
+		close(call.done)
+	}()
+
+	adm, err := cb.beforeRequest(opts)
+	if err != nil {
+		call.err, call.ok = err, true
+		return nil, Undecided, err
+	}
+
+	call.result, call.verdict, call.err = cb.runVerdict(adm, req)
+	call.ok = true
+	return call.result, call.verdict, call.err
+}
@@ -0,0 +1,90 @@
+package gobreaker
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeKey(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{ProbeKey: func(md Metadata) string { return md["endpoint"] }})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+
+	var calls int32
+	release := make(chan struct{})
+	req := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "ok", nil
+	}
+	md := WithMetadata(Metadata{"endpoint": "a"})
+
+	var wg sync.WaitGroup
+	results := make(chan interface{}, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := cb.ExecuteWithOptions(req, md)
+			assert.Nil(t, err)
+			results <- result
+		}()
+	}
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for result := range results {
+		assert.Equal(t, "ok", result)
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, 0, len(cb.probeCalls))
+}
+
+func TestProbeKeyNotShared(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{ProbeKey: func(md Metadata) string { return md["endpoint"] }})
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+
+	// a request without the key is an ordinary probe
+	_, err := cb.Execute(func() (interface{}, error) { return nil, errors.New("fail") })
+	assert.NotNil(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestProbeKeyPanic(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{MaxRequests: 2, ProbeKey: func(md Metadata) string { return "probe" }})
+	cb.Trip()
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		cb.Execute(func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("probe")
+		})
+	}()
+	<-started
+
+	done := make(chan error)
+	go func() { done <- succeed(cb) }()
+	time.Sleep(time.Duration(10) * time.Millisecond)
+	close(release)
+
+	// the waiting request runs by itself after the shared probe panics
+	assert.Equal(t, ErrOpenState, RejectionReason(<-done))
+}
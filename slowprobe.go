@@ -0,0 +1,31 @@
+package gobreaker
+
+import "time"
+
+// creditProbe credits a successful probe of the half-open state that took d by Settings.HalfOpenLatencyBudget,
+// and reports false if the probe is too slow to count as a success.
+func (cb *CircuitBreaker) creditProbe(d time.Duration) bool {
+	if cb.halfOpenLatencyBudget <= 0 {
+		return true
+	}
+
+	switch {
+	case d <= cb.halfOpenLatencyBudget:
+		cb.probeCredit++
+	case d <= 2*cb.halfOpenLatencyBudget:
+		cb.probeCredit += 0.5
+		cb.slowProbes++
+	default:
+		return false
+	}
+	return true
+}
+
+// probeSuccesses returns the successes of the probes of the current generation of the half-open state,
+// weighted by Settings.HalfOpenLatencyBudget.
+func (cb *CircuitBreaker) probeSuccesses() float64 {
+	if cb.halfOpenLatencyBudget <= 0 {
+		return float64(cb.counts.snapshot().ConsecutiveSuccesses)
+	}
+	return cb.probeCredit
+}
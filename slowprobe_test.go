@@ -0,0 +1,57 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfOpenLatencyBudget(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(Settings{
+		Clock:                 clock,
+		MaxRequests:           2,
+		HalfOpenLatencyBudget: time.Duration(100) * time.Millisecond,
+	})
+	probe := func(d time.Duration) error {
+		_, err := cb.Execute(func() (interface{}, error) {
+			clock.advance(d)
+			return nil, nil
+		})
+		return err
+	}
+	halfOpen := func() {
+		cb.Trip()
+		clock.advance(time.Duration(61) * time.Second)
+		assert.Equal(t, StateHalfOpen, cb.State())
+	}
+
+	// a slow probe counts as half a success and lets one more probe in
+	halfOpen()
+	assert.Nil(t, probe(time.Duration(50)*time.Millisecond))
+	assert.Nil(t, probe(time.Duration(150)*time.Millisecond))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, probe(time.Duration(50)*time.Millisecond))
+	assert.Equal(t, StateClosed, cb.State())
+
+	// all the probes are slow, so it takes twice as many
+	halfOpen()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, probe(time.Duration(150)*time.Millisecond))
+		assert.Equal(t, StateHalfOpen, cb.State())
+	}
+	assert.Nil(t, probe(time.Duration(150)*time.Millisecond))
+	assert.Equal(t, StateClosed, cb.State())
+
+	// a probe slower than twice the budget counts as a failure
+	halfOpen()
+	assert.Nil(t, probe(time.Duration(250)*time.Millisecond))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestHalfOpenLatencyBudgetValidate(t *testing.T) {
+	assert.Nil(t, Settings{HalfOpenLatencyBudget: time.Second}.Validate())
+	assert.NotNil(t, Settings{HalfOpenLatencyBudget: -time.Second}.Validate())
+	assert.NotNil(t, Settings{HalfOpenLatencyBudget: time.Second, HalfOpenSuccessRatio: 0.5}.Validate())
+}
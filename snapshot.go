@@ -0,0 +1,74 @@
+package gobreaker
+
+import "time"
+
+// BreakerSnapshot is the state of a CircuitBreaker captured by Snapshot,
+// e.g. to move a CircuitBreaker between processes, to serialize it for debugging,
+// or to construct a CircuitBreaker in a specific state for tests with RestoreCircuitBreaker.
+// Expiry is the zero time if the generation doesn't expire.
+type BreakerSnapshot struct {
+	Name       string
+	State      State
+	Counts     Counts
+	Generation uint64
+	Expiry     time.Time
+	Disabled   bool
+}
+
+// Snapshot returns the current BreakerSnapshot of the CircuitBreaker.
+func (cb *CircuitBreaker) Snapshot() BreakerSnapshot {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.snapshotLocked()
+}
+
+// snapshotLocked is Snapshot with the lock held.
+func (cb *CircuitBreaker) snapshotLocked() BreakerSnapshot {
+	cb.currentState(cb.clock.Now())
+	return BreakerSnapshot{
+		Name:       cb.name,
+		State:      cb.state,
+		Counts:     cb.counts.snapshot(),
+		Generation: cb.generation,
+		Expiry:     cb.expiry,
+		Disabled:   cb.disabled,
+	}
+}
+
+// RestoreCircuitBreaker returns a new CircuitBreaker configured with st and restored to s.
+// The name of the CircuitBreaker is s.Name, so st.Name is ignored.
+// No state change is reported for the restored state, and the hooks are called from the next state change.
+func RestoreCircuitBreaker(s BreakerSnapshot, st Settings) *CircuitBreaker {
+	st.Name = s.Name
+	cb := NewCircuitBreaker(st)
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.clock.Now()
+	cb.state = s.State
+	cb.generation = s.Generation
+	cb.generationState = s.State
+	cb.counts.store(s.Counts)
+	cb.expiry = s.Expiry
+	cb.disabled = s.Disabled
+	cb.lifetime.start(now)
+	cb.resetShards()
+	cb.publishFastPath()
+
+	if cb.state == StateOpen {
+		cb.downtime.openedAt = now
+		if cb.healthChecking() {
+			cb.startHealthCheck()
+		}
+	}
+	return cb
+}
+
+// Snapshot returns the current BreakerSnapshot of the TwoStepCircuitBreaker.
+// See CircuitBreaker.Snapshot.
+func (tscb *TwoStepCircuitBreaker) Snapshot() BreakerSnapshot {
+	return tscb.cb.Snapshot()
+}
@@ -0,0 +1,56 @@
+package gobreaker
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "snap", Interval: time.Duration(10) * time.Second})
+	assert.Nil(t, succeed(cb))
+	assert.Nil(t, fail(cb))
+
+	s := cb.Snapshot()
+	assert.Equal(t, "snap", s.Name)
+	assert.Equal(t, StateClosed, s.State)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, s.Counts)
+	assert.Equal(t, cb.generation, s.Generation)
+	assert.Equal(t, cb.expiry, s.Expiry)
+
+	data, err := json.Marshal(s)
+	assert.Nil(t, err)
+	var decoded BreakerSnapshot
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, s.Counts, decoded.Counts)
+	assert.True(t, s.Expiry.Equal(decoded.Expiry))
+
+	restored := RestoreCircuitBreaker(decoded, Settings{Name: "ignored", Interval: time.Duration(10) * time.Second})
+	assert.Equal(t, "snap", restored.Name())
+	assert.Equal(t, s.Counts, restored.Counts())
+	assert.Nil(t, fail(restored))
+	assert.Equal(t, Counts{3, 1, 2, 0, 2}, restored.Counts())
+}
+
+func TestRestoreOpen(t *testing.T) {
+	var changes []State
+	cb := RestoreCircuitBreaker(BreakerSnapshot{
+		Name:       "open",
+		State:      StateOpen,
+		Counts:     Counts{6, 0, 6, 0, 6},
+		Generation: 7,
+		Expiry:     time.Now().Add(time.Minute),
+	}, Settings{OnStateChange: func(name string, from, to State) { changes = append(changes, to) }})
+
+	assert.Equal(t, StateOpen, cb.State())
+	assert.True(t, errors.Is(succeed(cb), ErrOpenState))
+	assert.Nil(t, changes)
+
+	pseudoSleep(cb, time.Minute)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Equal(t, uint64(8), cb.Snapshot().Generation)
+	assert.Equal(t, []State{StateHalfOpen}, changes)
+}
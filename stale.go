@@ -0,0 +1,21 @@
+package gobreaker
+
+import "time"
+
+// recordStaleResult handles the outcome of a request allowed in a past generation.
+func (cb *CircuitBreaker) recordStaleResult(adm admission, state State, success bool, now time.Time) {
+	if cb.onStaleResult != nil {
+		cb.onStaleResult(cb.name, success)
+	}
+
+	if !cb.countStaleResults || state != StateClosed || cb.disabled {
+		return
+	}
+
+	cb.counts.onRequest()
+	if success {
+		cb.onSuccess(state, now)
+	} else {
+		cb.onFailure(state, now, adm.metadata)
+	}
+}
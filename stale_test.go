@@ -0,0 +1,48 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnStaleResult(t *testing.T) {
+	var stale []bool
+	cb := NewCircuitBreaker(Settings{
+		Interval:      time.Duration(10) * time.Second,
+		OnStaleResult: func(name string, success bool) { stale = append(stale, success) },
+	})
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+
+	done, err := tscb.Allow()
+	assert.Nil(t, err)
+	pseudoSleep(cb, time.Duration(11)*time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	done(false)
+	assert.Equal(t, []bool{false}, stale)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+}
+
+func TestCountStaleResults(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		Interval:          time.Duration(10) * time.Second,
+		CountStaleResults: true,
+	})
+	tscb := &TwoStepCircuitBreaker{cb: cb}
+
+	done, err := tscb.Allow()
+	assert.Nil(t, err)
+	pseudoSleep(cb, time.Duration(11)*time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+	done(false)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	// a stale result is not counted in the open state
+	done, err = tscb.Allow()
+	assert.Nil(t, err)
+	cb.Trip()
+	done(true)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+}
@@ -0,0 +1,51 @@
+package gobreaker
+
+import "time"
+
+// StateMachine extends the transitions of CircuitBreaker, so that advanced users can add their own states,
+// e.g. a degraded state between the closed and open states that sheds a part of the load.
+// Set it to Settings.StateMachine. The custom states are the States more than StateOpen.
+//
+// Allow is called for every request in a custom state, and reports whether the request is allowed.
+// A request not allowed is rejected with ErrThrottled.
+// The requests in the built-in states are allowed by CircuitBreaker itself.
+//
+// Next is called with the state and Counts whenever the outcome of a request is counted
+// and the built-in transitions haven't changed the state, and returns the next state.
+// If Next returns state, the state doesn't change.
+// Like the built-in states, every transition starts a new generation with the cleared Counts,
+// and a custom state lasts until Next or a manual operation such as Trip changes it.
+type StateMachine interface {
+	Allow(state State, counts Counts) bool
+	Next(state State, counts Counts, success bool) State
+}
+
+// DefaultStateMachine is the StateMachine with only the built-in transitions.
+type DefaultStateMachine struct{}
+
+// Allow allows every request.
+func (DefaultStateMachine) Allow(state State, counts Counts) bool {
+	return true
+}
+
+// Next keeps the current state.
+func (DefaultStateMachine) Next(state State, counts Counts, success bool) State {
+	return state
+}
+
+// isCustomState reports whether state is defined by a StateMachine.
+func isCustomState(state State) bool {
+	return state > StateOpen
+}
+
+// nextState asks the StateMachine for the transition after an outcome counted in state,
+// unless the built-in transitions have already changed the state.
+func (cb *CircuitBreaker) nextState(state State, success bool, now time.Time) {
+	if cb.stateMachine == nil || cb.state != state {
+		return
+	}
+
+	if next := cb.stateMachine.Next(state, cb.counts.snapshot(), success); next != state {
+		cb.transition(next, now, CauseStateMachine)
+	}
+}
@@ -0,0 +1,71 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const stateDegraded = StateOpen + 1
+
+// degradedStateMachine sheds every other request in stateDegraded, between the closed and open states.
+type degradedStateMachine struct {
+	requests int
+}
+
+func (m *degradedStateMachine) Allow(state State, counts Counts) bool {
+	m.requests++
+	return m.requests%2 == 0
+}
+
+func (m *degradedStateMachine) Next(state State, counts Counts, success bool) State {
+	switch {
+	case state == StateClosed && counts.ConsecutiveFailures >= 2:
+		return stateDegraded
+	case state == stateDegraded && counts.ConsecutiveSuccesses >= 2:
+		return StateClosed
+	case state == stateDegraded && counts.ConsecutiveFailures >= 2:
+		return StateOpen
+	default:
+		return state
+	}
+}
+
+func TestStateMachine(t *testing.T) {
+	var transitions []State
+	cb := NewCircuitBreaker(Settings{
+		StateMachine:  &degradedStateMachine{},
+		OnStateChange: func(name string, from, to State) { transitions = append(transitions, to) },
+	})
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, stateDegraded, cb.State())
+
+	assert.Equal(t, ErrThrottled, RejectionReason(succeed(cb)))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, ErrThrottled, RejectionReason(succeed(cb)))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, ErrThrottled, RejectionReason(fail(cb)))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, ErrThrottled, RejectionReason(fail(cb)))
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	assert.Equal(t, []State{stateDegraded, StateClosed, stateDegraded, StateOpen}, transitions)
+	assert.Equal(t, uint64(1), cb.Lifetime().Trips)
+}
+
+func TestDefaultStateMachine(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{StateMachine: DefaultStateMachine{}})
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
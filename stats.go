@@ -0,0 +1,175 @@
+package gobreaker
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Stats holds the timing statistics of the requests completed in the current generation of CircuitBreaker.
+// CircuitBreaker clears the internal Stats together with the internal Counts.
+//
+// Count is the number of the durations aggregated in Stats.
+// Min, Max and Mean are exact, while P50, P90 and P99 are estimated
+// from a histogram with power-of-two buckets and are within a factor of 2 of the true percentiles.
+//
+// PayloadCount, PayloadMin, PayloadMax and PayloadMean aggregate the metrics returned by Settings.PayloadMetric.
+//
+// Rejections is the number of the requests rejected in the current generation,
+// e.g. to see how much traffic has been shed. It is 0 unless Settings.CountRejections is true.
+//
+// FailureVelocity is the failures per second in the closed state over the last Settings.BurstWindow,
+// which is not limited to the current generation. It is 0 unless Settings.BurstWindow is more than 0.
+type Stats struct {
+	Count uint32
+	Min   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+
+	PayloadCount uint32
+	PayloadMin   float64
+	PayloadMax   float64
+	PayloadMean  float64
+
+	Rejections uint32
+
+	FailureVelocity float64
+}
+
+// latencyBuckets is the number of buckets of latencyStats.
+// Bucket i holds the durations d with bits.Len64(d) == i, i.e. in [2^(i-1), 2^i) nanoseconds.
+const latencyBuckets = 64
+
+// latencyStats aggregates the durations of the requests of a generation.
+type latencyStats struct {
+	count   uint32
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+	buckets [latencyBuckets]uint32
+
+	payloadCount uint32
+	payloadSum   float64
+	payloadMin   float64
+	payloadMax   float64
+
+	rejections uint32
+}
+
+func (s *latencyStats) add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	if s.count == 0 || d < s.min {
+		s.min = d
+	}
+	if d > s.max {
+		s.max = d
+	}
+	s.count++
+	s.sum += d
+
+	i := bits.Len64(uint64(d))
+	if i >= latencyBuckets {
+		i = latencyBuckets - 1
+	}
+	s.buckets[i]++
+}
+
+func (s *latencyStats) addPayload(v float64) {
+	if s.payloadCount == 0 || v < s.payloadMin {
+		s.payloadMin = v
+	}
+	if s.payloadCount == 0 || v > s.payloadMax {
+		s.payloadMax = v
+	}
+	s.payloadCount++
+	s.payloadSum += v
+}
+
+func (s *latencyStats) clear() {
+	*s = latencyStats{}
+}
+
+// percentile estimates the q-th quantile of the durations by interpolating within its bucket.
+func (s *latencyStats) percentile(q float64) time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+
+	rank := q * float64(s.count)
+	var seen float64
+	for i, n := range s.buckets {
+		if n == 0 {
+			continue
+		}
+		if seen+float64(n) < rank {
+			seen += float64(n)
+			continue
+		}
+
+		var lower, upper time.Duration
+		if i > 0 {
+			lower = time.Duration(1) << uint(i-1)
+			upper = time.Duration(1)<<uint(i) - 1
+		}
+		if lower < s.min {
+			lower = s.min
+		}
+		if upper > s.max {
+			upper = s.max
+		}
+		return lower + time.Duration(float64(upper-lower)*(rank-seen)/float64(n))
+	}
+	return s.max
+}
+
+func (s *latencyStats) snapshot() Stats {
+	var st Stats
+	if s.count > 0 {
+		st.Count = s.count
+		st.Min = s.min
+		st.Max = s.max
+		st.Mean = s.sum / time.Duration(s.count)
+		st.P50 = s.percentile(0.5)
+		st.P90 = s.percentile(0.9)
+		st.P99 = s.percentile(0.99)
+	}
+	if s.payloadCount > 0 {
+		st.PayloadCount = s.payloadCount
+		st.PayloadMin = s.payloadMin
+		st.PayloadMax = s.payloadMax
+		st.PayloadMean = s.payloadSum / float64(s.payloadCount)
+	}
+	st.Rejections = s.rejections
+	return st
+}
+
+// measurePayload records the metric of the payload of the request admitted with adm, if any.
+func (adm *admission) measurePayload(result interface{}, err error) {
+	if adm.payloadMetric != nil {
+		adm.payload, adm.hasPayload = adm.payloadMetric(result, err)
+	}
+}
+
+// Stats returns the timing statistics of the requests completed in the current generation.
+// The duration of a request is measured from the time it was allowed until its outcome was reported,
+// so it includes the time until the callback of TwoStepCircuitBreaker is called.
+// With Settings.CounterShards, the successes counted without the lock are not included.
+func (cb *CircuitBreaker) Stats() Stats {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+	return cb.snapshotStats()
+}
+
+// Stats returns the timing statistics of the requests completed in the current generation.
+// See CircuitBreaker.Stats.
+func (tscb *TwoStepCircuitBreaker) Stats() Stats {
+	return tscb.cb.Stats()
+}
@@ -0,0 +1,109 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyStats(t *testing.T) {
+	var s latencyStats
+	assert.Equal(t, Stats{}, s.snapshot())
+
+	for i := 1; i <= 100; i++ {
+		s.add(time.Duration(i) * time.Millisecond)
+	}
+	st := s.snapshot()
+	assert.Equal(t, uint32(100), st.Count)
+	assert.Equal(t, time.Millisecond, st.Min)
+	assert.Equal(t, 100*time.Millisecond, st.Max)
+	assert.Equal(t, time.Duration(50500)*time.Microsecond, st.Mean)
+	assert.True(t, st.P50 >= 25*time.Millisecond && st.P50 <= 100*time.Millisecond)
+	assert.True(t, st.P90 >= 45*time.Millisecond && st.P90 <= 100*time.Millisecond)
+	assert.True(t, st.P99 >= st.P90 && st.P99 <= st.Max)
+
+	s.clear()
+	s.add(-time.Second)
+	s.add(time.Second)
+	st = s.snapshot()
+	assert.Equal(t, time.Duration(0), st.Min)
+	assert.Equal(t, time.Second, st.Max)
+	assert.Equal(t, time.Duration(0), st.P50)
+	assert.True(t, st.P99 > time.Second/2 && st.P99 <= time.Second)
+}
+
+func TestReadyToTripWithStats(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		ReadyToTripWithStats: func(counts Counts, stats Stats) bool {
+			return stats.Max >= time.Duration(10)*time.Millisecond
+		},
+	})
+
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, uint32(1), cb.Stats().Count)
+	assert.Equal(t, StateClosed, cb.State())
+
+	_, err := cb.Execute(func() (interface{}, error) {
+		time.Sleep(time.Duration(10) * time.Millisecond)
+		return nil, errors.New("slow")
+	})
+	assert.Equal(t, "slow", err.Error())
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Stats{}, cb.Stats())
+}
+
+func TestPayloadMetric(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		PayloadMetric: func(result interface{}, err error) (float64, bool) {
+			body, ok := result.([]byte)
+			return float64(len(body)), ok
+		},
+		ReadyToTripWithStats: func(counts Counts, stats Stats) bool {
+			return stats.PayloadCount >= 3 && stats.PayloadMax == 0
+		},
+	})
+	respond := func(body []byte) {
+		_, err := cb.Execute(func() (interface{}, error) { return body, nil })
+		assert.Nil(t, err)
+	}
+
+	respond([]byte("abc"))
+	respond([]byte("a"))
+	assert.Nil(t, succeed(cb))
+	st := cb.Stats()
+	assert.Equal(t, uint32(3), st.Count)
+	assert.Equal(t, uint32(2), st.PayloadCount)
+	assert.Equal(t, 1.0, st.PayloadMin)
+	assert.Equal(t, 3.0, st.PayloadMax)
+	assert.Equal(t, 2.0, st.PayloadMean)
+
+	cb.Reset()
+	respond([]byte{})
+	respond([]byte{})
+	assert.Equal(t, StateClosed, cb.State())
+	respond([]byte{})
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestCountRejections(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{CountRejections: true, MaxRequests: 1})
+	cb.Trip()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, ErrOpenState, RejectionReason(succeed(cb)))
+	}
+	assert.Equal(t, uint32(3), cb.Stats().Rejections)
+
+	pseudoSleep(cb, time.Duration(61)*time.Second)
+	ch := succeedLater(cb, time.Duration(50)*time.Millisecond)
+	time.Sleep(time.Duration(10) * time.Millisecond)
+	assert.Equal(t, ErrTooManyRequests, RejectionReason(succeed(cb)))
+	assert.Equal(t, uint32(1), cb.Stats().Rejections)
+	assert.Nil(t, <-ch)
+
+	cb = NewCircuitBreaker(Settings{})
+	cb.Trip()
+	assert.NotNil(t, succeed(cb))
+	assert.Equal(t, uint32(0), cb.Stats().Rejections)
+}
@@ -0,0 +1,110 @@
+package gobreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// Stream is a long-lived request allowed by AllowStream, such as a streaming RPC or a websocket.
+// The caller reports the liveness of the stream by Heartbeat and its final outcome by Done.
+// If no heartbeat arrives for the idle timeout, the stream is counted as a failure,
+// and the channel returned by Idle is closed so that the caller can close the stream.
+// The outcome of a Stream is counted exactly once, by the first of Done and the idle timeout.
+type Stream struct {
+	cb   *CircuitBreaker
+	adm  admission
+	idle time.Duration
+
+	mutex    sync.Mutex
+	timer    Timer
+	beats    uint64
+	idleCh   chan struct{}
+	finished bool
+}
+
+// AllowStream is like Allow but returns a Stream for a long-lived request with the given idle timeout.
+// If idleTimeout is less than or equal to 0, the Stream never times out.
+func (tscb *TwoStepCircuitBreaker) AllowStream(idleTimeout time.Duration, opts ...CallOption) (*Stream, error) {
+	adm, err := tscb.cb.beforeRequest(newCallOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stream{
+		cb:     tscb.cb,
+		adm:    adm,
+		idle:   idleTimeout,
+		idleCh: make(chan struct{}),
+	}
+	s.mutex.Lock()
+	s.arm()
+	s.mutex.Unlock()
+	return s, nil
+}
+
+// arm restarts the idle timer.
+func (s *Stream) arm() {
+	if s.idle <= 0 {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.beats++
+	beats := s.beats
+	s.timer = s.cb.clock.AfterFunc(s.idle, func() { s.expire(beats) })
+}
+
+// expire times out the Stream unless a heartbeat has arrived since the timer for beats was started.
+func (s *Stream) expire(beats uint64) {
+	s.mutex.Lock()
+	stale := s.beats != beats
+	s.mutex.Unlock()
+	if stale {
+		return
+	}
+
+	if s.finish() {
+		close(s.idleCh)
+		s.cb.afterRequest(s.adm, false)
+	}
+}
+
+// finish marks the Stream finished and reports whether it was still running.
+func (s *Stream) finish() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.finished {
+		return false
+	}
+	s.finished = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	return true
+}
+
+// Heartbeat reports that the stream is alive and restarts the idle timeout.
+// Heartbeat does nothing after the Stream is finished.
+func (s *Stream) Heartbeat() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.finished {
+		s.arm()
+	}
+}
+
+// Done reports the final outcome of the stream.
+// Done does nothing if the Stream has already been finished by Done or the idle timeout.
+func (s *Stream) Done(success bool) {
+	if s.finish() {
+		s.cb.afterRequest(s.adm, success)
+	}
+}
+
+// Idle returns a channel closed when the Stream times out without heartbeats.
+func (s *Stream) Idle() <-chan struct{} {
+	return s.idleCh
+}
@@ -0,0 +1,43 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+
+	s, err := tscb.AllowStream(time.Duration(50) * time.Millisecond)
+	assert.Nil(t, err)
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Duration(20) * time.Millisecond)
+		s.Heartbeat()
+	}
+	assert.Equal(t, Counts{1, 0, 0, 0, 0}, tscb.Counts())
+	s.Done(true)
+	s.Done(false)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, tscb.Counts())
+
+	// the idle timeout counts as a failure
+	s, err = tscb.AllowStream(time.Duration(20) * time.Millisecond)
+	assert.Nil(t, err)
+	select {
+	case <-s.Idle():
+	case <-time.After(time.Second):
+		t.Fatal("the stream didn't time out")
+	}
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, tscb.Counts())
+	s.Heartbeat()
+	s.Done(true)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, tscb.Counts())
+
+	// no idle timeout
+	s, err = tscb.AllowStream(0)
+	assert.Nil(t, err)
+	time.Sleep(time.Duration(30) * time.Millisecond)
+	s.Done(false)
+	assert.Equal(t, Counts{3, 1, 2, 0, 2}, tscb.Counts())
+}
@@ -0,0 +1,41 @@
+package gobreaker
+
+// Ticket is a request allowed by TwoStepCircuitBreaker.Acquire.
+// Unlike the callback returned by Allow, a Ticket is a value, so acquiring and completing it doesn't allocate
+// on the hot path. Either Done or Cancel must be called exactly once.
+// The zero Ticket, which is returned for a request bypassing the CircuitBreaker, ignores Done and Cancel.
+type Ticket struct {
+	cb  *CircuitBreaker
+	adm admission
+}
+
+// Done records the outcome of the request.
+func (t Ticket) Done(success bool) {
+	if t.cb != nil {
+		t.cb.afterRequest(t.adm, success)
+	}
+}
+
+// Cancel forgets the request without counting a success or a failure, like OutcomeCanceled.
+func (t Ticket) Cancel() {
+	if t.cb != nil {
+		t.cb.releaseRequest(t.adm)
+	}
+}
+
+// Acquire is like AllowWithOptions but returns a Ticket instead of a callback.
+func (tscb *TwoStepCircuitBreaker) Acquire(opts ...CallOption) (Ticket, error) {
+	o := newCallOptions(opts)
+	if o.bypass {
+		return Ticket{}, nil
+	}
+	return tscb.acquire(o)
+}
+
+func (tscb *TwoStepCircuitBreaker) acquire(opts callOptions) (Ticket, error) {
+	adm, err := tscb.cb.beforeRequest(opts)
+	if err != nil {
+		return Ticket{}, err
+	}
+	return Ticket{cb: tscb.cb, adm: adm}, nil
+}
@@ -0,0 +1,71 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquire(t *testing.T) {
+	cb := NewTwoStepCircuitBreaker(Settings{Timeout: time.Duration(61) * time.Second})
+
+	ticket, err := cb.Acquire()
+	assert.Nil(t, err)
+	ticket.Done(true)
+	ticket, err = cb.Acquire()
+	assert.Nil(t, err)
+	ticket.Cancel()
+	assert.Equal(t, Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}, cb.Counts())
+
+	ticket, err = cb.Acquire(WithBypass())
+	assert.Nil(t, err)
+	ticket.Done(false)
+	assert.Equal(t, Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}, cb.Counts())
+
+	cb.cb.Trip()
+	_, err = cb.Acquire()
+	assert.True(t, errors.Is(err, ErrOpenState))
+}
+
+func TestZeroAllocations(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+	req := func() (interface{}, error) { return nil, nil }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		_, _ = cb.Execute(req)
+	}))
+
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		ticket, _ := tscb.Acquire()
+		ticket.Done(true)
+	}))
+}
+
+func BenchmarkExecute(b *testing.B) {
+	cb := NewCircuitBreaker(Settings{})
+	req := func() (interface{}, error) { return nil, nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = cb.Execute(req)
+	}
+}
+
+func BenchmarkAllow(b *testing.B) {
+	cb := NewTwoStepCircuitBreaker(Settings{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		done, _ := cb.Allow()
+		done(true)
+	}
+}
+
+func BenchmarkAcquire(b *testing.B) {
+	cb := NewTwoStepCircuitBreaker(Settings{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ticket, _ := cb.Acquire()
+		ticket.Done(true)
+	}
+}
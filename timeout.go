@@ -0,0 +1,52 @@
+package gobreaker
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCallTimeout is returned when a request doesn't return within the timeout enforced by
+// Settings.EnforceCallTimeout or WithEnforcedTimeout.
+var ErrCallTimeout = errors.New("call timed out")
+
+// WithEnforcedTimeout makes the CircuitBreaker enforce the timeout d on the request,
+// like Settings.EnforceCallTimeout with Settings.CallTimeout of d.
+func WithEnforcedTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.enforcedTimeout = d
+	}
+}
+
+// enforcedCallTimeout returns the timeout enforced on the requests by Settings.EnforceCallTimeout, or 0.
+func (cb *CircuitBreaker) enforcedCallTimeout() time.Duration {
+	if !cb.enforceCallTimeout {
+		return 0
+	}
+	return cb.callTimeout
+}
+
+// runWithTimeout runs req in a new goroutine and waits for it until adm.enforcedTimeout.
+// If req doesn't return in time, its outcome is recorded as ErrCallTimeout at once,
+// and its result is discarded when it eventually returns.
+func (cb *CircuitBreaker) runWithTimeout(adm admission, req func() (interface{}, error)) (interface{}, Verdict, error) {
+	ch := make(chan Result, 1)
+	go func() {
+		value, err := req()
+		ch <- Result{Value: value, Err: err}
+	}()
+
+	expired := make(chan struct{})
+	timer := cb.clock.AfterFunc(adm.enforcedTimeout, func() { close(expired) })
+	defer timer.Stop()
+
+	select {
+	case r := <-ch:
+		adm.measurePayload(r.Value, r.Err)
+		adm.result = r.Value
+		v := cb.afterRequestWithError(adm, r.Err)
+		return r.Value, v, cb.wrapError(adm, r.Err)
+	case <-expired:
+		v := cb.afterRequestWithError(adm, ErrCallTimeout)
+		return nil, v, cb.wrapError(adm, ErrCallTimeout)
+	}
+}
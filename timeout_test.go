@@ -0,0 +1,65 @@
+package gobreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnforceCallTimeout(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		CallTimeout:        time.Duration(20) * time.Millisecond,
+		EnforceCallTimeout: true,
+	})
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	value, err := cb.Execute(func() (interface{}, error) {
+		defer close(returned)
+		<-release
+		return "late", nil
+	})
+	assert.Nil(t, value)
+	assert.Equal(t, ErrCallTimeout, err)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	close(release)
+	<-returned
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	value, err = cb.Execute(func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, "ok", value)
+	assert.Nil(t, err)
+	assert.Equal(t, Counts{2, 1, 1, 1, 0}, cb.Counts())
+}
+
+func TestEnforceCallTimeoutContext(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{
+		CallTimeout:        time.Minute,
+		EnforceCallTimeout: true,
+	})
+
+	ctx := WithCallTimeout(context.Background(), time.Duration(20)*time.Millisecond)
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		time.Sleep(time.Duration(200) * time.Millisecond)
+		return nil, nil
+	})
+	assert.Equal(t, ErrCallTimeout, err)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+}
+
+func TestWithEnforcedTimeout(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{})
+
+	_, err := cb.ExecuteWithOptions(func() (interface{}, error) {
+		time.Sleep(time.Duration(200) * time.Millisecond)
+		return nil, nil
+	}, WithEnforcedTimeout(time.Duration(20)*time.Millisecond))
+	assert.Equal(t, ErrCallTimeout, err)
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, cb.Counts())
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{2, 1, 1, 1, 0}, cb.Counts())
+}
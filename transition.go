@@ -0,0 +1,87 @@
+package gobreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransitionCause is a type that represents why the state of CircuitBreaker has changed.
+type TransitionCause int
+
+// These constants are causes of the transitions of CircuitBreaker.
+//
+// CauseNone means the state has never changed.
+// CauseReadyToTrip is a trip in the closed state by ReadyToTrip or the other trip policies.
+// CauseTimeout is the transition from the open state to the half-open state after Timeout.
+// CauseHalfOpenFailure is the transition back to the open state because the probes have failed.
+// CauseHalfOpenSuccess is the transition to the closed state because the probes have succeeded.
+// CauseCanary is the transition to the half-open state by a successful canary of FailOpenRatio.
+// CauseHealthCheck is the transition to the closed state by HealthCheck.
+// CauseManual is a transition by Trip, Reset, Disable or Enable.
+// CauseStateMachine is a transition by StateMachine.
+const (
+	CauseNone TransitionCause = iota
+	CauseReadyToTrip
+	CauseTimeout
+	CauseHalfOpenFailure
+	CauseHalfOpenSuccess
+	CauseCanary
+	CauseHealthCheck
+	CauseManual
+	CauseStateMachine
+)
+
+// String implements stringer interface.
+func (c TransitionCause) String() string {
+	switch c {
+	case CauseNone:
+		return "none"
+	case CauseReadyToTrip:
+		return "ready-to-trip"
+	case CauseTimeout:
+		return "timeout"
+	case CauseHalfOpenFailure:
+		return "half-open-failure"
+	case CauseHalfOpenSuccess:
+		return "half-open-success"
+	case CauseCanary:
+		return "canary"
+	case CauseHealthCheck:
+		return "health-check"
+	case CauseManual:
+		return "manual"
+	case CauseStateMachine:
+		return "state-machine"
+	default:
+		return fmt.Sprintf("unknown cause: %d", c)
+	}
+}
+
+// Transition describes the last transition of CircuitBreaker, e.g. for a dashboard to show
+// "open because of 12 consecutive failures at 14:02:11".
+// Counts is the snapshot of the Counts that led to the transition, just before they were cleared.
+type Transition struct {
+	From   State
+	To     State
+	Cause  TransitionCause
+	Time   time.Time
+	Counts Counts
+}
+
+// LastTransition returns the last transition of the CircuitBreaker,
+// which explains why the CircuitBreaker is in the current state.
+// The Cause of LastTransition is CauseNone if the state has never changed.
+func (cb *CircuitBreaker) LastTransition() Transition {
+	cb.lazyInit()
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.currentState(cb.clock.Now())
+	return cb.lastTransition
+}
+
+// LastTransition returns the last transition of the TwoStepCircuitBreaker.
+// See CircuitBreaker.LastTransition.
+func (tscb *TwoStepCircuitBreaker) LastTransition() Transition {
+	return tscb.cb.LastTransition()
+}
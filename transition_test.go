@@ -0,0 +1,44 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastTransition(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	var events []StateChangeEvent
+	cb := NewCircuitBreaker(Settings{
+		Clock:                 clock,
+		OnStateChangeDetailed: func(ev StateChangeEvent) { events = append(events, ev) },
+	})
+	assert.Equal(t, CauseNone, cb.LastTransition().Cause)
+
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	tr := cb.LastTransition()
+	assert.Equal(t, StateClosed, tr.From)
+	assert.Equal(t, StateOpen, tr.To)
+	assert.Equal(t, CauseReadyToTrip, tr.Cause)
+	assert.Equal(t, clock.now, tr.Time)
+	assert.Equal(t, Counts{6, 0, 6, 0, 6}, tr.Counts)
+	assert.Equal(t, CauseReadyToTrip, events[0].Cause)
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Equal(t, CauseTimeout, cb.LastTransition().Cause)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, CauseHalfOpenFailure, cb.LastTransition().Cause)
+
+	clock.advance(time.Duration(61) * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Transition{From: StateHalfOpen, To: StateClosed, Cause: CauseHalfOpenSuccess, Time: clock.now, Counts: Counts{1, 1, 0, 1, 0}},
+		cb.LastTransition())
+
+	cb.Trip()
+	assert.Equal(t, CauseManual, cb.LastTransition().Cause)
+	assert.Equal(t, "manual", CauseManual.String())
+	assert.Equal(t, "unknown cause: 42", TransitionCause(42).String())
+}
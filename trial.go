@@ -0,0 +1,33 @@
+package gobreaker
+
+import "time"
+
+// defaultTrialSuccessRatio is the success ratio to close the CircuitBreaker at the end of the trial period
+// of HalfOpenDuration if HalfOpenSuccessRatio is 0.
+const defaultTrialSuccessRatio = 0.5
+
+// trialing reports whether the half-open state is a trial period of HalfOpenDuration.
+func (cb *CircuitBreaker) trialing() bool {
+	return cb.halfOpenDuration > 0
+}
+
+// endTrial closes or reopens the CircuitBreaker by the success ratio at the end of the trial period,
+// or starts another trial period if no request has completed in it.
+func (cb *CircuitBreaker) endTrial(now time.Time) {
+	counts := cb.counts.snapshot()
+	completed := counts.TotalSuccesses + counts.TotalFailures
+	if completed == 0 {
+		cb.toNewGeneration(now)
+		return
+	}
+
+	ratio := cb.halfOpenSuccessRatio
+	if ratio <= 0 {
+		ratio = defaultTrialSuccessRatio
+	}
+	if float64(counts.TotalSuccesses)/float64(completed) >= ratio {
+		cb.transition(StateClosed, now, CauseHalfOpenSuccess)
+	} else {
+		cb.transition(StateOpen, now, CauseHalfOpenFailure)
+	}
+}
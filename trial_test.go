@@ -0,0 +1,52 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfOpenDuration(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(Settings{Clock: clock, HalfOpenDuration: time.Duration(10) * time.Second})
+	trial := func() {
+		cb.Trip()
+		clock.advance(time.Duration(61) * time.Second)
+		assert.Equal(t, StateHalfOpen, cb.State())
+	}
+
+	trial()
+	assert.Nil(t, fail(cb))
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	_, err := cb.Execute(func() (interface{}, error) {
+		_, err := cb.Execute(func() (interface{}, error) { return nil, nil })
+		return nil, err
+	})
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, StateHalfOpen, cb.State())
+	clock.advance(time.Duration(11) * time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, CauseHalfOpenSuccess, cb.LastTransition().Cause)
+
+	trial()
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, fail(cb))
+	assert.Nil(t, succeed(cb))
+	clock.advance(time.Duration(11) * time.Second)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, CauseHalfOpenFailure, cb.LastTransition().Cause)
+
+	trial()
+	clock.advance(time.Duration(11) * time.Second)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+	clock.advance(time.Duration(11) * time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+
+	err = Settings{HalfOpenDuration: time.Second, ReadyToClose: func(Counts) bool { return true }}.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
@@ -0,0 +1,95 @@
+package gobreaker
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Transaction is the lifecycle of a transaction, such as *sql.Tx.
+type Transaction interface {
+	Commit() error
+	Rollback() error
+}
+
+// Tx is a Transaction allowed by a TwoStepCircuitBreaker.
+// The outcome of the transaction is reported to the TwoStepCircuitBreaker exactly once,
+// by the first call of Commit, Rollback or RollbackCause.
+//
+// The errors are classified by Settings.IsSuccessful, except for sql.ErrTxDone,
+// which means the transaction has already been completed and is always counted as a success.
+type Tx struct {
+	Transaction
+
+	cb   *CircuitBreaker
+	adm  admission
+	once sync.Once
+}
+
+// BeginTx starts a transaction with begin if the TwoStepCircuitBreaker allows it.
+// BeginTx returns a *RejectionError without calling begin if the TwoStepCircuitBreaker rejects the transaction.
+// If begin returns an error, the error is reported to the TwoStepCircuitBreaker and returned.
+//
+// For example, with database/sql:
+//
+//	tx, err := tscb.BeginTx(func() (gobreaker.Transaction, error) { return db.BeginTx(ctx, nil) })
+//	if err != nil {
+//		return err
+//	}
+//	defer tx.Rollback()
+//	...
+//	return tx.Commit()
+func (tscb *TwoStepCircuitBreaker) BeginTx(begin func() (Transaction, error)) (*Tx, error) {
+	adm, err := tscb.cb.beforeRequest(callOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := begin()
+	if err != nil {
+		tscb.cb.afterRequestWithError(adm, err)
+		return nil, err
+	}
+
+	return &Tx{Transaction: t, cb: tscb.cb, adm: adm}, nil
+}
+
+// Commit commits the transaction and reports the commit-time error, if any, as the outcome.
+func (tx *Tx) Commit() error {
+	err := tx.Transaction.Commit()
+	tx.report(err)
+	return err
+}
+
+// Rollback rolls back the transaction.
+// A rollback requested by the application is counted as a success
+// unless the rollback itself fails.
+func (tx *Tx) Rollback() error {
+	err := tx.Transaction.Rollback()
+	tx.report(err)
+	return err
+}
+
+// RollbackCause rolls back the transaction because of cause, e.g. the error of a statement,
+// and reports cause as the outcome, so that an infrastructure error fails the transaction
+// while an application error accepted by Settings.IsSuccessful doesn't.
+// If cause is nil, RollbackCause is the same as Rollback.
+func (tx *Tx) RollbackCause(cause error) error {
+	err := tx.Transaction.Rollback()
+	if cause != nil {
+		tx.report(cause)
+	} else {
+		tx.report(err)
+	}
+	return err
+}
+
+func (tx *Tx) report(err error) {
+	if errors.Is(err, sql.ErrTxDone) {
+		err = nil
+	}
+
+	tx.once.Do(func() {
+		tx.cb.afterRequestWithError(tx.adm, err)
+	})
+}
@@ -0,0 +1,77 @@
+package gobreaker
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTx struct {
+	commitErr error
+	done      bool
+}
+
+func (tx *fakeTx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	return nil
+}
+
+func beginFake(tscb *TwoStepCircuitBreaker, ftx *fakeTx) (*Tx, error) {
+	return tscb.BeginTx(func() (Transaction, error) { return ftx, nil })
+}
+
+func TestBeginTx(t *testing.T) {
+	tscb := NewTwoStepCircuitBreaker(Settings{})
+
+	tx, err := beginFake(tscb, &fakeTx{})
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, sql.ErrTxDone, tx.Rollback()) // deferred rollback after commit
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, tscb.Counts())
+
+	tx, err = beginFake(tscb, &fakeTx{commitErr: errors.New("connection reset")})
+	assert.Nil(t, err)
+	assert.NotNil(t, tx.Commit())
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, tscb.Counts())
+
+	tx, err = beginFake(tscb, &fakeTx{})
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+	assert.Equal(t, Counts{3, 2, 1, 1, 0}, tscb.Counts())
+
+	_, err = tscb.BeginTx(func() (Transaction, error) { return nil, errors.New("no connection") })
+	assert.NotNil(t, err)
+	assert.Equal(t, Counts{4, 2, 2, 0, 1}, tscb.Counts())
+}
+
+func TestRollbackCause(t *testing.T) {
+	errConstraint := errors.New("constraint violation")
+	tscb := NewTwoStepCircuitBreaker(Settings{
+		IsSuccessful: func(err error) bool { return err == nil || err == errConstraint },
+	})
+
+	tx, _ := beginFake(tscb, &fakeTx{})
+	assert.Nil(t, tx.RollbackCause(errConstraint))
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, tscb.Counts())
+
+	tx, _ = beginFake(tscb, &fakeTx{})
+	assert.Nil(t, tx.RollbackCause(errors.New("deadline exceeded")))
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, tscb.Counts())
+
+	tscb.cb.Trip()
+	_, err := beginFake(tscb, &fakeTx{})
+	assert.True(t, errors.Is(err, ErrOpenState))
+}
@@ -0,0 +1,151 @@
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidSettings is wrapped by the errors returned from Settings.Validate.
+var ErrInvalidSettings = errors.New("invalid settings")
+
+func invalidSettings(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSettings, fmt.Sprintf(format, args...))
+}
+
+// Validate reports the first contradiction in the Settings, e.g. a field that is ignored
+// because of another field, which NewCircuitBreaker would silently accept.
+// The returned error wraps ErrInvalidSettings.
+func (st Settings) Validate() error {
+	maxRequests := st.MaxRequests
+	if maxRequests == 0 {
+		maxRequests = 1
+	}
+
+	switch {
+	case st.HalfOpenMaxTotal == 0 && st.IdempotentProbes > maxRequests:
+		return invalidSettings("IdempotentProbes %d is more than MaxRequests %d", st.IdempotentProbes, maxRequests)
+	case st.HalfOpenMaxTotal > 0 && st.IdempotentProbes > st.HalfOpenMaxTotal:
+		return invalidSettings("IdempotentProbes %d is more than HalfOpenMaxTotal %d", st.IdempotentProbes, st.HalfOpenMaxTotal)
+	case st.CounterShards < 0:
+		return invalidSettings("CounterShards %d is negative", st.CounterShards)
+	case st.AuditLogSize < 0:
+		return invalidSettings("AuditLogSize %d is negative", st.AuditLogSize)
+	case st.HistorySize < 0:
+		return invalidSettings("HistorySize %d is negative", st.HistorySize)
+	case st.TripDumpSize < 0:
+		return invalidSettings("TripDumpSize %d is negative", st.TripDumpSize)
+	case st.RecentErrorsSize < 0:
+		return invalidSettings("RecentErrorsSize %d is negative", st.RecentErrorsSize)
+	case st.HealthCheck == nil && (st.HealthCheckInterval != 0 || st.HealthCheckSuccesses != 0):
+		return invalidSettings("HealthCheckInterval and HealthCheckSuccesses require HealthCheck")
+	case st.DowntimeBudget > DowntimeWindow:
+		return invalidSettings("DowntimeBudget %v is longer than DowntimeWindow %v", st.DowntimeBudget, DowntimeWindow)
+	case st.DowntimeBudget <= 0 && st.OnDowntimeBudgetExceeded != nil:
+		return invalidSettings("OnDowntimeBudgetExceeded requires DowntimeBudget")
+	case st.DisablePanicRecovery && st.IsPanicFailure != nil:
+		return invalidSettings("IsPanicFailure is not used with DisablePanicRecovery")
+	case st.IsSuccessful != nil && st.IsSuccessfulWithMetadata != nil:
+		return invalidSettings("IsSuccessful is not used with IsSuccessfulWithMetadata")
+	case st.IsSuccessfulResult != nil && (st.IsSuccessful != nil || st.IsSuccessfulWithMetadata != nil):
+		return invalidSettings("IsSuccessful and IsSuccessfulWithMetadata are not used with IsSuccessfulResult")
+	case st.Classifier != nil && (st.IsSuccessful != nil || st.IsSuccessfulWithMetadata != nil || st.IsSuccessfulResult != nil):
+		return invalidSettings("IsSuccessful, IsSuccessfulWithMetadata and IsSuccessfulResult are not used with Classifier")
+	case st.ReadyToTripWithMetadata != nil && st.ReadyToTripWithStats != nil:
+		return invalidSettings("ReadyToTripWithStats is not used with ReadyToTripWithMetadata")
+	case st.BurnRate != nil && (st.BurnRate.Target <= 0 || st.BurnRate.Target >= 1):
+		return invalidSettings("BurnRate.Target %v is not between 0 and 1", st.BurnRate.Target)
+	case st.BurnRate != nil && (st.ReadyToTrip != nil || st.ReadyToTripWithMetadata != nil || st.ReadyToTripWithStats != nil):
+		return invalidSettings("ReadyToTrip, ReadyToTripWithMetadata and ReadyToTripWithStats are not used with BurnRate")
+	case st.ReadyToTripWithWindow != nil && (st.ReadyToTripWithMetadata != nil || st.ReadyToTripWithStats != nil || st.BurnRate != nil):
+		return invalidSettings("ReadyToTripWithWindow is not used with ReadyToTripWithMetadata, ReadyToTripWithStats or BurnRate")
+	case st.FailOpenRatio < 0 || st.FailOpenRatio > 1:
+		return invalidSettings("FailOpenRatio %v is not between 0 and 1", st.FailOpenRatio)
+	case st.ReadyToTripByCategory != nil && st.Categorize == nil:
+		return invalidSettings("ReadyToTripByCategory requires Categorize")
+	case st.RecoveryRamp != nil && !validRampSteps(st.RecoveryRamp.Steps):
+		return invalidSettings("RecoveryRamp.Steps %v are not between 0 exclusive and 1", st.RecoveryRamp.Steps)
+	case st.HalfOpenSuccessRatio < 0 || st.HalfOpenSuccessRatio > 1:
+		return invalidSettings("HalfOpenSuccessRatio %v is not between 0 and 1", st.HalfOpenSuccessRatio)
+	case !validShedThresholds(st.ShedThresholds):
+		return invalidSettings("ShedThresholds %v are not between 0 and 1", st.ShedThresholds)
+	case st.DecayHalfLife < 0:
+		return invalidSettings("DecayHalfLife %v is negative", st.DecayHalfLife)
+	case st.DecayHalfLife > 0 && st.BurnRate != nil:
+		return invalidSettings("DecayHalfLife is not used with BurnRate")
+	case st.HalfOpenDuration < 0:
+		return invalidSettings("HalfOpenDuration %v is negative", st.HalfOpenDuration)
+	case st.HalfOpenDuration > 0 && (st.ReadyToClose != nil || st.ReadyToReopen != nil):
+		return invalidSettings("ReadyToClose and ReadyToReopen are not used with HalfOpenDuration")
+	case st.CacheTTL < 0:
+		return invalidSettings("CacheTTL %v is negative", st.CacheTTL)
+	case st.BurstWindow < 0 || st.BurstThreshold < 0:
+		return invalidSettings("BurstWindow %v or BurstThreshold %v is negative", st.BurstWindow, st.BurstThreshold)
+	case (st.BurstWindow > 0) != (st.BurstThreshold > 0):
+		return invalidSettings("BurstWindow and BurstThreshold are used together")
+	case st.BurstWindow > 0 && st.CounterShards != 0:
+		return invalidSettings("CounterShards is not used with BurstWindow")
+	case st.InjectFailureRate < 0 || st.InjectFailureRate > 1:
+		return invalidSettings("InjectFailureRate %v is not between 0 and 1", st.InjectFailureRate)
+	case st.InjectLatency < 0:
+		return invalidSettings("InjectLatency %v is negative", st.InjectLatency)
+	case st.EnforceCallTimeout && st.CallTimeout <= 0:
+		return invalidSettings("EnforceCallTimeout requires CallTimeout")
+	case st.HalfOpenLatencyBudget < 0:
+		return invalidSettings("HalfOpenLatencyBudget %v is negative", st.HalfOpenLatencyBudget)
+	case st.HalfOpenLatencyBudget > 0 && (st.ReadyToClose != nil || st.HalfOpenSuccessRatio > 0 || st.HalfOpenDuration > 0):
+		return invalidSettings("HalfOpenLatencyBudget is not used with ReadyToClose, HalfOpenSuccessRatio or HalfOpenDuration")
+	case st.TimeoutJitter < 0:
+		return invalidSettings("TimeoutJitter %v is negative", st.TimeoutJitter)
+	case st.BurnRate != nil && st.CounterShards != 0:
+		return invalidSettings("CounterShards is not used with BurnRate")
+	}
+
+	switch st.Mode {
+	case ModeStandard, ModeHighThroughput:
+		if st.AdaptiveK != 0 || st.AdaptiveWindow != 0 || st.ShedNonIdempotentFirst {
+			return invalidSettings("AdaptiveK, AdaptiveWindow and ShedNonIdempotentFirst require ModeAdaptive")
+		}
+	case ModeAdaptive:
+		if st.CounterShards != 0 {
+			return invalidSettings("CounterShards is not used in ModeAdaptive")
+		}
+	default:
+		return invalidSettings("unknown Mode %d", st.Mode)
+	}
+
+	if st.MaintenancePolicy != MaintenanceNeverTrip && st.MaintenancePolicy != MaintenanceTripImmediately {
+		return invalidSettings("unknown MaintenancePolicy %d", st.MaintenancePolicy)
+	}
+
+	if st.IntervalPolicy != IntervalNever && st.IntervalPolicy != IntervalDefault {
+		return invalidSettings("unknown IntervalPolicy %d", st.IntervalPolicy)
+	}
+
+	if st.HalfOpenAdmission != AdmissionFirstCome && st.HalfOpenAdmission != AdmissionFIFO {
+		return invalidSettings("unknown HalfOpenAdmission %d", st.HalfOpenAdmission)
+	}
+	return nil
+}
+
+// DefaultSettings returns the Settings with the defaults recommended for production, named name.
+// The CircuitBreaker trips when at least half of at least 20 requests fail within a minute,
+// stays open for 30 seconds, and then closes after 3 successful probes.
+func DefaultSettings(name string) Settings {
+	return Settings{
+		Name:        name,
+		MaxRequests: 3,
+		Interval:    time.Duration(60) * time.Second,
+		Timeout:     time.Duration(30) * time.Second,
+		ReadyToTrip: RateHysteresis{TripFailureRatio: 0.5, MinRequests: 20}.ReadyToTrip,
+	}
+}
+
+// NewCircuitBreakerWithError is like NewCircuitBreaker
+// but returns the error of Settings.Validate instead of accepting invalid Settings.
+func NewCircuitBreakerWithError(st Settings) (*CircuitBreaker, error) {
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+	return NewCircuitBreaker(st), nil
+}
@@ -0,0 +1,47 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Settings{}.Validate())
+	assert.Nil(t, DefaultSettings("db").Validate())
+	assert.Nil(t, Settings{Mode: ModeAdaptive, AdaptiveK: 1.5}.Validate())
+
+	invalid := []Settings{
+		{MaxRequests: 2, IdempotentProbes: 3},
+		{CounterShards: -1},
+		{AuditLogSize: -1},
+		{HealthCheckSuccesses: 3},
+		{DowntimeBudget: DowntimeWindow + time.Second},
+		{OnDowntimeBudgetExceeded: func(name string, downtime time.Duration) {}},
+		{DisablePanicRecovery: true, IsPanicFailure: func(recovered interface{}) bool { return true }},
+		{IsSuccessful: defaultIsSuccessful, IsSuccessfulWithMetadata: func(md Metadata, err error) bool { return true }},
+		{IsSuccessful: defaultIsSuccessful, Classifier: Chain()},
+		{IsSuccessful: defaultIsSuccessful, IsSuccessfulResult: func(result interface{}, err error) bool { return true }},
+		{EnforceCallTimeout: true},
+		{AdaptiveK: 1.5},
+		{Mode: ModeAdaptive, CounterShards: 4},
+		{Mode: Mode(7)},
+		{IntervalPolicy: IntervalPolicy(7)},
+	}
+	for i, st := range invalid {
+		assert.True(t, errors.Is(st.Validate(), ErrInvalidSettings), "settings %d", i)
+	}
+}
+
+func TestNewCircuitBreakerWithError(t *testing.T) {
+	cb, err := NewCircuitBreakerWithError(DefaultSettings("db"))
+	assert.Nil(t, err)
+	assert.Equal(t, "db", cb.Name())
+	assert.Equal(t, uint32(3), cb.maxRequests)
+
+	cb, err = NewCircuitBreakerWithError(Settings{CounterShards: -1})
+	assert.Nil(t, cb)
+	assert.Equal(t, "invalid settings: CounterShards -1 is negative", err.Error())
+}
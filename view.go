@@ -0,0 +1,65 @@
+package gobreaker
+
+import "time"
+
+// View is a read-only view of a CircuitBreaker returned by ReadOnly,
+// so that a CircuitBreaker owned by a package can be exposed to the monitoring or the admin code
+// without letting it send requests or change the state.
+type View struct {
+	cb *CircuitBreaker
+}
+
+// ReadOnly returns the read-only View of the CircuitBreaker.
+func (cb *CircuitBreaker) ReadOnly() View {
+	return View{cb: cb}
+}
+
+// ReadOnly returns the read-only View of the TwoStepCircuitBreaker.
+func (tscb *TwoStepCircuitBreaker) ReadOnly() View {
+	return View{cb: tscb.cb}
+}
+
+// Name returns the name of the CircuitBreaker.
+func (v View) Name() string {
+	return v.cb.Name()
+}
+
+// State returns the current state of the CircuitBreaker.
+func (v View) State() State {
+	return v.cb.State()
+}
+
+// Counts returns the internal counters of the CircuitBreaker.
+func (v View) Counts() Counts {
+	return v.cb.Counts()
+}
+
+// Stats returns the timing statistics of the CircuitBreaker. See CircuitBreaker.Stats.
+func (v View) Stats() Stats {
+	return v.cb.Stats()
+}
+
+// Expiry returns the time when the current generation of the CircuitBreaker expires. See CircuitBreaker.Expiry.
+func (v View) Expiry() time.Time {
+	return v.cb.Expiry()
+}
+
+// LastTransition returns the last transition of the CircuitBreaker. See CircuitBreaker.LastTransition.
+func (v View) LastTransition() Transition {
+	return v.cb.LastTransition()
+}
+
+// Lifetime returns the cumulative counters of the CircuitBreaker. See CircuitBreaker.Lifetime.
+func (v View) Lifetime() Lifetime {
+	return v.cb.Lifetime()
+}
+
+// Snapshot returns the snapshot of the CircuitBreaker. See CircuitBreaker.Snapshot.
+func (v View) Snapshot() BreakerSnapshot {
+	return v.cb.Snapshot()
+}
+
+// Settings returns a copy of the effective Settings of the CircuitBreaker. See CircuitBreaker.Settings.
+func (v View) Settings() Settings {
+	return v.cb.Settings()
+}
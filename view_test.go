@@ -0,0 +1,28 @@
+package gobreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnly(t *testing.T) {
+	cb := NewCircuitBreaker(Settings{Name: "view"})
+	v := cb.ReadOnly()
+	assert.Nil(t, fail(cb))
+
+	assert.Equal(t, "view", v.Name())
+	assert.Equal(t, StateClosed, v.State())
+	assert.Equal(t, Counts{1, 0, 1, 0, 1}, v.Counts())
+	assert.Equal(t, uint32(1), v.Stats().Count)
+
+	cb.Trip()
+	assert.Equal(t, StateOpen, v.State())
+	assert.Equal(t, cb.Expiry(), v.Expiry())
+	assert.Equal(t, CauseManual, v.LastTransition().Cause)
+	assert.Equal(t, uint64(1), v.Lifetime().Trips)
+	assert.Equal(t, StateOpen, v.Snapshot().State)
+
+	tscb := NewTwoStepCircuitBreaker(Settings{Name: "two-step"})
+	assert.Equal(t, "two-step", tscb.ReadOnly().Name())
+}
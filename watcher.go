@@ -0,0 +1,163 @@
+package gobreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// GroupConfig is the configuration of the CircuitBreakers of a Group, e.g. in a file watched by a Watcher.
+// Default configures the CircuitBreakers not in Breakers, and Breakers configures the CircuitBreakers by name.
+// Each Config is applied to the Settings given to NewGroup with Config.ApplyTo, so the hooks are kept,
+// and a Config in Breakers doesn't inherit Default. The names in the Configs are ignored.
+type GroupConfig struct {
+	Default  *Config           `json:"default,omitempty" yaml:"default,omitempty"`
+	Breakers map[string]Config `json:"breakers,omitempty" yaml:"breakers,omitempty"`
+}
+
+// GroupConfigFromJSON returns the GroupConfig of the JSON representation.
+// The unknown fields are rejected like SettingsFromJSON.
+func GroupConfigFromJSON(data []byte) (GroupConfig, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var gc GroupConfig
+	if err := dec.Decode(&gc); err != nil {
+		return GroupConfig{}, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+	}
+	return gc, nil
+}
+
+// ApplyConfig reconfigures the Group and its CircuitBreakers with gc by UpdateSettings,
+// and the CircuitBreakers created later are configured with gc as well.
+// ApplyConfig validates all the Configs first, and returns an error wrapping ErrInvalidSettings
+// without changing anything if any of them is invalid.
+// The CircuitBreakers created after ApplyConfig has started are configured with gc.
+// The CircuitBreakers are reconfigured without the lock of the Group,
+// so that their hooks, such as OnStateChange, may use the Group.
+func (g *Group) ApplyConfig(gc GroupConfig) error {
+	def := g.base
+	if gc.Default != nil {
+		st, err := gc.Default.ApplyTo(g.base)
+		if err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+		def = st
+	}
+
+	overrides := make(map[string]Settings, len(gc.Breakers))
+	for name, c := range gc.Breakers {
+		st, err := c.ApplyTo(g.base)
+		if err != nil {
+			return fmt.Errorf("breaker %q: %w", name, err)
+		}
+		overrides[name] = st
+	}
+
+	g.applying.Lock()
+	defer g.applying.Unlock()
+
+	type update struct {
+		cb *CircuitBreaker
+		st Settings
+	}
+	g.mutex.Lock()
+	g.settings = def
+	g.overrides = overrides
+	updates := make([]update, 0, len(g.breakers))
+	for name, cb := range g.breakers {
+		updates = append(updates, update{cb: cb, st: g.settingsFor(name)})
+	}
+	g.mutex.Unlock()
+
+	for _, u := range updates {
+		u.cb.UpdateSettings(u.st)
+	}
+	return nil
+}
+
+// ConfigSource is a source of the configuration of a Group watched by a Watcher, e.g. a file or an object of S3.
+//
+// Watch calls update with the whole configuration at the start and whenever it changes,
+// until ctx is done or the watch fails.
+type ConfigSource interface {
+	Watch(ctx context.Context, update func(data []byte)) error
+}
+
+// FileSource is a ConfigSource of a file, which is read every Interval and reported when its content changes.
+// If Interval is less than or equal to 0, Interval is set to 10 seconds.
+// Clock, if not nil, measures Interval instead of the system time.
+// Watch fails if the file can't be read at the start. The later errors of reading the file are ignored,
+// e.g. while the file is being replaced, and the file is read again after Interval.
+type FileSource struct {
+	Path     string
+	Interval time.Duration
+	Clock    Clock
+}
+
+const defaultFileSourceInterval = time.Duration(10) * time.Second
+
+// Watch implements ConfigSource.
+func (s FileSource) Watch(ctx context.Context, update func(data []byte)) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultFileSourceInterval
+	}
+
+	last, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return err
+	}
+	update(last)
+
+	clock := s.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	for {
+		if err := sleepContext(ctx, clock, interval); err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(s.Path)
+		if err != nil || bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+		update(data)
+	}
+}
+
+// Watcher applies the JSON representation of GroupConfig from a ConfigSource to a Group whenever it changes.
+// An invalid configuration is reported to the error handler and not applied,
+// so the Group keeps the last valid configuration until the configuration is fixed.
+type Watcher struct {
+	group   *Group
+	source  ConfigSource
+	onError func(err error)
+}
+
+// NewWatcher returns a new Watcher that applies the configuration from source to g.
+// onError, if not nil, is called with the error of every invalid configuration.
+func NewWatcher(g *Group, source ConfigSource, onError func(err error)) *Watcher {
+	return &Watcher{group: g, source: source, onError: onError}
+}
+
+// Run watches the ConfigSource and applies the configuration until ctx is done or the watch fails,
+// and returns the error of Watch.
+func (w *Watcher) Run(ctx context.Context) error {
+	return w.source.Watch(ctx, w.apply)
+}
+
+func (w *Watcher) apply(data []byte) {
+	gc, err := GroupConfigFromJSON(data)
+	if err == nil {
+		err = w.group.ApplyConfig(gc)
+	}
+	if err != nil && w.onError != nil {
+		w.onError(err)
+	}
+}
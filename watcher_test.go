@@ -0,0 +1,166 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyConfig(t *testing.T) {
+	var changes []string
+	g := NewGroup(Settings{
+		MaxRequests:   5,
+		OnStateChange: func(name string, _ State, _ State) { changes = append(changes, name) },
+	})
+	a := g.Get("a")
+
+	gc, err := GroupConfigFromJSON([]byte(`{
+		"default": {"maxRequests": 2, "timeout": "10s"},
+		"breakers": {"b": {"maxRequests": 3}}
+	}`))
+	assert.Nil(t, err)
+	assert.Nil(t, g.ApplyConfig(gc))
+	assert.Equal(t, uint32(2), a.maxRequests)
+	assert.Equal(t, time.Duration(10)*time.Second, a.timeout)
+	assert.Equal(t, "a", a.Name())
+	assert.Equal(t, uint32(3), g.Get("b").maxRequests)
+	assert.Equal(t, uint32(2), g.Get("c").maxRequests)
+
+	invalid := GroupConfig{Breakers: map[string]Config{
+		"a": {MaxRequests: 7},
+		"b": {FailOpenRatio: 2},
+	}}
+	err = g.ApplyConfig(invalid)
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+	assert.Equal(t, uint32(2), a.maxRequests)
+	assert.Equal(t, uint32(3), g.Get("b").maxRequests)
+
+	assert.Nil(t, g.ApplyConfig(GroupConfig{}))
+	assert.Equal(t, uint32(5), a.maxRequests)
+	a.Trip()
+	assert.Equal(t, []string{"a"}, changes)
+
+	_, err = GroupConfigFromJSON([]byte(`{"defaults": {}}`))
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
+
+func TestApplyConfigHooks(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var g *Group
+	var names []string
+	g = NewGroup(Settings{
+		Clock:         clock,
+		OnStateChange: func(name string, _ State, _ State) { names = g.Names() },
+	})
+	g.Get("a").Trip()
+	clock.advance(time.Duration(61) * time.Second)
+
+	// UpdateSettings moves "a" to the half-open state, and OnStateChange uses the Group
+	done := make(chan error)
+	go func() { done <- g.ApplyConfig(GroupConfig{}) }()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("ApplyConfig deadlocked")
+	}
+	assert.Equal(t, []string{"a"}, names)
+}
+
+// manualClock is a Clock whose timers are handed to the test, which fires them by calling them.
+type manualClock struct {
+	stepClock
+	timers chan func()
+}
+
+type manualTimer struct{}
+
+func (manualTimer) Stop() bool { return false }
+
+func (c *manualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.timers <- f
+	return manualTimer{}
+}
+
+func TestFileSourceClock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobreaker")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "breakers.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("1"), 0600))
+
+	clock := &manualClock{timers: make(chan func())}
+	updates := make(chan string)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- FileSource{Path: path, Interval: time.Hour, Clock: clock}.Watch(ctx, func(data []byte) {
+			updates <- string(data)
+		})
+	}()
+	assert.Equal(t, "1", <-updates)
+
+	// the file is read only when the timer fires
+	fire := <-clock.timers
+	assert.Nil(t, ioutil.WriteFile(path, []byte("2"), 0600))
+	fire()
+	assert.Equal(t, "2", <-updates)
+
+	<-clock.timers
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobreaker")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "breakers.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"default": {"maxRequests": 2}}`), 0600))
+
+	g := NewGroup(Settings{})
+	a := g.Get("a")
+	errs := make(chan error, 1)
+	w := NewWatcher(g, FileSource{Path: path, Interval: time.Millisecond}, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	maxRequests := func(n uint32) bool {
+		for i := 0; i < 1000; i++ {
+			a.mutex.Lock()
+			m := a.maxRequests
+			a.mutex.Unlock()
+			if m == n {
+				return true
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	assert.True(t, maxRequests(2))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"default": {"maxRequests": 2, "mode": "magic"}}`), 0600))
+	assert.True(t, errors.Is(<-errs, ErrInvalidSettings))
+	assert.True(t, maxRequests(2))
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"default": {"maxRequests": 4}}`), 0600))
+	assert.True(t, maxRequests(4))
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	err = NewWatcher(g, FileSource{Path: filepath.Join(dir, "missing.json")}, nil).Run(context.Background())
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,119 @@
+package gobreaker
+
+import (
+	"math"
+	"time"
+)
+
+// windowBuckets is the number of buckets of a rollingWindow.
+const windowBuckets = 10
+
+type windowBucket struct {
+	start     time.Time
+	requests  uint32
+	successes uint32
+	failures  uint32
+}
+
+// rollingWindow counts requests and their outcomes over the last size of time
+// with the granularity of size / windowBuckets.
+type rollingWindow struct {
+	size    time.Duration
+	width   time.Duration
+	buckets [windowBuckets]windowBucket
+}
+
+func newRollingWindow(size time.Duration) *rollingWindow {
+	width := size / windowBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &rollingWindow{size: size, width: width}
+}
+
+func (w *rollingWindow) bucket(now time.Time) *windowBucket {
+	start := now.Truncate(w.width)
+	b := &w.buckets[(start.UnixNano()/int64(w.width))%windowBuckets]
+	if !b.start.Equal(start) {
+		*b = windowBucket{start: start}
+	}
+	return b
+}
+
+func (w *rollingWindow) onRequest(now time.Time) {
+	w.bucket(now).requests++
+}
+
+func (w *rollingWindow) onSuccess(now time.Time) {
+	w.bucket(now).successes++
+}
+
+func (w *rollingWindow) onFailure(now time.Time) {
+	w.bucket(now).failures++
+}
+
+// add counts the requests and their outcomes at once.
+func (w *rollingWindow) add(now time.Time, requests, successes, failures uint32) {
+	b := w.bucket(now)
+	b.requests += requests
+	b.successes += successes
+	b.failures += failures
+}
+
+// totals returns the numbers of requests, successes and failures within the window until now.
+func (w *rollingWindow) totals(now time.Time) (requests, successes, failures uint32) {
+	from := now.Add(-w.size)
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if b.start.After(from) && !b.start.After(now) {
+			requests += b.requests
+			successes += b.successes
+			failures += b.failures
+		}
+	}
+	return requests, successes, failures
+}
+
+// Window is the view of the recent requests in the closed state given to Settings.ReadyToTripWithWindow,
+// so that a policy isn't blinded the instant Interval clears Counts.
+//
+// Current is the Counts of the current generation, and Elapsed is the time since the current generation started.
+// Previous is the Counts of the previous generation if it was also in the closed state, e.g. ended by Interval,
+// or the zero Counts otherwise.
+type Window struct {
+	Current  Counts
+	Previous Counts
+	Elapsed  time.Duration
+	Interval time.Duration
+}
+
+// Sliding estimates the Counts over the last Interval by weighting Previous by the part of Interval
+// not yet elapsed in the current generation, like a sliding window counter.
+// ConsecutiveSuccesses and ConsecutiveFailures are those of Current.
+// If Interval is 0, Sliding returns Current.
+func (w Window) Sliding() Counts {
+	if w.Interval <= 0 || w.Elapsed >= w.Interval {
+		return w.Current
+	}
+
+	weight := float64(w.Interval-w.Elapsed) / float64(w.Interval)
+	weighted := func(current, previous uint32) uint32 {
+		return current + uint32(math.Round(float64(previous)*weight))
+	}
+
+	counts := w.Current
+	counts.Requests = weighted(w.Current.Requests, w.Previous.Requests)
+	counts.TotalSuccesses = weighted(w.Current.TotalSuccesses, w.Previous.TotalSuccesses)
+	counts.TotalFailures = weighted(w.Current.TotalFailures, w.Previous.TotalFailures)
+	return counts
+}
+
+// window returns the Window of the CircuitBreaker at now.
+func (cb *CircuitBreaker) window(now time.Time) Window {
+	return Window{
+		Current:  cb.counts.snapshot(),
+		Previous: cb.previousCounts,
+		Elapsed:  now.Sub(cb.generationStart),
+		Interval: cb.interval,
+	}
+}
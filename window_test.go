@@ -0,0 +1,75 @@
+package gobreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollingWindow(t *testing.T) {
+	w := newRollingWindow(time.Duration(10) * time.Second)
+	now := time.Now().Truncate(time.Second)
+
+	w.onRequest(now)
+	w.onSuccess(now)
+	w.onRequest(now.Add(time.Duration(3) * time.Second))
+	w.onFailure(now.Add(time.Duration(3) * time.Second))
+
+	r, s, f := w.totals(now.Add(time.Duration(5) * time.Second))
+	assert.Equal(t, []uint32{2, 1, 1}, []uint32{r, s, f})
+
+	// the first bucket is out of the window
+	r, s, f = w.totals(now.Add(time.Duration(10) * time.Second))
+	assert.Equal(t, []uint32{1, 0, 1}, []uint32{r, s, f})
+
+	// the bucket is reused after the window
+	w.onRequest(now.Add(time.Duration(13) * time.Second))
+	r, _, f = w.totals(now.Add(time.Duration(13) * time.Second))
+	assert.Equal(t, []uint32{1, 0}, []uint32{r, f})
+}
+
+func TestWindowSliding(t *testing.T) {
+	w := Window{
+		Current:  Counts{Requests: 2, TotalFailures: 2, ConsecutiveFailures: 2},
+		Previous: Counts{Requests: 10, TotalSuccesses: 6, TotalFailures: 4},
+		Elapsed:  time.Duration(15) * time.Second,
+		Interval: time.Duration(60) * time.Second,
+	}
+	assert.Equal(t, Counts{Requests: 10, TotalSuccesses: 5, TotalFailures: 5, ConsecutiveFailures: 2}, w.Sliding())
+
+	w.Elapsed = time.Duration(60) * time.Second
+	assert.Equal(t, w.Current, w.Sliding())
+	w.Interval = 0
+	assert.Equal(t, w.Current, w.Sliding())
+}
+
+func TestReadyToTripWithWindow(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	var last Window
+	cb := NewCircuitBreaker(Settings{
+		Clock:    clock,
+		Interval: time.Duration(10) * time.Second,
+		ReadyToTripWithWindow: func(w Window) bool {
+			last = w
+			return w.Sliding().TotalFailures >= 5
+		},
+	})
+
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, fail(cb))
+	}
+	clock.advance(time.Duration(11) * time.Second)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{}, cb.Counts())
+
+	clock.advance(time.Second)
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{Requests: 4, TotalFailures: 4, ConsecutiveFailures: 4}, last.Previous)
+	assert.Equal(t, time.Second, last.Elapsed)
+	assert.Equal(t, StateOpen, cb.State())
+
+	cb.Reset()
+	assert.Nil(t, fail(cb))
+	assert.Equal(t, Counts{}, last.Previous)
+}